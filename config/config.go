@@ -0,0 +1,112 @@
+// Package config reads the optional YAML settings file
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the settings file. Unset values keep the built-in defaults, and
+// command-line flags and environment credentials take precedence over it.
+type Config struct {
+	Credentials Credentials        `yaml:"credentials"`
+	Demo        Demo               `yaml:"demo"`
+	Theme       map[string]string  `yaml:"theme"`   // Colors by element, e.g. positive: "46" or "#00ff00"
+	Refresh     time.Duration      `yaml:"refresh"` // Clock and redraw interval, 0 keeps the default
+	Reconnect   Reconnect          `yaml:"reconnect"`
+	Proxy       string             `yaml:"proxy"` // HTTP or SOCKS5 proxy for OKX connections, as with -proxy
+	Alerts      Alerts             `yaml:"alerts"`
+	Sort        string             `yaml:"sort"`     // Initial card order, as with -sort
+	Pins        []string           `yaml:"pins"`     // Instruments shown first whatever the sort, as with -pin
+	Leverage    map[string]float64 `yaml:"leverage"` // Leverage assumed per instrument where OKX reports none
+}
+
+// Credentials are OKX API credentials, overridden by the OKX_API_* variables
+type Credentials struct {
+	APIKey     string `yaml:"api_key"`
+	APISecret  string `yaml:"api_secret"`
+	Passphrase string `yaml:"api_passphrase"`
+}
+
+// Demo configures demo mode
+type Demo struct {
+	Positions []core.DemoPosition `yaml:"positions"` // Replace the built-in demo set
+	Count     int                 `yaml:"count"`     // Number of demo positions, as with -demo-count
+	Equity    float64             `yaml:"equity"`    // Demo account equity before demo PnL
+}
+
+// Reconnect configures the reconnect backoff
+type Reconnect struct {
+	MinDelay time.Duration `yaml:"min_delay"` // First wait after a drop
+	MaxDelay time.Duration `yaml:"max_delay"` // Cap of the doubling wait
+}
+
+// Alerts configures the PnL % alert thresholds
+type Alerts struct {
+	LossPct    float64 `yaml:"loss_pct"`   // Alert below -N % PnL, as with -loss-alert
+	GainPct    float64 `yaml:"gain_pct"`   // Alert above N % PnL, as with -gain-alert
+	Hysteresis float64 `yaml:"hysteresis"` // PnL % points before an alert rearms, as with -alert-hysteresis
+}
+
+// Load reads the settings file at path. A missing file is not an error and
+// yields an empty Config, so every setting keeps its default. Unknown keys
+// are rejected to catch typos.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// validate checks the values the packages using them don't check themselves
+func (cfg *Config) validate() error {
+	if err := core.ValidateDemoPositions(cfg.Demo.Positions); err != nil {
+		return err
+	}
+	switch {
+	case cfg.Demo.Count < 0:
+		return fmt.Errorf("demo count must not be negative")
+	case cfg.Demo.Equity < 0:
+		return fmt.Errorf("demo equity must not be negative")
+	case cfg.Alerts.LossPct < 0 || cfg.Alerts.GainPct < 0:
+		return fmt.Errorf("alert thresholds must not be negative")
+	case cfg.Refresh < 0:
+		return fmt.Errorf("refresh must not be negative")
+	case cfg.Reconnect.MinDelay < 0 || cfg.Reconnect.MaxDelay < 0:
+		return fmt.Errorf("reconnect delays must not be negative")
+	case cfg.Reconnect.MinDelay > 0 && cfg.Reconnect.MaxDelay > 0 && cfg.Reconnect.MinDelay > cfg.Reconnect.MaxDelay:
+		return fmt.Errorf("reconnect min_delay %s exceeds max_delay %s", cfg.Reconnect.MinDelay, cfg.Reconnect.MaxDelay)
+	}
+	if cfg.Proxy != "" {
+		if _, err := core.ParseProxyURL(cfg.Proxy); err != nil {
+			return err
+		}
+	}
+	for instId, leverage := range cfg.Leverage {
+		if leverage <= 0 {
+			return fmt.Errorf("leverage for %s must be positive", instId)
+		}
+	}
+	return nil
+}
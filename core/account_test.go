@@ -0,0 +1,56 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAccountFrameParsed(t *testing.T) {
+	c, _, balanceCh, _ := newTestDemoClient()
+	pushFrame(t, c, c.mainHandlers, `{
+		"arg": {"channel": "account", "uid": "44705892343619584"},
+		"data": [{
+			"ccy": "USDT", "totalEq": "11000.5", "availBal": "8200.25", "adjEq": "10500.75",
+			"ordFroz": "250.5", "mgnRatio": "0.125", "uTime": "1700000000000", "autoLoan": "true",
+			"details": [
+				{"ccy": "USDT", "liab": "-100", "interest": "0.5", "maxLoan": "5000"},
+				{"ccy": "BTC", "liab": "0", "interest": "0"}
+			]
+		}]
+	}`)
+
+	select {
+	case got := <-balanceCh:
+		want := BalanceData{
+			Currency: "USDT", TotalEquity: 11000.5, AvailBalance: 8200.25, MarginRatio: 12.5,
+			AdjustedEquity: 10500.75, OrderFrozen: 250.5, AutoLoan: true, AutoLoanReported: true,
+			Timestamp: 1700000000000,
+		}
+		borrows := got.Borrows
+		got.Borrows = nil
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("balance =\n%+v\nwant\n%+v", got, want)
+		}
+		if len(borrows) != 1 || borrows[0] != (BorrowData{Currency: "USDT", Liability: 100, Interest: 0.5, MaxLoan: 5000}) {
+			t.Errorf("borrows = %+v, want only the USDT loan", borrows)
+		}
+	default:
+		t.Fatal("no balance sent for the account frame")
+	}
+}
+
+func TestAccountFrameOptionalFieldsDefaultToZero(t *testing.T) {
+	c, _, balanceCh, errorCh := newTestDemoClient()
+	pushFrame(t, c, c.mainHandlers, `{"arg":{"channel":"account"},"data":[{"ccy":"USDC","totalEq":"500","availBal":"500"}]}`)
+
+	got := <-balanceCh
+	if got.AdjustedEquity != 0 || got.OrderFrozen != 0 || got.MarginRatio != 0 || got.Borrows != nil || got.AutoLoanReported {
+		t.Errorf("balance = %+v, want absent fields left at zero", got)
+	}
+	for len(errorCh) > 0 {
+		if msg := <-errorCh; strings.Contains(msg, "Malformed") {
+			t.Errorf("absent field reported as malformed: %q", msg)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package core
+
+// SetAccount labels every position and balance the client sends with account,
+// so several clients, one per OKX account or subaccount, can feed one UI
+func (c *OKXClient) SetAccount(account string) {
+	c.account = account
+}
+
+// sendPosition sends a position update labelled with the client's account,
+// under the position channel's full policy
+func (c *OKXClient) sendPosition(position PositionData) {
+	position.Account = c.account
+	select {
+	case c.positionCh <- position:
+	default:
+		if c.backpressure.onFull(ChannelPosition) {
+			c.positionCh <- position
+		}
+	}
+}
+
+// sendBalance sends a balance update labelled with the client's account,
+// under the balance channel's full policy
+func (c *OKXClient) sendBalance(balance BalanceData) {
+	balance.Account = c.account
+	select {
+	case c.balanceCh <- balance:
+	default:
+		if c.backpressure.onFull(ChannelBalance) {
+			c.balanceCh <- balance
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ChannelPolicy is what a client does when a channel to the UI is full
+type ChannelPolicy int
+
+const (
+	ChannelBlock ChannelPolicy = iota // Wait for the UI to drain the channel
+	ChannelDrop                       // Drop the message so the feed keeps up
+)
+
+// Channels the policies and metrics apply to. Debug traces share the error
+// channel but have a policy of their own.
+const (
+	ChannelPosition = "position"
+	ChannelBalance  = "balance"
+	ChannelError    = "error"
+	ChannelDebug    = "debug"
+)
+
+// channelNames lists the channels in display order
+var channelNames = []string{ChannelPosition, ChannelBalance, ChannelError, ChannelDebug}
+
+// defaultChannelPolicies block on positions, balances and errors, whose loss
+// would leave the UI showing stale structure or miss a fatal error, and drop
+// debug traces, which only fill the debug pane
+var defaultChannelPolicies = map[string]ChannelPolicy{
+	ChannelPosition: ChannelBlock,
+	ChannelBalance:  ChannelBlock,
+	ChannelError:    ChannelBlock,
+	ChannelDebug:    ChannelDrop,
+}
+
+// channelPolicyNames maps the names used in overrides to policies
+var channelPolicyNames = map[string]ChannelPolicy{
+	"block": ChannelBlock,
+	"drop":  ChannelDrop,
+}
+
+// ParseChannelPolicies parses comma-separated channel=policy overrides such
+// as "error=drop,debug=block", where policy is block or drop
+func ParseChannelPolicies(value string) (map[string]ChannelPolicy, error) {
+	policies := make(map[string]ChannelPolicy)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		channel, name, ok := strings.Cut(entry, "=")
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		if !ok {
+			return nil, fmt.Errorf("invalid channel policy %q, want channel=block or channel=drop", entry)
+		}
+		if _, known := defaultChannelPolicies[channel]; !known {
+			return nil, fmt.Errorf("invalid channel %q in %q, want %s", channel, entry, strings.Join(channelNames, ", "))
+		}
+		policy, ok := channelPolicyNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("invalid policy %q for channel %s, want block or drop", name, channel)
+		}
+		policies[channel] = policy
+	}
+	return policies, nil
+}
+
+// ChannelCounts are how often sends found one channel full
+type ChannelCounts struct {
+	Channel string
+	Policy  ChannelPolicy
+	Full    uint64 // Sends that found the channel full
+	Dropped uint64 // Messages dropped under the drop policy
+}
+
+// channelCounters are the live counts of one channel
+type channelCounters struct {
+	full    atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// Backpressure applies the full-channel policies of the channels clients feed
+// the UI through and counts how often each was full, so a stalled UI shows up
+// in the diagnostics rather than as a silent stall. One is shared by every
+// client sending on the same channels.
+type Backpressure struct {
+	policies map[string]ChannelPolicy
+	counters map[string]*channelCounters
+}
+
+// NewBackpressure creates the shared channel metrics with the given policy
+// overrides on top of the defaults
+func NewBackpressure(overrides map[string]ChannelPolicy) *Backpressure {
+	b := &Backpressure{
+		policies: make(map[string]ChannelPolicy, len(defaultChannelPolicies)),
+		counters: make(map[string]*channelCounters, len(channelNames)),
+	}
+	for channel, policy := range defaultChannelPolicies {
+		b.policies[channel] = policy
+	}
+	for channel, policy := range overrides {
+		b.policies[channel] = policy
+	}
+	for _, channel := range channelNames {
+		b.counters[channel] = &channelCounters{}
+	}
+	return b
+}
+
+// onFull records that a send found the channel full and reports whether the
+// sender should block until it drains. Without metrics it always blocks, as
+// clients did before policies existed.
+func (b *Backpressure) onFull(channel string) bool {
+	if b == nil {
+		return true
+	}
+	counters := b.counters[channel]
+	counters.full.Add(1)
+	if b.policies[channel] == ChannelDrop {
+		counters.dropped.Add(1)
+		return false
+	}
+	return true
+}
+
+// Counts returns the full and dropped counts of each channel in display order
+func (b *Backpressure) Counts() []ChannelCounts {
+	if b == nil {
+		return nil
+	}
+	counts := make([]ChannelCounts, 0, len(channelNames))
+	for _, channel := range channelNames {
+		counters := b.counters[channel]
+		counts = append(counts, ChannelCounts{
+			Channel: channel,
+			Policy:  b.policies[channel],
+			Full:    counters.full.Load(),
+			Dropped: counters.dropped.Load(),
+		})
+	}
+	return counts
+}
+
+// SetBackpressure sets the shared metrics and policies applied when the
+// position, balance or error channel is full
+func (c *OKXClient) SetBackpressure(backpressure *Backpressure) {
+	c.backpressure = backpressure
+}
+
+// sendError sends a status, warning or error message to the UI, with "DEBUG:"
+// traces under the debug channel's policy
+func (c *OKXClient) sendError(msg string) {
+	channel := ChannelError
+	if strings.HasPrefix(msg, "DEBUG:") {
+		channel = ChannelDebug
+	}
+	select {
+	case c.errorCh <- msg:
+	default:
+		if c.backpressure.onFull(channel) {
+			c.errorCh <- msg
+		}
+	}
+}
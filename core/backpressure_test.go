@@ -0,0 +1,107 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// channelCounts returns the counts of one channel
+func channelCounts(b *Backpressure, channel string) ChannelCounts {
+	for _, counts := range b.Counts() {
+		if counts.Channel == channel {
+			return counts
+		}
+	}
+	return ChannelCounts{}
+}
+
+func TestParseChannelPolicies(t *testing.T) {
+	policies, err := ParseChannelPolicies(" Error=drop, debug=BLOCK ,")
+	if err != nil || len(policies) != 2 || policies[ChannelError] != ChannelDrop || policies[ChannelDebug] != ChannelBlock {
+		t.Fatalf("ParseChannelPolicies() = %v, %v", policies, err)
+	}
+	for _, value := range []string{"error", "trades=drop", "error=maybe"} {
+		if _, err := ParseChannelPolicies(value); err == nil {
+			t.Errorf("ParseChannelPolicies(%q) accepted an invalid policy", value)
+		}
+	}
+}
+
+func TestBackpressureDropsDebugTraces(t *testing.T) {
+	errorCh := make(chan string, 1)
+	c := NewOKXClient(make(chan PositionData, 1), make(chan BalanceData, 1), errorCh)
+	b := NewBackpressure(nil)
+	c.SetBackpressure(b)
+
+	c.sendError("DEBUG: first")
+	c.sendError("DEBUG: second") // Finds the channel full and is dropped
+	c.sendError("DEBUG: third")
+
+	if msg := <-errorCh; msg != "DEBUG: first" || len(errorCh) != 0 {
+		t.Errorf("received %q with %d more, want only the trace sent before the channel filled", msg, len(errorCh))
+	}
+	if got := channelCounts(b, ChannelDebug); got.Full != 2 || got.Dropped != 2 || got.Policy != ChannelDrop {
+		t.Errorf("debug counts = %+v, want 2 full and 2 dropped", got)
+	}
+	if got := channelCounts(b, ChannelError); got.Full != 0 {
+		t.Errorf("error counts = %+v, want debug traces counted on their own", got)
+	}
+}
+
+func TestBackpressureBlocksPositions(t *testing.T) {
+	positionCh := make(chan PositionData, 1)
+	c := NewOKXClient(positionCh, make(chan BalanceData, 1), make(chan string, 10))
+	b := NewBackpressure(nil)
+	c.SetBackpressure(b)
+
+	c.sendPosition(PositionData{InstrumentID: "BTC-USDT-SWAP"})
+	sent := make(chan struct{})
+	go func() {
+		c.sendPosition(PositionData{InstrumentID: "ETH-USDT-SWAP"})
+		close(sent)
+	}()
+
+	// The second send waits for the UI instead of dropping the update
+	select {
+	case <-sent:
+		t.Fatal("position sent into a full channel without blocking")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := channelCounts(b, ChannelPosition); got.Full != 1 || got.Dropped != 0 || got.Policy != ChannelBlock {
+		t.Errorf("position counts = %+v, want 1 full and none dropped", got)
+	}
+
+	for _, want := range []string{"BTC-USDT-SWAP", "ETH-USDT-SWAP"} {
+		if pos := receivePosition(t, positionCh); pos.InstrumentID != want {
+			t.Errorf("received %s, want %s", pos.InstrumentID, want)
+		}
+	}
+	<-sent
+}
+
+func TestBackpressureDropOverride(t *testing.T) {
+	balanceCh := make(chan BalanceData, 1)
+	c := NewOKXClient(make(chan PositionData, 1), balanceCh, make(chan string, 10))
+	b := NewBackpressure(map[string]ChannelPolicy{ChannelBalance: ChannelDrop})
+	c.SetBackpressure(b)
+
+	c.sendBalance(BalanceData{Currency: "USDT", TotalEquity: 1000})
+	c.sendBalance(BalanceData{Currency: "USDT", TotalEquity: 1001})
+
+	if balance := <-balanceCh; balance.TotalEquity != 1000 || len(balanceCh) != 0 {
+		t.Errorf("received %v with %d more, want the first balance only", balance.TotalEquity, len(balanceCh))
+	}
+	if got := channelCounts(b, ChannelBalance); got.Full != 1 || got.Dropped != 1 {
+		t.Errorf("balance counts = %+v, want 1 full and 1 dropped", got)
+	}
+	if got := channelCounts(b, ChannelPosition); got.Policy != ChannelBlock {
+		t.Errorf("position policy = %v, want the default block kept", got.Policy)
+	}
+}
+
+func TestNilBackpressureBlocks(t *testing.T) {
+	var b *Backpressure
+	if !b.onFull(ChannelDebug) || b.Counts() != nil {
+		t.Error("clients without metrics don't block uncounted")
+	}
+}
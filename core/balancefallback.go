@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultBalanceTimeout is how long after login the first account push may
+	// take before the balance is fetched over REST instead
+	defaultBalanceTimeout = 10 * time.Second
+
+	// balancePath is the REST endpoint of the account balance
+	balancePath = "/api/v5/account/balance"
+)
+
+// SetBalanceFallback sets how long after login to wait for the first account
+// push before fetching the balance from the REST API once. 0 disables the
+// fetch, so the balance stays blank until OKX pushes it.
+func (c *OKXClient) SetBalanceFallback(timeout time.Duration) {
+	c.balanceTimeout = timeout
+}
+
+// watchAccountSnapshot fetches the balance over REST when no account push
+// has arrived balanceTimeout after login, so the header isn't left without a
+// balance while positions already stream
+func (c *OKXClient) watchAccountSnapshot() {
+	select {
+	case <-c.ctx.Done():
+		return
+	case <-time.After(c.balanceTimeout):
+	}
+	if c.accountSeen.Load() {
+		return
+	}
+
+	c.sendError(fmt.Sprintf("DEBUG: No account snapshot %s after login, fetching the balance over REST", c.balanceTimeout))
+	balances, err := c.fetchBalance()
+	if err != nil {
+		c.sendError(fmt.Sprintf("WARN: Balance fetch failed: %v", err))
+		return
+	}
+	for _, balance := range balances {
+		c.sendBalance(balance)
+	}
+}
+
+// fetchBalance requests the account balance from the signed REST API. The
+// response carries the same fields as the account channel.
+func (c *OKXClient) fetchBalance() ([]BalanceData, error) {
+	data, err := c.signedGet(balancePath)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]BalanceData, 0, len(data))
+	for _, item := range data {
+		balances = append(balances, c.parseBalanceData(item))
+	}
+	return balances, nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// balanceServer serves a USDT balance on the REST balance endpoint and counts
+// the signed requests it receives
+func balanceServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != balancePath || r.Header.Get("OK-ACCESS-KEY") != "key" || r.Header.Get("OK-ACCESS-SIGN") == "" {
+			t.Errorf("unexpected request %s with key %q", r.URL.Path, r.Header.Get("OK-ACCESS-KEY"))
+		}
+		requests++
+		w.Write([]byte(`{"code":"0","data":[{"ccy":"USDT","totalEq":"1000","availBal":"900"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestBalanceFallbackFetchesWithoutAccountPush(t *testing.T) {
+	server, requests := balanceServer(t)
+	c, _, balanceCh, _ := newTestDemoClient()
+	c.isDemo = false
+	c.apiKey, c.secretKey, c.passphrase = "key", "secret", "pass"
+	c.restURL = server.URL
+	c.SetBalanceFallback(10 * time.Millisecond)
+
+	c.watchAccountSnapshot()
+
+	select {
+	case balance := <-balanceCh:
+		if balance.Currency != "USDT" || balance.TotalEquity != 1000 || balance.AvailBalance != 900 {
+			t.Errorf("fetched balance = %+v, want 1000 USDT with 900 available", balance)
+		}
+	default:
+		t.Fatal("no balance sent after the timeout")
+	}
+	if *requests != 1 {
+		t.Errorf("%d balance requests, want 1", *requests)
+	}
+}
+
+func TestBalanceFallbackSkippedOnceAccountSeen(t *testing.T) {
+	server, requests := balanceServer(t)
+	c, _, balanceCh, _ := newTestDemoClient()
+	c.isDemo = false
+	c.apiKey, c.secretKey, c.passphrase = "key", "secret", "pass"
+	c.restURL = server.URL
+	c.SetBalanceFallback(10 * time.Millisecond)
+	c.accountSeen.Store(true)
+
+	c.watchAccountSnapshot()
+
+	if *requests != 0 || len(balanceCh) != 0 {
+		t.Errorf("%d requests and %d balances sent after an account push, want none", *requests, len(balanceCh))
+	}
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// defaultSubscribeBatchSize is how many channels go in one subscribe
+	// request, well under OKX's 64 KB request limit
+	defaultSubscribeBatchSize = 50
+
+	// defaultSubscribeBatchDelay paces subscribe requests under OKX's limit of
+	// 3 requests per second per connection
+	defaultSubscribeBatchDelay = 350 * time.Millisecond
+
+	// defaultRateLimitBackoff is how long subscriptions wait after OKX rejects
+	// a request as too frequent before they are sent again
+	defaultRateLimitBackoff = time.Second
+)
+
+// SetSubscribeBatching splits subscribe and unsubscribe requests into batches
+// of at most size channels, sent delay apart. A size of 0 or less, or a
+// negative delay, keeps the default.
+func (c *OKXClient) SetSubscribeBatching(size int, delay time.Duration) {
+	if size > 0 {
+		c.subBatchSize = size
+	}
+	if delay >= 0 {
+		c.subBatchDelay = delay
+	}
+}
+
+// sendBatched sends op ("subscribe" or "unsubscribe") for args on conn in
+// paced batches. The write mutex is only held for each write, so heartbeats
+// keep flowing between batches. Subscribed channels are counted up front, so
+// acknowledgements are only reported complete once every batch is in.
+func (c *OKXClient) sendBatched(conn *websocket.Conn, mu *sync.Mutex, op string, args []map[string]string) error {
+	if op == "subscribe" {
+		c.subAcksPending.Add(int64(len(args)))
+	}
+
+	batches := 0
+	for start := 0; start < len(args); start += c.subBatchSize {
+		if batches > 0 {
+			select {
+			case <-time.After(c.subBatchDelay):
+			case <-c.ctx.Done():
+				c.unsentBatches(op, len(args)-start)
+				return c.ctx.Err()
+			}
+		}
+
+		end := start + c.subBatchSize
+		if end > len(args) {
+			end = len(args)
+		}
+
+		mu.Lock()
+		if c.stopping() {
+			mu.Unlock()
+			c.unsentBatches(op, len(args)-start)
+			return c.ctx.Err()
+		}
+		err := conn.WriteJSON(map[string]interface{}{
+			"op":   op,
+			"args": args[start:end],
+		})
+		mu.Unlock()
+		if err != nil {
+			c.unsentBatches(op, len(args)-start)
+			return err
+		}
+		batches++
+	}
+
+	if batches > 1 {
+		c.sendError(fmt.Sprintf("DEBUG: Sent %d channels to %s in %d batches", len(args), op, batches))
+	}
+	return nil
+}
+
+// unsentBatches stops waiting for acknowledgements of channels whose
+// subscribe request was never sent
+func (c *OKXClient) unsentBatches(op string, unsent int) {
+	if op == "subscribe" {
+		c.subAcksPending.Add(-int64(unsent))
+	}
+}
+
+// requeueSubscriptions resends the subscriptions still awaiting acknowledgement
+// once the rate limit backoff has passed, on the same connections. A backoff
+// already waiting covers every rejection until it resends.
+func (c *OKXClient) requeueSubscriptions() {
+	if c.subAcksPending.Load() <= 0 || !c.backingOff.CompareAndSwap(false, true) {
+		return
+	}
+	c.sendError(fmt.Sprintf("DEBUG: Rate limited, resending subscriptions in %s", c.rateLimitBackoff))
+
+	c.goSafe(func() {
+		defer c.backingOff.Store(false)
+		select {
+		case <-time.After(c.rateLimitBackoff):
+		case <-c.ctx.Done():
+			return
+		}
+
+		// Which batch was rejected is unknown, so every channel is sent again
+		// and counted afresh; OKX acknowledges repeated subscriptions as usual
+		c.subAcksPending.Store(0)
+		c.resetPriceSubscriptions()
+		if err := c.subscribe(); err != nil {
+			c.sendError(fmt.Sprintf("Failed to resend subscriptions: %v", err))
+		}
+	})
+}
+
+// ackSubscription counts one subscribe acknowledgement from OKX, reporting
+// once every channel sent so far has been acknowledged
+func (c *OKXClient) ackSubscription() {
+	for {
+		pending := c.subAcksPending.Load()
+		if pending <= 0 {
+			return
+		}
+		if c.subAcksPending.CompareAndSwap(pending, pending-1) {
+			if pending == 1 {
+				c.sendError("DEBUG: All subscriptions acknowledged")
+			}
+			return
+		}
+	}
+}
+
+// handleTickerEvent handles subscription events on the ticker connection:
+// acknowledgements are counted and errors reported, backing off from rate limits
+func (c *OKXClient) handleTickerEvent(event string, response map[string]interface{}) {
+	switch event {
+	case "subscribe":
+		c.ackSubscription()
+	case "error":
+		code, _ := response["code"].(string)
+		msg, _ := response["msg"].(string)
+		c.sendError("Ticker connection: " + describeError(code, msg))
+		c.backOffOnError(code)
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribeBatchesArePacedAndAcked(t *testing.T) {
+	c, _, _, errorCh := newTestDemoClient()
+	c.SetSubscribeBatching(30, 20*time.Millisecond)
+	conn, received := recordingConn(t)
+
+	var args []map[string]string
+	for i := 0; i < 100; i++ {
+		args = append(args, map[string]string{"channel": "tickers", "instId": fmt.Sprintf("COIN%d-USDT-SWAP", i)})
+	}
+	start := time.Now()
+	if err := c.sendBatched(conn, &c.tickerMutex, "subscribe", args); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("4 batches sent in %s, want them 20ms apart", elapsed)
+	}
+
+	// Batches arrive in order with every channel once
+	var sizes []int
+	seen := make(map[string]bool)
+	for deadline := time.Now().Add(time.Second); len(sizes) < 4 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		sizes = sizes[:0]
+		for _, msg := range received() {
+			batch, _ := msg["args"].([]interface{})
+			sizes = append(sizes, len(batch))
+			for _, arg := range batch {
+				seen[arg.(map[string]interface{})["instId"].(string)] = true
+			}
+		}
+	}
+	if fmt.Sprint(sizes) != "[30 30 30 10]" || len(seen) != 100 {
+		t.Errorf("batch sizes %v covering %d instruments, want [30 30 30 10] covering 100", sizes, len(seen))
+	}
+
+	// Completion is only reported once the last of the 100 acks arrives
+	for i := 0; i < 99; i++ {
+		c.ackSubscription()
+	}
+	if countMessages(errorCh, "All subscriptions acknowledged") != 0 {
+		t.Error("acknowledged before every channel was")
+	}
+	c.ackSubscription()
+	if countMessages(errorCh, "All subscriptions acknowledged") != 1 {
+		t.Error("completion not reported after the last ack")
+	}
+}
+
+func TestSubscribeBatchesStopOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewOKXClientWithContext(ctx, make(chan PositionData, 10), make(chan BalanceData, 10), make(chan string, 100))
+	c.SetSubscribeBatching(10, time.Hour)
+	conn, _ := recordingConn(t)
+
+	args := make([]map[string]string, 25)
+	done := make(chan error, 1)
+	go func() { done <- c.sendBatched(conn, &c.tickerMutex, "subscribe", args) }()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("sendBatched() = nil after shutdown")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("still pacing after shutdown")
+	}
+	if pending := c.subAcksPending.Load(); pending != 10 {
+		t.Errorf("%d acks pending, want only the 10 sent", pending)
+	}
+}
+
+func TestRateLimitResendsUnackedSubscriptions(t *testing.T) {
+	c, _, _, errorCh := newTestDemoClient()
+	c.isDemo = false
+	c.rateLimitBackoff = 20 * time.Millisecond
+	conn, received := recordingConn(t)
+	c.tickerConn = conn
+
+	c.parsePositionData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "posSide": "long", "pos": "1", "avgPx": "100"})
+	sent := len(sentSince(t, conn, received, 0)) + 1
+	channels := c.subAcksPending.Load()
+	if channels == 0 {
+		t.Fatal("no acks pending for the BTC subscription")
+	}
+
+	// OKX rejects the subscription as too frequent, on the same connection
+	rateLimited := map[string]interface{}{"event": "error", "code": "60014", "msg": "Requests too frequent."}
+	if c.reconnectOnError("60014") {
+		t.Error("rate limit reconnects")
+	}
+	c.handleTickerEvent("error", rateLimited)
+	c.handleTickerEvent("error", rateLimited) // Covered by the backoff already waiting
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if msgs := received(); len(msgs) > sent && len(tickerInstruments(msgs[sent:], "subscribe")) > 0 {
+			break
+		}
+	}
+	time.Sleep(50 * time.Millisecond) // Long enough for a second resend to show
+	c.tickerMutex.Lock()              // Orders the marker after the resend's writes
+	c.tickerMutex.Unlock()
+	msgs := sentSince(t, conn, received, sent)
+	sent += len(msgs) + 1
+	if got := tickerInstruments(msgs, "subscribe"); fmt.Sprint(got) != "[BTC-USDT-SWAP]" {
+		t.Errorf("resent %v after the backoff, want BTC-USDT-SWAP once", got)
+	}
+	if pending := c.subAcksPending.Load(); pending != channels {
+		t.Errorf("%d acks pending after resending, want %d counted afresh", pending, channels)
+	}
+	if countMessages(errorCh, "Rate limited, resending subscriptions") != 1 {
+		t.Error("no DEBUG message for the backoff")
+	}
+
+	// Once everything is acknowledged there is nothing to resend
+	for i := int64(0); i < channels; i++ {
+		c.ackSubscription()
+	}
+	c.handleTickerEvent("error", rateLimited)
+	time.Sleep(50 * time.Millisecond)
+	if got := tickerInstruments(sentSince(t, conn, received, sent), "subscribe"); len(got) != 0 {
+		t.Errorf("resent %v with every subscription acknowledged", got)
+	}
+}
+
+// countMessages drains errorCh, counting messages containing text
+func countMessages(errorCh <-chan string, text string) int {
+	n := 0
+	for len(errorCh) > 0 {
+		if strings.Contains(<-errorCh, text) {
+			n++
+		}
+	}
+	return n
+}
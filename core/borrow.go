@@ -0,0 +1,62 @@
+package core
+
+import "math"
+
+// BorrowData is the borrowing of one currency in a margin account.
+//
+// It maps these fields of the account channel's details array:
+//
+//	liab     -> Liability (OKX reports liabilities as negative, stored as positive)
+//	interest -> Interest, accrued and not yet repaid
+//	maxLoan  -> MaxLoan, how much more of the currency can be borrowed
+type BorrowData struct {
+	Currency  string
+	Liability float64
+	Interest  float64
+	MaxLoan   float64
+}
+
+// parseBorrows extracts the currencies with outstanding borrowing from the
+// account channel's details array. Accounts without borrowing return nil.
+func (c *OKXClient) parseBorrows(data map[string]interface{}) []BorrowData {
+	details, ok := data["details"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var borrows []BorrowData
+	for _, item := range details {
+		detail, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		borrow := BorrowData{Currency: getString(detail, "ccy")}
+		if c.parseNumber(borrow.Currency, "liab", getString(detail, "liab"), &borrow.Liability) {
+			borrow.Liability = math.Abs(borrow.Liability)
+		}
+		if c.parseNumber(borrow.Currency, "interest", getString(detail, "interest"), &borrow.Interest) {
+			borrow.Interest = math.Abs(borrow.Interest)
+		}
+		c.parseNumber(borrow.Currency, "maxLoan", getString(detail, "maxLoan"), &borrow.MaxLoan)
+
+		if borrow.Liability > 0 || borrow.Interest > 0 {
+			borrows = append(borrows, borrow)
+		}
+	}
+	return borrows
+}
+
+// parseAutoLoan reads the auto-borrow setting. OKX only includes autoLoan for
+// some account modes, so reported is false when the field is absent.
+func parseAutoLoan(data map[string]interface{}) (enabled, reported bool) {
+	switch value := data["autoLoan"].(type) {
+	case bool:
+		return value, true
+	case string:
+		if value == "true" || value == "false" {
+			return value == "true", true
+		}
+	}
+	return false, false
+}
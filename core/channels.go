@@ -0,0 +1,105 @@
+package core
+
+// ChannelHandler processes the data array pushed on an OKX channel along with
+// the channel argument (channel name, instId, ...)
+type ChannelHandler func(arg map[string]interface{}, data []interface{})
+
+// RegisterChannelHandler registers fn for pushes on the named OKX channel on both
+// the main and ticker connections, replacing any built-in handler for that channel
+func (c *OKXClient) RegisterChannelHandler(channel string, fn func(arg map[string]interface{}, data []interface{})) {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+
+	c.mainHandlers[channel] = fn
+	c.tickerHandlers[channel] = fn
+}
+
+// registerDefaultHandlers registers the built-in channel handlers for the main
+// and ticker connections
+func (c *OKXClient) registerDefaultHandlers() {
+	c.mainHandlers = map[string]ChannelHandler{
+		"account":   c.handleAccountChannel,
+		"positions": c.handlePositionsChannel,
+		"tickers":   c.handlePositionsChannel,
+	}
+
+	c.tickerHandlers = map[string]ChannelHandler{
+		"tickers":    c.handleTickersChannel,
+		"mark-price": c.handleTickersChannel,
+		"trades":     c.handleTradesChannel,
+		"books5":     c.handleBooksChannel,
+	}
+}
+
+// dispatchChannel routes a data push to the handler registered for its channel,
+// reporting whether the message carried a channel argument
+func (c *OKXClient) dispatchChannel(handlers map[string]ChannelHandler, response map[string]interface{}, data []interface{}) bool {
+	arg, ok := response["arg"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if channel, ok := arg["channel"].(string); ok {
+		c.handlersMutex.RLock()
+		handler, exists := handlers[channel]
+		c.handlersMutex.RUnlock()
+
+		if exists {
+			handler(arg, data)
+		}
+	}
+	return true
+}
+
+// handleAccountChannel handles balance data from the account channel
+func (c *OKXClient) handleAccountChannel(arg map[string]interface{}, data []interface{}) {
+	c.debugf(debugPosition, "Received %d balance items", len(data))
+	c.accountSeen.Store(true)
+	for _, item := range data {
+		if balData, ok := item.(map[string]interface{}); ok {
+			balance := c.parseBalanceData(balData)
+			c.debugf(debugPosition, "Parsed balance data for %s", balance.Currency)
+			c.sendBalance(balance)
+		}
+	}
+}
+
+// handlePositionsChannel handles position/ticker data on the main connection
+func (c *OKXClient) handlePositionsChannel(arg map[string]interface{}, data []interface{}) {
+	c.debugf(debugPosition, "Received %d position/ticker items", len(data))
+	for _, item := range data {
+		if posData, ok := item.(map[string]interface{}); ok {
+			position := c.parsePositionData(posData)
+			c.instDebugf(position.InstrumentID, debugPosition, "Parsed position data for %s", position.InstrumentID)
+			c.sendPosition(position)
+		}
+	}
+}
+
+// handleTickersChannel handles ticker data on the ticker connection
+func (c *OKXClient) handleTickersChannel(arg map[string]interface{}, data []interface{}) {
+	c.debugf(debugTicker, "Received %d ticker items", len(data))
+	for _, item := range data {
+		if tickerData, ok := item.(map[string]interface{}); ok {
+			c.handleTickerData(tickerData)
+		}
+	}
+}
+
+// handleTradesChannel handles public trade prints on the ticker connection
+func (c *OKXClient) handleTradesChannel(arg map[string]interface{}, data []interface{}) {
+	for _, item := range data {
+		if tradeData, ok := item.(map[string]interface{}); ok {
+			c.handleTradeData(tradeData)
+		}
+	}
+}
+
+// handleBooksChannel handles books5 depth snapshots on the ticker connection
+func (c *OKXClient) handleBooksChannel(arg map[string]interface{}, data []interface{}) {
+	for _, item := range data {
+		if bookData, ok := item.(map[string]interface{}); ok {
+			c.handleBookData(arg, bookData)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// pushFrame decodes a raw frame and routes it as the connection owning
+// handlers does, reporting whether it carried a channel argument
+func pushFrame(t *testing.T, c *OKXClient, handlers map[string]ChannelHandler, frame string) bool {
+	t.Helper()
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(frame), &response); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := response["data"].([]interface{})
+	return c.dispatchChannel(handlers, response, data)
+}
+
+func TestRegisterChannelHandler(t *testing.T) {
+	c, positionCh, _, _ := newTestDemoClient()
+
+	type push struct {
+		instID string
+		items  int
+	}
+	var pushes []push
+	c.RegisterChannelHandler("liquidation-orders", func(arg map[string]interface{}, data []interface{}) {
+		instID, _ := arg["instId"].(string)
+		pushes = append(pushes, push{instID, len(data)})
+	})
+
+	// Both connections route the channel to the registered handler
+	pushFrame(t, c, c.mainHandlers, `{"arg":{"channel":"liquidation-orders","instId":"BTC-USDT-SWAP"},"data":[{},{}]}`)
+	pushFrame(t, c, c.tickerHandlers, `{"arg":{"channel":"liquidation-orders","instId":"ETH-USDT-SWAP"},"data":[{}]}`)
+	want := []push{{"BTC-USDT-SWAP", 2}, {"ETH-USDT-SWAP", 1}}
+	if len(pushes) != len(want) {
+		t.Fatalf("handler got %v, want %v", pushes, want)
+	}
+	for i := range want {
+		if pushes[i] != want[i] {
+			t.Errorf("push %d = %v, want %v", i, pushes[i], want[i])
+		}
+	}
+
+	// Unknown channels are ignored, still counting as routed so the main
+	// connection doesn't fall back to parsing them as positions
+	frame := `{"arg":{"channel":"funding-rate","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP","pos":"1"}]}`
+	if !pushFrame(t, c, c.mainHandlers, frame) || !pushFrame(t, c, c.tickerHandlers, frame) {
+		t.Error("frame with a channel argument reported as unrouted")
+	}
+	if len(pushes) != len(want) {
+		t.Errorf("unknown channel reached the handler: %v", pushes)
+	}
+	select {
+	case pos := <-positionCh:
+		t.Errorf("unknown channel sent position %+v", pos)
+	default:
+	}
+
+	// Frames without an argument are left to the caller
+	if pushFrame(t, c, c.mainHandlers, `{"data":[{}]}`) {
+		t.Error("frame without a channel argument reported as routed")
+	}
+}
+
+func TestRegisterChannelHandlerReplacesBuiltIn(t *testing.T) {
+	c, positionCh, _, _ := newTestDemoClient()
+	called := 0
+	c.RegisterChannelHandler("positions", func(arg map[string]interface{}, data []interface{}) { called++ })
+
+	pushFrame(t, c, c.mainHandlers, `{"arg":{"channel":"positions"},"data":[{"instId":"BTC-USDT-SWAP","pos":"1"}]}`)
+	if called != 1 {
+		t.Errorf("handler called %d times, want 1", called)
+	}
+	select {
+	case pos := <-positionCh:
+		t.Errorf("built-in positions handler still ran, sent %+v", pos)
+	default:
+	}
+}
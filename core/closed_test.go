@@ -0,0 +1,98 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// sentSince writes a marker and returns the messages received after the
+// first skip up to it, so everything sent before the marker has arrived
+func sentSince(t *testing.T, conn *websocket.Conn, received func() []map[string]interface{}, skip int) []map[string]interface{} {
+	t.Helper()
+	if err := conn.WriteJSON(map[string]string{"op": "marker"}); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if msgs := received(); len(msgs) > skip && msgs[len(msgs)-1]["op"] == "marker" {
+			return msgs[skip : len(msgs)-1]
+		}
+	}
+	t.Fatal("marker never arrived")
+	return nil
+}
+
+// tickerInstruments returns the instruments of the ticker channels that op
+// (subscribe or unsubscribe) is sent for in msgs
+func tickerInstruments(msgs []map[string]interface{}, op string) []string {
+	var instIds []string
+	for _, msg := range msgs {
+		if msg["op"] != op {
+			continue
+		}
+		args, _ := msg["args"].([]interface{})
+		for _, arg := range args {
+			arg, _ := arg.(map[string]interface{})
+			if arg["channel"] == "tickers" {
+				instIds = append(instIds, arg["instId"].(string))
+			}
+		}
+	}
+	return instIds
+}
+
+func TestClosedPositionUnsubscribed(t *testing.T) {
+	c, _, _, errorCh := newTestDemoClient()
+	c.isDemo = false
+	conn, received := recordingConn(t)
+	c.tickerConn = conn
+
+	position := func(instId, side, size string) {
+		c.parsePositionData(map[string]interface{}{"instId": instId, "posSide": side, "pos": size, "avgPx": "100"})
+	}
+	position("BTC-USDT-SWAP", "long", "1")
+	position("ETH-USDT-SWAP", "long", "2")
+	position("ETH-USDT-SWAP", "short", "2")
+	sent := len(sentSince(t, conn, received, 0)) + 1
+
+	position("BTC-USDT-SWAP", "long", "0")
+	msgs := sentSince(t, conn, received, sent)
+	sent += len(msgs) + 1
+	if got := tickerInstruments(msgs, "unsubscribe"); len(got) != 1 || got[0] != "BTC-USDT-SWAP" {
+		t.Errorf("closing BTC unsubscribed %v, want only BTC-USDT-SWAP", got)
+	}
+	if c.currentPositions["BTC-USDT-SWAP"] {
+		t.Error("closed BTC position still tracked")
+	}
+	if countMessages(errorCh, "DEBUG: Removed position tracking for BTC-USDT-SWAP (position closed)") != 1 {
+		t.Error("no DEBUG message naming the closed instrument")
+	}
+
+	// A hedged instrument stays subscribed until both legs close
+	position("ETH-USDT-SWAP", "long", "0")
+	msgs = sentSince(t, conn, received, sent)
+	sent += len(msgs) + 1
+	if got := tickerInstruments(msgs, "unsubscribe"); len(got) != 0 {
+		t.Errorf("closing one ETH leg unsubscribed %v, want nothing while the short is open", got)
+	}
+	position("ETH-USDT-SWAP", "short", "0")
+	if got := tickerInstruments(sentSince(t, conn, received, sent), "unsubscribe"); len(got) != 1 || got[0] != "ETH-USDT-SWAP" {
+		t.Errorf("closing both ETH legs unsubscribed %v, want ETH-USDT-SWAP", got)
+	}
+	if len(c.currentPositions) != 0 || len(c.openSides) != 0 {
+		t.Errorf("still tracking %v (%v) after every position closed", c.currentPositions, c.openSides)
+	}
+}
+
+func TestClosedPositionNoMessageWhenUntracked(t *testing.T) {
+	c, _, _, errorCh := newTestDemoClient()
+	c.isDemo = false
+	c.parsePositionData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "posSide": "long", "pos": "0"})
+	for len(errorCh) > 0 {
+		if msg := <-errorCh; strings.Contains(msg, "position closed") {
+			t.Errorf("closing a position never opened logged %q", msg)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// closedHistoryPoll is how often recently closed positions are fetched
+	closedHistoryPoll = time.Minute
+
+	// closedHistoryPath is the REST endpoint of closed positions, newest first
+	closedHistoryPath = "/api/v5/account/positions-history"
+
+	// closedHistoryPageSize is the most records OKX returns per page
+	closedHistoryPageSize = 100
+
+	// closedHistoryMaxPages bounds the pages fetched per poll, so a long
+	// lookback on a busy account can't page through months of history
+	closedHistoryMaxPages = 10
+)
+
+// ClosedPosition is a position closed within the recently closed lookback
+type ClosedPosition struct {
+	InstrumentID  string
+	PositionSide  string // "long" or "short", from the trade direction in net mode
+	RealizedPnL   float64
+	CloseAvgPrice float64
+	Currency      string
+	ClosedAt      time.Time
+}
+
+// ClosedHistory is one account's positions closed within the lookback,
+// newest first. Each poll sends the full list again.
+type ClosedHistory struct {
+	Account   string
+	Positions []ClosedPosition
+}
+
+// SetClosedHistory polls the positions closed within lookback to closedCh
+// once logged in. A nil channel or a lookback of 0 leaves polling off.
+func (c *OKXClient) SetClosedHistory(closedCh chan<- ClosedHistory, lookback time.Duration) {
+	if lookback <= 0 {
+		closedCh = nil
+	}
+	c.closedCh = closedCh
+	c.closedLookback = lookback
+}
+
+// pollClosedHistory fetches the recently closed positions now and then every
+// poll interval until the client shuts down. Only a failed first fetch is
+// raised as a warning, later ones are noted in the debug pane.
+func (c *OKXClient) pollClosedHistory() {
+	fetched := false
+	ticker := time.NewTicker(closedHistoryPoll)
+	defer ticker.Stop()
+	for {
+		positions, err := c.fetchClosedHistory(time.Now().Add(-c.closedLookback))
+		switch {
+		case err == nil:
+			fetched = true
+			select {
+			case c.closedCh <- ClosedHistory{Account: c.account, Positions: positions}:
+			case <-c.ctx.Done():
+				return
+			}
+		case !fetched:
+			c.sendError(fmt.Sprintf("WARN: Recently closed positions unavailable: %v", err))
+		default:
+			c.sendError(fmt.Sprintf("DEBUG: Closed position history fetch failed: %v", err))
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchClosedHistory pages through the closed positions from newest to
+// oldest until it passes since, each page continuing after the last record's
+// update time
+func (c *OKXClient) fetchClosedHistory(since time.Time) ([]ClosedPosition, error) {
+	var positions []ClosedPosition
+	after := ""
+	for page := 0; page < closedHistoryMaxPages; page++ {
+		query := url.Values{"limit": {strconv.Itoa(closedHistoryPageSize)}}
+		if after != "" {
+			query.Set("after", after)
+		}
+		data, err := c.signedGet(closedHistoryPath + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range data {
+			closed := c.parseClosedPosition(item)
+			if closed.ClosedAt.Before(since) {
+				return positions, nil
+			}
+			positions = append(positions, closed)
+		}
+		if len(data) < closedHistoryPageSize {
+			return positions, nil
+		}
+		after = getString(data[len(data)-1], "uTime")
+	}
+	c.sendError(fmt.Sprintf("DEBUG: Closed position history capped at %d records", len(positions)))
+	return positions, nil
+}
+
+// parseClosedPosition converts a positions-history record to a ClosedPosition
+func (c *OKXClient) parseClosedPosition(data map[string]interface{}) ClosedPosition {
+	closed := ClosedPosition{
+		InstrumentID: getString(data, "instId"),
+		PositionSide: getString(data, "posSide"),
+		Currency:     getString(data, "ccy"),
+	}
+	if closed.PositionSide == "" || closed.PositionSide == "net" {
+		closed.PositionSide = getString(data, "direction")
+	}
+	c.parseNumber(closed.InstrumentID, "realizedPnl", getString(data, "realizedPnl"), &closed.RealizedPnL)
+	c.parseNumber(closed.InstrumentID, "closeAvgPx", getString(data, "closeAvgPx"), &closed.CloseAvgPrice)
+	if ms, err := strconv.ParseInt(getString(data, "uTime"), 10, 64); err == nil {
+		closed.ClosedAt = time.UnixMilli(ms)
+	}
+	return closed
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// historyServer serves closed positions one minute apart, newest first, paged
+// like positions-history, and records the after cursor of each request
+func historyServer(t *testing.T, now time.Time, records int) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != closedHistoryPath {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		after := r.URL.Query().Get("after")
+		mu.Lock()
+		cursors = append(cursors, after)
+		mu.Unlock()
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var data []map[string]string
+		for i := 0; i < records && len(data) < limit; i++ {
+			uTime := now.Add(-time.Duration(i) * time.Minute).UnixMilli()
+			if after != "" {
+				if cursor, _ := strconv.ParseInt(after, 10, 64); uTime >= cursor {
+					continue
+				}
+			}
+			data = append(data, map[string]string{
+				"instId": "BTC-USDT-SWAP", "posSide": "net", "direction": "long",
+				"realizedPnl": "1.5", "closeAvgPx": "50000", "ccy": "USDT",
+				"uTime": strconv.FormatInt(uTime, 10),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": "0", "data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), cursors...)
+	}
+}
+
+func TestFetchClosedHistoryPages(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	tests := []struct {
+		name      string
+		records   int
+		lookback  time.Duration
+		wantCount int
+		wantPages int
+	}{
+		{"one short page", 30, 24 * time.Hour, 30, 1},
+		{"pages until the lookback", 500, 150*time.Minute - time.Second, 150, 2},
+		{"full last page fetches one more", 200, 24 * time.Hour, 200, 3},
+		{"capped at the page limit", 2000, 48 * time.Hour, closedHistoryPageSize * closedHistoryMaxPages, closedHistoryMaxPages},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cursors := historyServer(t, now, tt.records)
+			c, _, _, _ := newTestDemoClient()
+			c.isDemo = false
+			c.restURL = server.URL
+
+			positions, err := c.fetchClosedHistory(now.Add(-tt.lookback))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(positions) != tt.wantCount {
+				t.Errorf("%d closed positions, want %d", len(positions), tt.wantCount)
+			}
+			for i := 1; i < len(positions); i++ {
+				if !positions[i].ClosedAt.Before(positions[i-1].ClosedAt) {
+					t.Fatalf("position %d closed at %v, not before %v", i, positions[i].ClosedAt, positions[i-1].ClosedAt)
+				}
+			}
+
+			pages := cursors()
+			if len(pages) != tt.wantPages {
+				t.Fatalf("fetched %d pages, want %d", len(pages), tt.wantPages)
+			}
+			// Each page continues after the last record of the one before
+			for i := 1; i < len(pages); i++ {
+				want := strconv.FormatInt(positions[i*closedHistoryPageSize-1].ClosedAt.UnixMilli(), 10)
+				if pages[i] != want {
+					t.Errorf("page %d after = %q, want %q", i+1, pages[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseClosedPosition(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	got := c.parseClosedPosition(map[string]interface{}{
+		"instId": "ETH-USDT-SWAP", "posSide": "net", "direction": "short",
+		"realizedPnl": "-12.5", "closeAvgPx": "3100", "ccy": "USDT", "uTime": "1700000000000",
+	})
+	want := ClosedPosition{
+		InstrumentID:  "ETH-USDT-SWAP",
+		PositionSide:  "short", // From the direction in net mode
+		RealizedPnL:   -12.5,
+		CloseAvgPrice: 3100,
+		Currency:      "USDT",
+		ClosedAt:      time.UnixMilli(1700000000000),
+	}
+	if got != want {
+		t.Errorf("parseClosedPosition() = %+v, want %+v", got, want)
+	}
+}
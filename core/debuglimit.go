@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Debug message categories for rate limiting high-volume traces
+const (
+	debugTicker   = "ticker"   // Per-item ticker and market data traces
+	debugPosition = "position" // Per-item position and balance parsing traces
+	debugPnL      = "pnl"      // Per-field PnL and ratio derivation traces
+	debugParse    = "parse"    // Numeric fields OKX sent in an unexpected format
+)
+
+// debugLimiter caps debug messages per category to a number per second,
+// counting what it drops so a summary can be emitted in the next window, and
+// optionally restricts them to some instruments
+type debugLimiter struct {
+	mu          sync.Mutex
+	perSec      int
+	windows     map[string]*debugWindow
+	instruments map[string]bool // Instruments whose traces are emitted, nil for all
+}
+
+// debugWindow tracks one category's messages in the current second
+type debugWindow struct {
+	start   time.Time
+	sent    int
+	dropped int
+}
+
+// allow reports whether another message of the category may be sent, and how
+// many were dropped in the previous window if this one is the first after it
+func (l *debugLimiter) allow(category string, now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.windows == nil {
+		l.windows = make(map[string]*debugWindow)
+	}
+	window, ok := l.windows[category]
+	if !ok {
+		window = &debugWindow{start: now}
+		l.windows[category] = window
+	}
+
+	var dropped int
+	if now.Sub(window.start) >= time.Second {
+		dropped = window.dropped
+		*window = debugWindow{start: now}
+	}
+
+	if window.sent >= l.perSec {
+		window.dropped++
+		return false, 0
+	}
+	window.sent++
+	return true, dropped
+}
+
+// SetDebugRate limits debug messages to n per second per category, dropping
+// the excess with a summary. Zero or less disables the limit.
+func (c *OKXClient) SetDebugRate(n int) {
+	c.debugLimit.mu.Lock()
+	defer c.debugLimit.mu.Unlock()
+	c.debugLimit.perSec = n
+}
+
+// SetDebugInstruments restricts the high-volume debug traces to the given
+// instruments. Traces not tied to an instrument are dropped while the filter is
+// set; connection and subscription messages are unaffected. Empty clears it.
+func (c *OKXClient) SetDebugInstruments(instIds []string) {
+	c.debugLimit.mu.Lock()
+	defer c.debugLimit.mu.Unlock()
+
+	c.debugLimit.instruments = nil
+	for _, instId := range instIds {
+		if c.debugLimit.instruments == nil {
+			c.debugLimit.instruments = make(map[string]bool)
+		}
+		c.debugLimit.instruments[instId] = true
+	}
+}
+
+// debugf sends a rate limited debug message of the given category. Dropped
+// messages are never formatted, which keeps bursts cheap.
+func (c *OKXClient) debugf(category, format string, args ...interface{}) {
+	c.instDebugf("", category, format, args...)
+}
+
+// instDebugf is debugf for a trace about one instrument, dropped when the
+// instrument filter excludes it
+func (c *OKXClient) instDebugf(instId, category, format string, args ...interface{}) {
+	c.debugLimit.mu.Lock()
+	unlimited := c.debugLimit.perSec <= 0
+	filtered := c.debugLimit.instruments != nil && !c.debugLimit.instruments[instId]
+	c.debugLimit.mu.Unlock()
+	if filtered {
+		return
+	}
+	if unlimited {
+		c.sendError("DEBUG: " + fmt.Sprintf(format, args...))
+		return
+	}
+
+	ok, dropped := c.debugLimit.allow(category, time.Now())
+	if !ok {
+		return
+	}
+	if dropped > 0 {
+		c.sendError(fmt.Sprintf("DEBUG: …(dropped %d %s messages)", dropped, category))
+	}
+	c.sendError("DEBUG: " + fmt.Sprintf(format, args...))
+}
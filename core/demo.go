@@ -0,0 +1,274 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// DemoPosition describes a demo position to create
+type DemoPosition struct {
+	InstID   string  `json:"instId" yaml:"instId"`
+	AvgPrice float64 `json:"avgPx" yaml:"avgPx"`
+	Size     float64 `json:"size" yaml:"size"`
+	Side     string  `json:"side" yaml:"side"`   // "long" or "short"
+	Leverage float64 `json:"lever" yaml:"lever"` // 0 uses the instrument's leverage override, or defaultDemoLeverage
+}
+
+// defaultDemoLeverage is the leverage of demo positions that don't set one and
+// have no leverage override
+const defaultDemoLeverage = 10.0
+
+// defaultDemoInstruments is the fixed set of demo positions for 10 different
+// trading pairs, at the default demo leverage
+var defaultDemoInstruments = []DemoPosition{
+	{"BTC-USDT-SWAP", 45000.0, 0.1, "long", 0},
+	{"ETH-USDT-SWAP", 2800.0, 1.0, "long", 0},
+	{"SOL-USDT-SWAP", 178.0, 2.7, "short", 0},
+	{"ADA-USDT-SWAP", 0.45, 1000.0, "long", 0},
+	{"DOT-USDT-SWAP", 6.8, 50.0, "short", 0},
+	{"LINK-USDT-SWAP", 14.2, 25.0, "long", 0},
+	{"AVAX-USDT-SWAP", 28.5, 15.0, "short", 0},
+	{"MATIC-USDT-SWAP", 0.85, 500.0, "long", 0},
+	{"UNI-USDT-SWAP", 7.3, 40.0, "short", 0},
+	{"LTC-USDT-SWAP", 95.0, 3.0, "long", 0},
+}
+
+// SetDemoInstruments replaces the built-in demo positions. Demo count,
+// randomization and ticker subscriptions all work from this set; an empty
+// slice keeps the built-in one.
+func (c *OKXClient) SetDemoInstruments(positions []DemoPosition) {
+	c.demoTemplates = positions
+}
+
+// demoTemplateSet returns the configured demo positions, or the built-in set
+func (c *OKXClient) demoTemplateSet() []DemoPosition {
+	if len(c.demoTemplates) > 0 {
+		return c.demoTemplates
+	}
+	return defaultDemoInstruments
+}
+
+// LoadDemoPositions reads demo positions from a JSON array file
+func LoadDemoPositions(file string) ([]DemoPosition, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []DemoPosition
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	if err := ValidateDemoPositions(positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// ValidateDemoPositions checks that every demo position names an instrument
+// and has a positive entry and size, a long or short side and no negative leverage
+func ValidateDemoPositions(positions []DemoPosition) error {
+	for i, pos := range positions {
+		switch {
+		case pos.InstID == "":
+			return fmt.Errorf("demo position %d has no instId", i+1)
+		case pos.AvgPrice <= 0 || pos.Size <= 0:
+			return fmt.Errorf("demo position %s needs a positive avgPx and size", pos.InstID)
+		case pos.Side != "long" && pos.Side != "short":
+			return fmt.Errorf("demo position %s has side %q, want long or short", pos.InstID, pos.Side)
+		case pos.Leverage < 0:
+			return fmt.Errorf("demo position %s has negative leverage", pos.InstID)
+		}
+	}
+	return nil
+}
+
+// defaultDemoEquity is the demo account's equity before any demo PnL
+const defaultDemoEquity = 10000.0
+
+// SetDemoEquity sets the demo account's base equity, which the PnL of the
+// demo positions is added to. Non-positive values keep the default.
+func (c *OKXClient) SetDemoEquity(equity float64) {
+	if equity > 0 {
+		c.demoEquity = equity
+	}
+}
+
+// demoBalance returns the demo account balance: the base equity plus the PnL
+// of every demo position, less the margin they use for the available balance
+func (c *OKXClient) demoBalance() BalanceData {
+	c.demoMutex.Lock()
+	equity := c.demoEquity
+	var margin float64
+	for _, pos := range c.demoPositions {
+		equity += pos.PnL
+		margin += pos.Margin
+	}
+	c.demoMutex.Unlock()
+	return BalanceData{
+		Currency:     "USDT",
+		TotalEquity:  equity,
+		AvailBalance: equity - margin,
+		Timestamp:    nowMillis(),
+	}
+}
+
+// updateDemoPrices applies a ticker price to the demo position of its
+// instrument and to the synthetic ones following it, returning the positions
+// to send and whether their PnL was recalculated. It reports false when the
+// instrument has no demo position.
+func (c *OKXClient) updateDemoPrices(update tickerUpdate) ([]PositionData, bool, bool) {
+	c.demoMutex.Lock()
+	defer c.demoMutex.Unlock()
+
+	instId, lastPrice := update.instId, update.price
+	demoPos, exists := c.demoPositions[instId]
+	if !exists {
+		return nil, false, false
+	}
+	if update.last > 0 {
+		demoPos.LastPrice = update.last
+	}
+	if lastPrice <= 0 {
+		c.demoPositions[instId] = demoPos
+		return []PositionData{demoPos}, false, true
+	}
+
+	// Randomized demo positions take their entry near the first market price
+	if offset, pending := c.demoEntryOffsets[instId]; pending {
+		demoPos.AvgPrice = lastPrice * (1 + offset)
+		delete(c.demoEntryOffsets, instId)
+	}
+
+	// Update the current price and recalculate PnL
+	demoPos = recalcDemoPnL(demoPos, lastPrice)
+	demoPos.PriceIsLast = update.priceIsLast
+	demoPos.Timestamp = update.exchangeTs
+	demoPos.ReceivedAt = update.receivedAt
+	c.demoPositions[instId] = demoPos
+	updated := []PositionData{demoPos}
+
+	// Synthetic load-test instruments move with the instrument they follow
+	for _, follower := range c.demoFollowers[instId] {
+		if followerPos, exists := c.demoPositions[follower]; exists {
+			followerPos = recalcDemoPnL(followerPos, lastPrice)
+			followerPos.Timestamp = update.exchangeTs
+			followerPos.ReceivedAt = update.receivedAt
+			c.demoPositions[follower] = followerPos
+			updated = append(updated, followerPos)
+		}
+	}
+	return updated, true, true
+}
+
+// SetDemoRandom enables randomized demo positions, a zero seed uses the current time
+func (c *OKXClient) SetDemoRandom(seed int64) {
+	c.demoRandom = true
+	c.demoSeed = seed
+}
+
+// SetDemoCount sets how many demo positions to create. Counts beyond the
+// configured instruments add synthetic ones for load testing the UI.
+func (c *OKXClient) SetDemoCount(n int) {
+	c.demoCount = n
+}
+
+// demoInstrumentSet returns the demo positions to create, fixed or randomized,
+// trimmed or extended with synthetic instruments to the demo count
+func (c *OKXClient) demoInstrumentSet() []DemoPosition {
+	templates := c.demoTemplateSet()
+	count := c.demoCount
+	if count <= 0 {
+		count = len(templates)
+	}
+
+	// Use the fixed set by default for deterministic screenshots
+	instruments := templates
+	if c.demoRandom {
+		instruments = c.randomDemoInstruments()
+	}
+
+	if count <= len(templates) {
+		if count < len(instruments) {
+			instruments = instruments[:count]
+		}
+		return instruments
+	}
+
+	extended := make([]DemoPosition, 0, count)
+	extended = append(extended, instruments...)
+	return append(extended, c.syntheticDemoInstruments(count-len(templates))...)
+}
+
+// syntheticDemoInstruments generates n demo instruments named TEST1-USDT-SWAP,
+// TEST2-USDT-SWAP, ... OKX has no tickers for them, so each one follows the
+// price of a configured instrument.
+func (c *OKXClient) syntheticDemoInstruments(n int) []DemoPosition {
+	templates := c.demoTemplateSet()
+	var instruments []DemoPosition
+	for i := 0; i < n; i++ {
+		base := templates[i%len(templates)]
+		instId := fmt.Sprintf("TEST%d-USDT-SWAP", i+1)
+
+		// Spread entries within ±5% of the template and alternate sides so PnLs differ
+		entry := base.AvgPrice * (1 + float64(i%11-5)/100)
+		side := "long"
+		if i%2 == 1 {
+			side = "short"
+		}
+
+		instruments = append(instruments, DemoPosition{instId, entry, base.Size, side, base.Leverage})
+		c.demoFollowers[base.InstID] = append(c.demoFollowers[base.InstID], instId)
+	}
+	return instruments
+}
+
+// randomDemoInstruments picks a random subset of demo instruments with random
+// sizes and sides. Entry prices are set near the market on the first ticker.
+func (c *OKXClient) randomDemoInstruments() []DemoPosition {
+	seed := c.demoSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	c.sendError(fmt.Sprintf("DEBUG: Randomizing demo positions with seed %d", seed))
+
+	// Keep at least 3 instruments so the grid is never near-empty
+	pool := make([]DemoPosition, len(c.demoTemplateSet()))
+	copy(pool, c.demoTemplateSet())
+	rng.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+	count := len(pool)
+	if count > 3 {
+		count = 3 + rng.Intn(len(pool)-2)
+	}
+	if c.demoCount > len(pool) {
+		// Synthetic instruments are added on top of the full set
+		count = len(pool)
+	} else if c.demoCount > 0 && count > c.demoCount {
+		count = c.demoCount
+	}
+
+	var instruments []DemoPosition
+	for _, demo := range pool[:count] {
+		// Size between 0.25x and 3x of the template size
+		demo.Size *= 0.25 + rng.Float64()*2.75
+
+		if rng.Intn(2) == 0 {
+			demo.Side = "long"
+		} else {
+			demo.Side = "short"
+		}
+
+		// Entry within ±3% of the first observed market price
+		c.demoEntryOffsets[demo.InstID] = (rng.Float64()*2 - 1) * 0.03
+
+		instruments = append(instruments, demo)
+	}
+
+	return instruments
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrorAction is what the reconnect loop does after an OKX error
+type ErrorAction int
+
+const (
+	ErrorRetry   ErrorAction = iota // Reconnect with backoff, the error may clear by itself
+	ErrorFatal                      // Stop reconnecting, retrying can't fix it
+	ErrorBackoff                    // Keep the connection, resend unacknowledged subscriptions after a wait
+)
+
+// defaultErrorActions classifies OKX error event codes and WebSocket close
+// codes. A dropped connection is retried unless its code is fatal; an error
+// event on a live connection reconnects only for codes listed as retry, backs
+// off for rate limits and is otherwise just reported, as it mostly rejects a
+// single request.
+var defaultErrorActions = map[string]ErrorAction{
+	// Login errors: missing, invalid or revoked credentials
+	"60001": ErrorFatal, // OK-ACCESS-KEY can not be empty
+	"60002": ErrorFatal, // OK-ACCESS-SIGN can not be empty
+	"60003": ErrorFatal, // OK-ACCESS-PASSPHRASE can not be empty
+	"60005": ErrorFatal, // Invalid OK-ACCESS-KEY
+	"60007": ErrorFatal, // Invalid sign
+	"60009": ErrorFatal, // Login failed
+	"60024": ErrorFatal, // Wrong passphrase
+	"60032": ErrorFatal, // API key doesn't exist
+	"4001":  ErrorFatal, // Close: login failed
+	"4007":  ErrorFatal, // Close: API key has been updated or deleted
+
+	// Permission errors: valid credentials that can't read the account from here
+	"50110": ErrorFatal, // IP not in the API key's whitelist
+	"50114": ErrorFatal, // Invalid authority
+	"50120": ErrorFatal, // API key doesn't have permission
+
+	// Transient conditions, listed for clarity and to document the intent
+	"50001": ErrorRetry, // Service temporarily unavailable
+	"60006": ErrorRetry, // Timestamp request expired, e.g. after clock drift
+	"60023": ErrorRetry, // Bulk login requests too frequent
+	"63999": ErrorRetry, // Login failed due to internal error
+	"64008": ErrorRetry, // Connection closing for service upgrade
+	"4004":  ErrorRetry, // Close: no data received in 30s
+	"4006":  ErrorRetry, // Close: abnormal disconnection
+
+	// Rate limits: a fresh connection would only add to the request count
+	"60014": ErrorBackoff, // Requests too frequent
+}
+
+// errorDescriptions say what known OKX error and close codes mean, for
+// messages where OKX's own text is terse or missing
+var errorDescriptions = map[string]string{
+	"50001": "service temporarily unavailable",
+	"50011": "rate limit reached",
+	"50110": "IP not in the API key's whitelist",
+	"50114": "invalid authority",
+	"50120": "API key doesn't have permission",
+	"60001": "API key is empty",
+	"60002": "signature is empty",
+	"60003": "passphrase is empty",
+	"60004": "invalid timestamp",
+	"60005": "invalid API key",
+	"60006": "timestamp expired, check the system clock",
+	"60007": "invalid signature",
+	"60008": "channel not supported on this endpoint",
+	"60009": "login failed",
+	"60011": "not logged in",
+	"60012": "illegal request",
+	"60013": "invalid arguments",
+	"60014": "requests too frequent",
+	"60018": "wrong URL or channel doesn't exist",
+	"60019": "invalid operation",
+	"60023": "login requests too frequent",
+	"60024": "wrong passphrase",
+	"60032": "API key doesn't exist",
+	"63999": "login failed due to an internal error",
+	"64008": "connection closing for a service upgrade",
+	"4001":  "login failed",
+	"4004":  "no data received in 30s",
+	"4006":  "abnormal disconnection",
+	"4007":  "API key was updated or deleted",
+}
+
+// describeError formats an OKX error for display with its code, what the code
+// means when known, OKX's own message and any setup hint, e.g.
+// "OKX error 60018 (wrong URL or channel doesn't exist): ..."
+func describeError(code, msg string) string {
+	text := "OKX error"
+	if code != "" {
+		text += " " + code
+	}
+	if desc, ok := errorDescriptions[code]; ok {
+		text += " (" + desc + ")"
+	}
+	if msg != "" {
+		text += ": " + msg
+	}
+	return withHint(code, text)
+}
+
+// errorHints are actionable guidance for OKX errors caused by a common setup
+// pitfall, shown after the error itself
+var errorHints = map[string]string{
+	"50110": "IP not whitelisted: add this machine's public IP to the API key's IP whitelist on OKX, or remove the whitelist",
+	"50114": "API key lacks Read permission: enable Read for the key in OKX API management",
+	"50120": "API key lacks Read permission: enable Read for the key in OKX API management",
+}
+
+// withHint appends the guidance for an OKX error code to a message about it,
+// if the code has any
+func withHint(code, msg string) string {
+	if hint, ok := errorHints[code]; ok {
+		return msg + " (" + hint + ")"
+	}
+	return msg
+}
+
+// errorActionNames maps the names used in overrides to actions
+var errorActionNames = map[string]ErrorAction{
+	"retry":   ErrorRetry,
+	"fatal":   ErrorFatal,
+	"backoff": ErrorBackoff,
+}
+
+// ParseErrorActions parses comma-separated code=action overrides such as
+// "60014=fatal,4001=retry", where action is retry, fatal or backoff
+func ParseErrorActions(value string) (map[string]ErrorAction, error) {
+	actions := make(map[string]ErrorAction)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		code, name, ok := strings.Cut(entry, "=")
+		code = strings.TrimSpace(code)
+		if !ok {
+			return nil, fmt.Errorf("invalid error code override %q, want code=retry, code=fatal or code=backoff", entry)
+		}
+		if _, err := strconv.Atoi(code); err != nil {
+			return nil, fmt.Errorf("invalid error code %q in %q", code, entry)
+		}
+		action, ok := errorActionNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("invalid action %q for code %s, want retry, fatal or backoff", name, code)
+		}
+		actions[code] = action
+	}
+	return actions, nil
+}
+
+// SetErrorActions overrides the reconnect handling of specific OKX error and
+// close codes, on top of the built-in classification
+func (c *OKXClient) SetErrorActions(overrides map[string]ErrorAction) {
+	c.errorActions = overrides
+}
+
+// errorAction classifies an OKX error or close code, overrides first
+func (c *OKXClient) errorAction(code string) ErrorAction {
+	if action, ok := c.errorActions[code]; ok {
+		return action
+	}
+	return defaultErrorActions[code]
+}
+
+// stopOnError records an OKX error that retrying can't fix as the reason to
+// stop reconnecting, reporting whether it did
+func (c *OKXClient) stopOnError(code, msg string) bool {
+	if code == "" || c.errorAction(code) != ErrorFatal {
+		return false
+	}
+	c.fatalErr = describeError(code, msg)
+	return true
+}
+
+// reconnectOnError reports whether an OKX error event on a live connection is
+// a transient condition a fresh connection clears, e.g. a service upgrade: a
+// code classified as retry, by override or by default
+func (c *OKXClient) reconnectOnError(code string) bool {
+	action, ok := c.errorActions[code]
+	if !ok {
+		action, ok = defaultErrorActions[code]
+	}
+	return ok && action == ErrorRetry
+}
+
+// backOffOnError reports whether an OKX error event on a live connection is a
+// rate limit to wait out on the same connection, and if so schedules resending
+// the subscriptions OKX has not acknowledged
+func (c *OKXClient) backOffOnError(code string) bool {
+	if code == "" || c.errorAction(code) != ErrorBackoff {
+		return false
+	}
+	c.requeueSubscriptions()
+	return true
+}
+
+// closeCode extracts the WebSocket close code from a read error
+func closeCode(err error) (string, string, bool) {
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return "", "", false
+	}
+	return strconv.Itoa(closeErr.Code), closeErr.Text, true
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          string
+		overrides     map[string]ErrorAction
+		wantStop      bool
+		wantReconnect bool
+	}{
+		{"retryable close code", "4004", nil, false, true},
+		{"retryable error event", "50001", nil, false, true},
+		{"fatal login error", "60005", nil, true, false},
+		{"fatal close code", "4001", nil, true, false},
+		{"unknown code is only reported", "60012", nil, false, false},
+		{"rate limit backs off without reconnecting", "60014", nil, false, false},
+		{"override makes a retry fatal", "60014", map[string]ErrorAction{"60014": ErrorFatal}, true, false},
+		{"override makes a fatal retry", "4001", map[string]ErrorAction{"4001": ErrorRetry}, false, true},
+		{"override adds an unknown code", "60012", map[string]ErrorAction{"60012": ErrorRetry}, false, true},
+		{"no code", "", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, _, _ := newTestDemoClient()
+			c.SetErrorActions(tt.overrides)
+			if got := c.stopOnError(tt.code, "msg"); got != tt.wantStop {
+				t.Errorf("stopOnError(%q) = %v, want %v", tt.code, got, tt.wantStop)
+			}
+			if got := c.reconnectOnError(tt.code); got != tt.wantReconnect {
+				t.Errorf("reconnectOnError(%q) = %v, want %v", tt.code, got, tt.wantReconnect)
+			}
+			if tt.wantStop && !strings.Contains(c.fatalErr, "OKX error "+tt.code) {
+				t.Errorf("fatal reason = %q, want it to name %s", c.fatalErr, tt.code)
+			}
+			if !tt.wantStop && c.fatalErr != "" {
+				t.Errorf("fatal reason %q recorded for a non-fatal code", c.fatalErr)
+			}
+		})
+	}
+}
+
+func TestPermissionErrorsStopWithGuidance(t *testing.T) {
+	tests := []struct {
+		code     string
+		wantHint string
+	}{
+		{"50110", "IP not whitelisted"},
+		{"50114", "API key lacks Read permission"},
+		{"50120", "API key lacks Read permission"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			c, _, _, _ := newTestDemoClient()
+			if !c.stopOnError(tt.code, "msg") || c.reconnectOnError(tt.code) {
+				t.Errorf("%s doesn't stop reconnecting, retrying can't fix it", tt.code)
+			}
+			if !strings.Contains(c.fatalErr, "OKX error "+tt.code) || !strings.Contains(c.fatalErr, tt.wantHint) {
+				t.Errorf("fatal reason = %q, want %s with %q", c.fatalErr, tt.code, tt.wantHint)
+			}
+
+			// Signed REST fetches carry the same guidance
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"code":"` + tt.code + `","msg":"denied","data":[]}`))
+			}))
+			defer server.Close()
+			c.restURL = server.URL
+			if _, err := c.signedGet(balancePath); err == nil || !strings.Contains(err.Error(), tt.wantHint) {
+				t.Errorf("REST error = %v, want %q", err, tt.wantHint)
+			}
+
+			// An override keeps retrying, e.g. while the whitelist is updated
+			c, _, _, _ = newTestDemoClient()
+			c.SetErrorActions(map[string]ErrorAction{tt.code: ErrorRetry})
+			if c.stopOnError(tt.code, "msg") || !c.reconnectOnError(tt.code) {
+				t.Errorf("%s=retry override doesn't retry", tt.code)
+			}
+		})
+	}
+
+	if got := describeError("60012", "bad request"); strings.Contains(got, "permission") || strings.Contains(got, "whitelist") {
+		t.Errorf("unrelated error %q carries a setup hint", got)
+	}
+}
+
+func TestParseErrorActions(t *testing.T) {
+	actions, err := ParseErrorActions(" 60014=fatal, 4001=RETRY ,50011=backoff")
+	if err != nil || len(actions) != 3 || actions["60014"] != ErrorFatal || actions["4001"] != ErrorRetry || actions["50011"] != ErrorBackoff {
+		t.Fatalf("ParseErrorActions() = %v, %v", actions, err)
+	}
+	for _, value := range []string{"60014", "abc=fatal", "60014=maybe"} {
+		if _, err := ParseErrorActions(value); err == nil {
+			t.Errorf("ParseErrorActions(%q) accepted an invalid override", value)
+		}
+	}
+}
+
+// closingServer accepts WebSocket connections and closes the main one at once
+// with the given close code, counting how often the client connected
+func closingServer(t *testing.T, code int) (string, *atomic.Int32) {
+	t.Helper()
+	var connects atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if r.URL.Path == "/public" {
+			connects.Add(1)
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, "bye"))
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http"), &connects
+}
+
+func TestRunWithReconnectFollowsCloseCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		wantFatal bool
+	}{
+		{"retryable close reconnects", 4004, false},
+		{"fatal close stops", 4001, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, connects := closingServer(t, tt.code)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			positionCh := make(chan PositionData, 100)
+			balanceCh := make(chan BalanceData, 100)
+			errorCh := make(chan string, 100)
+			statusCh := make(chan ConnState, 100)
+			go drainClient(ctx, positionCh, balanceCh, errorCh, make(chan PositionData))
+
+			c := NewOKXClientWithContext(ctx, positionCh, balanceCh, errorCh)
+			c.SetEndpoints(base+"/public", base+"/private", base+"/ticker")
+			c.SetReconnectPolicy(10*time.Millisecond, 10*time.Millisecond)
+			c.SetStatusChannel(statusCh)
+
+			done := make(chan struct{})
+			go func() {
+				c.RunWithReconnect()
+				close(done)
+			}()
+
+			if tt.wantFatal {
+				select {
+				case <-done:
+				case <-time.After(2 * time.Second):
+					t.Fatal("still reconnecting after a fatal close code")
+				}
+				if n := connects.Load(); n != 1 {
+					t.Errorf("connected %d times, want once", n)
+				}
+				if last := lastStatus(statusCh); last != ConnStopped {
+					t.Errorf("last status = %v, want stopped", last)
+				}
+				if !strings.Contains(c.fatalErr, "4001") {
+					t.Errorf("fatal reason = %q, want the close code", c.fatalErr)
+				}
+				return
+			}
+
+			deadline := time.Now().Add(2 * time.Second)
+			for connects.Load() < 3 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if n := connects.Load(); n < 3 {
+				t.Fatalf("connected %d times, want repeated reconnects", n)
+			}
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("did not stop on cancel")
+			}
+		})
+	}
+}
+
+// lastStatus returns the last connection state sent so far
+func lastStatus(statusCh <-chan ConnState) ConnState {
+	var last ConnState
+	for len(statusCh) > 0 {
+		last = <-statusCh
+	}
+	return last
+}
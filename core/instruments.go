@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRESTURL is the OKX REST API base URL used for instrument metadata
+// and the balance fallback
+const DefaultRESTURL = "https://www.okx.com"
+
+// instrumentsTimeout bounds each instrument metadata request
+const instrumentsTimeout = 10 * time.Second
+
+// instrumentInfo is the instrument metadata the monitor uses
+type instrumentInfo struct {
+	InstID string `json:"instId"`
+	Lever  string `json:"lever"` // Maximum leverage, empty for instruments without leverage
+}
+
+// fetchInstruments returns the live instruments of the given instrument types
+// (e.g. SWAP, FUTURES) from the public instruments endpoint, requested through
+// client (nil for the default client) with each request bounded by
+// instrumentsTimeout
+func fetchInstruments(client *http.Client, baseURL string, instTypes ...string) ([]instrumentInfo, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var instruments []instrumentInfo
+
+	for _, instType := range instTypes {
+		endpoint := strings.TrimSuffix(baseURL, "/") + "/api/v5/public/instruments?instType=" + url.QueryEscape(instType)
+		ctx, cancel := context.WithTimeout(context.Background(), instrumentsTimeout)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("fetch %s instruments: %v", instType, err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("fetch %s instruments: %v", instType, err)
+		}
+
+		var body struct {
+			Code string           `json:"code"`
+			Msg  string           `json:"msg"`
+			Data []instrumentInfo `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("decode %s instruments: %v", instType, err)
+		}
+		if body.Code != "0" {
+			return nil, fmt.Errorf("fetch %s instruments: %s", instType, describeError(body.Code, body.Msg))
+		}
+
+		instruments = append(instruments, body.Data...)
+	}
+	return instruments, nil
+}
+
+// FetchInstrumentIDs returns the live instrument IDs of the given instrument
+// types (e.g. SWAP, FUTURES) from the public instruments endpoint, requested
+// through client, e.g. one from NewHTTPClient for the proxy; nil uses the default
+func FetchInstrumentIDs(client *http.Client, baseURL string, instTypes ...string) (map[string]bool, error) {
+	instruments, err := fetchInstruments(client, baseURL, instTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, inst := range instruments {
+		known[inst.InstID] = true
+	}
+	return known, nil
+}
+
+// FetchMaxLeverage returns the exchange's maximum leverage per instrument of
+// the given instrument types, requested through client like FetchInstrumentIDs.
+// Instruments OKX lists without one are left out.
+func FetchMaxLeverage(client *http.Client, baseURL string, instTypes ...string) (map[string]float64, error) {
+	instruments, err := fetchInstruments(client, baseURL, instTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLever := make(map[string]float64)
+	for _, inst := range instruments {
+		if lever, err := strconv.ParseFloat(inst.Lever, 64); err == nil && lever > 0 {
+			maxLever[inst.InstID] = lever
+		}
+	}
+	return maxLever, nil
+}
+
+// UnknownInstruments returns the instrument IDs that are not in known, and the
+// wildcard patterns (like "*-USDT-SWAP") that match none of them
+func UnknownInstruments(known map[string]bool, instIds []string) []string {
+	var unknown []string
+	for _, instId := range instIds {
+		if !strings.ContainsAny(instId, "*?[") {
+			if !known[instId] {
+				unknown = append(unknown, instId)
+			}
+			continue
+		}
+
+		matched := false
+		for candidate := range known {
+			if ok, _ := path.Match(instId, candidate); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unknown = append(unknown, instId)
+		}
+	}
+	return unknown
+}
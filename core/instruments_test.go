@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchMaxLeverageGoesThroughProxy(t *testing.T) {
+	// A plain http proxy receives the absolute request URL
+	var requested []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.String())
+		fmt.Fprintf(w, `{"code":"0","data":[{"instId":"%s-USDT-SWAP","lever":"50"},{"instId":"%s-SPOT","lever":""}]}`,
+			r.URL.Query().Get("instType"), r.URL.Query().Get("instType"))
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	levers, err := FetchMaxLeverage(client, "http://okx.invalid", "SWAP", "FUTURES")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"http://okx.invalid/api/v5/public/instruments?instType=SWAP",
+		"http://okx.invalid/api/v5/public/instruments?instType=FUTURES",
+	}
+	if fmt.Sprint(requested) != fmt.Sprint(want) {
+		t.Errorf("proxy saw %v, want %v", requested, want)
+	}
+	if len(levers) != 2 || levers["SWAP-USDT-SWAP"] != 50 || levers["FUTURES-USDT-SWAP"] != 50 {
+		t.Errorf("max leverage = %v, want 50x for both instruments with one", levers)
+	}
+}
+
+func TestNewHTTPClientRejectsBadProxy(t *testing.T) {
+	if _, err := NewHTTPClient("https://proxy:443"); err == nil {
+		t.Error("https proxy accepted")
+	}
+	client, err := NewHTTPClient("")
+	if err != nil || client.Transport != nil {
+		t.Errorf("empty proxy = %v, %v; want the default transport", client.Transport, err)
+	}
+}
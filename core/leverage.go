@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultLeverage is assumed for positions OKX reports without leverage when
+// no override covers the instrument
+const defaultLeverage = 1.0
+
+// ParseLeverageOverrides parses per-instrument leverage as instId=leverage
+// pairs, e.g. BTC-USDT-SWAP=20,ETH-USDT-SWAP=10
+func ParseLeverageOverrides(value string) (map[string]float64, error) {
+	overrides := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		instId, lever, ok := strings.Cut(entry, "=")
+		instId = strings.ToUpper(strings.TrimSpace(instId))
+		if !ok || instId == "" {
+			return nil, fmt.Errorf("invalid leverage override %q, want instId=leverage", entry)
+		}
+		leverage, err := strconv.ParseFloat(strings.TrimSpace(lever), 64)
+		if err != nil || leverage <= 0 {
+			return nil, fmt.Errorf("invalid leverage %q for %s, want a positive number", lever, instId)
+		}
+		overrides[instId] = leverage
+	}
+	return overrides, nil
+}
+
+// SetLeverageOverrides sets the leverage assumed per instrument where OKX
+// reports none, in place of the default 1x. Demo positions without a
+// leverage of their own use it in place of the demo default.
+func (c *OKXClient) SetLeverageOverrides(overrides map[string]float64) {
+	c.leverageOverrides = overrides
+}
+
+// assumedLeverage returns the leverage to assume for an instrument reported
+// without one: its override, or fallback without one
+func (c *OKXClient) assumedLeverage(instId string, fallback float64) float64 {
+	if leverage, ok := c.leverageOverrides[instId]; ok {
+		return leverage
+	}
+	return fallback
+}
+
+// estimatePnLRatio returns unrealized PnL as a percentage of the margin at the
+// position's leverage, like OKX's uplRatio, for positions reported without one
+func estimatePnLRatio(pos PositionData) float64 {
+	leverage := pos.Leverage
+	if leverage <= 0 {
+		leverage = defaultLeverage
+	}
+	return pos.PnL / (pos.AvgPrice * math.Abs(pos.Size)) * leverage * 100
+}
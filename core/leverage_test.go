@@ -0,0 +1,79 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLeverageOverrides(t *testing.T) {
+	got, err := ParseLeverageOverrides(" btc-usdt-swap=20, ETH-USDT-SWAP = 2.5 ,")
+	want := map[string]float64{"BTC-USDT-SWAP": 20, "ETH-USDT-SWAP": 2.5}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLeverageOverrides() = %v, %v; want %v", got, err, want)
+	}
+
+	for _, value := range []string{"BTC-USDT-SWAP", "=10", "BTC-USDT-SWAP=0", "BTC-USDT-SWAP=-5", "BTC-USDT-SWAP=high"} {
+		if _, err := ParseLeverageOverrides(value); err == nil {
+			t.Errorf("ParseLeverageOverrides(%q) accepted", value)
+		}
+	}
+}
+
+func TestReportedLeverageOverridesAssumed(t *testing.T) {
+	tests := []struct {
+		name        string
+		lever       interface{} // nil leaves lever out of the frame
+		instId      string
+		want        float64
+		wantAssumed bool
+		wantRatio   float64 // Estimated at the leverage: 100 PnL on 5000 notional
+	}{
+		{"reported wins over the override", "5", "BTC-USDT-SWAP", 5, false, 10},
+		{"override when not reported", nil, "BTC-USDT-SWAP", 20, true, 40},
+		{"override when reported empty", "", "BTC-USDT-SWAP", 20, true, 40},
+		{"override when reported 0", "0", "BTC-USDT-SWAP", 20, true, 40},
+		{"1x without an override", nil, "ETH-USDT-SWAP", 1, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, _, _ := newTestDemoClient()
+			c.isDemo = false
+			c.SetLeverageOverrides(map[string]float64{"BTC-USDT-SWAP": 20})
+
+			data := map[string]interface{}{
+				"instId": tt.instId, "posSide": "long", "pos": "1", "avgPx": "5000", "markPx": "5100", "upl": "100",
+			}
+			if tt.lever != nil {
+				data["lever"] = tt.lever
+			}
+			pos := c.parsePositionData(data)
+
+			if pos.Leverage != tt.want || pos.LeverageAssumed != tt.wantAssumed {
+				t.Errorf("leverage = %v (assumed %v), want %v (assumed %v)", pos.Leverage, pos.LeverageAssumed, tt.want, tt.wantAssumed)
+			}
+			if !pos.PnLRatioEstimated || !approxEqual(pos.PnLRatio, tt.wantRatio) {
+				t.Errorf("PnL ratio = %v (estimated %v), want an estimated %v", pos.PnLRatio, pos.PnLRatioEstimated, tt.wantRatio)
+			}
+		})
+	}
+}
+
+func TestDemoLeveragePrecedence(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	c.SetLeverageOverrides(map[string]float64{"BTC-USDT-SWAP": 20, "ETH-USDT-SWAP": 20})
+	c.SetDemoInstruments([]DemoPosition{
+		{"BTC-USDT-SWAP", 50000, 1, "long", 5}, // Its own leverage wins
+		{"ETH-USDT-SWAP", 3000, 1, "long", 0},  // Then the override
+		{"SOL-USDT-SWAP", 150, 1, "long", 0},   // Then the demo default
+	})
+
+	c.createDemoPositions()
+
+	want := map[string]float64{"BTC-USDT-SWAP": 5, "ETH-USDT-SWAP": 20, "SOL-USDT-SWAP": defaultDemoLeverage}
+	for instId, leverage := range want {
+		if got := c.demoPositions[instId].Leverage; got != leverage {
+			t.Errorf("%s demo leverage = %v, want %v", instId, got, leverage)
+		}
+	}
+}
@@ -1,19 +1,32 @@
 package core
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// Default OKX WebSocket endpoints
+const (
+	DefaultPublicURL  = "wss://ws.okx.com:8443/ws/v5/public"
+	DefaultPrivateURL = "wss://ws.okx.com:8443/ws/v5/private"
+	DefaultTickerURL  = "wss://wspri.okx.com:8443/ws/v5/ipublic"
+)
+
 // PositionData represents a trading position
 type PositionData struct {
 	InstrumentID  string  `json:"instId"`
@@ -21,18 +34,60 @@ type PositionData struct {
 	Size          float64 `json:"pos,string"`
 	AvgPrice      float64 `json:"avgPx,string"`
 	CurrentPrice  float64 `json:"markPx,string"`
+	LastPrice     float64 `json:"last,string"`       // Last traded price, 0 when not streamed
+	PriceIsLast   bool    `json:"-"`                 // CurrentPrice is the last traded price, no mark price yet
 	PnL           float64 `json:"upl,string"`        // Use 'upl' for unrealized PnL
 	PnLRatio      float64 `json:"uplRatio,string"`   // Use 'uplRatio' for unrealized PnL ratio
 	Leverage      float64 `json:"lever,string"`
-	Timestamp     int64   `json:"ts,string"`
+	Margin        float64 `json:"imr,string"`        // Initial margin committed to the position
+	MarginEstimated bool  `json:"-"`                 // Margin derived from notional/leverage
+	LeverageAssumed bool  `json:"-"`                 // Leverage not reported, taken from an override or the 1x default
+	PnLRatioEstimated bool `json:"-"`                // PnL ratio calculated from PnL, entry and leverage
+	MarginRatio   float64 `json:"mgnRatio,string"`   // Margin ratio in percent, 0 when not reported
+	RealizedPnL   float64 `json:"realizedPnl,string"` // Realized PnL of the position so far
+	LiqPrice      float64 `json:"liqPx,string"`      // Estimated liquidation price, 0 when not reported
+	MarginCurrency string `json:"mgnCcy"`            // Margin currency, "" when not reported
+	Timestamp     int64   `json:"ts,string"`         // Exchange time (epoch ms), local time if OKX sent none
+	ReceivedAt    int64   `json:"-"`                 // Local receipt time (epoch ms)
+	Account       string  `json:"account,omitempty"` // Label of the account the position belongs to, "" with a single account
 }
 
+// IsShort reports whether the position profits when the price falls. Net-mode
+// positions are short when their size is negative.
+func (p PositionData) IsShort() bool {
+	return p.PositionSide == "short" || (p.PositionSide == "net" && p.Size < 0)
+}
+
+// SettleCurrency returns the currency the position's PnL and margin are
+// denominated in, derived from the instrument ID. USD-quoted contracts such as
+// BTC-USD-SWAP are coin-margined and settle in the base coin.
+func (p PositionData) SettleCurrency() string {
+	parts := strings.Split(p.InstrumentID, "-")
+	if len(parts) < 2 {
+		return ""
+	}
+	if parts[1] == "USD" {
+		return parts[0]
+	}
+	return parts[1]
+}
+
+// knownPositionSides are the posSide values OKX documents
+var knownPositionSides = map[string]bool{"long": true, "short": true, "net": true}
+
 // BalanceData represents account balance information
 type BalanceData struct {
 	Currency      string  `json:"ccy"`
 	TotalEquity   float64 `json:"totalEq,string"`
 	AvailBalance  float64 `json:"availBal,string"`
+	MarginRatio   float64 `json:"mgnRatio,string"` // Account margin ratio in percent, 0 when not reported
+	AdjustedEquity float64 `json:"adjEq,string"`  // Equity adjusted for discounts in margin modes, 0 when not reported
+	OrderFrozen   float64 `json:"ordFroz,string"` // Margin frozen by open orders in USD, 0 when not reported
+	Borrows       []BorrowData `json:"-"`          // Outstanding margin borrowing per currency, nil without borrowing
+	AutoLoan      bool    `json:"autoLoan"`        // Auto-borrow enabled, meaningful only when AutoLoanReported
+	AutoLoanReported bool `json:"-"`               // OKX included autoLoan in the account update
 	Timestamp     int64   `json:"ts,string"`
+	Account       string  `json:"account,omitempty"` // Label of the account, "" with a single account
 }
 
 // TickerData represents ticker information
@@ -52,25 +107,113 @@ type OKXClient struct {
 	positionCh   chan<- PositionData
 	balanceCh    chan<- BalanceData
 	errorCh      chan<- string
+	tradeCh      chan<- TradeData   // Optional public trades feed, nil when disabled
+	bookCh       chan<- BookData    // Order book snapshots for the selected instrument
+	tickerCh     chan<- TickerData  // Bid, ask and 24h volume per instrument, nil when not wanted
+	backpressure *Backpressure      // Full-channel policies and metrics shared across clients, nil blocks uncounted
+	proxy        *url.URL           // Proxy for connections and REST requests, nil uses the environment's
+	restTransport *http.Transport  // REST transport through proxy, nil for the default
+	bookInstrument string           // Instrument currently subscribed to books5
 	apiKey       string
 	secretKey    string
 	passphrase   string
+	publicURL    string             // Public WebSocket endpoint used in demo mode
+	privateURL   string             // Private WebSocket endpoint used with credentials
+	tickerURL    string             // Public WebSocket endpoint used for ticker data
 	currentPositions map[string]bool // Track current positions for ticker subscription
+	openSides    map[string]map[string]bool // Open position sides per tracked instrument
+	positionsMutex sync.Mutex         // Guards currentPositions and openSides against the pause watcher
 	isDemo       bool               // Track if running in demo mode
 	demoPositions map[string]PositionData // Store demo positions
+	demoRandom   bool               // Randomize the initial demo positions
+	demoSeed     int64              // Seed for demo randomization
+	demoEntryOffsets map[string]float64 // Pending entry offsets from the first ticker price
+	demoCount    int                // Number of demo positions, 0 uses the built-in set
+	demoEquity   float64            // Demo account equity before demo PnL
+	demoFollowers map[string][]string // Synthetic demo instruments following a real instrument's price
+	demoTemplates []DemoPosition      // Configured demo positions, nil for the built-in set
+	demoMutex    sync.Mutex         // Guards demoPositions, demoEntryOffsets and demoFollowers
 	connMutex    sync.Mutex         // Protect main WebSocket writes
 	tickerMutex  sync.Mutex         // Protect ticker WebSocket writes
+	bookMutex    sync.Mutex         // Protect the selected order book instrument
+	mainHandlers map[string]ChannelHandler   // Channel handlers for the main connection
+	tickerHandlers map[string]ChannelHandler // Channel handlers for the ticker connection
+	handlersMutex sync.RWMutex               // Protect channel handler registration
+	markPriceFeed bool                       // Price updates from mark-price on the standard public endpoint
+	sharedPriceConn bool                     // Market data shares the main connection, no ticker socket
+	markPriceSeen map[string]bool            // Instruments streaming a mark price, their last price is ignored
+	markPriceMutex sync.Mutex                // Protect markPriceSeen across ticker listeners
+	statusCh     chan<- ConnState            // Optional connection state updates
+	debugLimit   debugLimiter                // Per-category debug message rate limit
+	tickerSubsPending atomic.Bool            // Positions changed while the price connection was down
+	paused       atomic.Bool                 // Subscriptions dropped by Pause, connections kept alive
+	errorActions map[string]ErrorAction      // Overrides of the retry/fatal classification of OKX codes
+	fatalErr     string                      // Error that ended the last connection for good, "" to retry
+	ctx          context.Context             // Cancelled to close the connections and stop every goroutine
+	simulatedTrading bool                    // Connect to OKX's demo-trading environment
+	subBatchSize int                         // Most channels per subscribe or unsubscribe request
+	subBatchDelay time.Duration              // Wait between batched subscribe requests
+	subAcksPending atomic.Int64              // Subscribed channels OKX has not acknowledged yet
+	rateLimitBackoff time.Duration           // Wait before resending subscriptions OKX rate limited
+	backingOff   atomic.Bool                 // Subscriptions are waiting out a rate limit
+	restURL      string                      // REST API base URL for the balance fallback and closed positions
+	balanceTimeout time.Duration             // Wait for the first account push before fetching it, 0 never fetches
+	accountSeen  atomic.Bool                 // An account push arrived since the last login
+	account      string                      // Label sent with every position and balance, "" with a single account
+	minReconnectDelay time.Duration          // First wait before reconnecting after a drop
+	maxReconnectDelay time.Duration          // Cap of the doubling reconnect wait
+	priceSubs    map[string]bool             // Instruments subscribed on the price connection
+	tickerThrottle time.Duration             // Interval coalesced ticker prices are flushed at, 0 sends each one
+	pendingTickers map[string]tickerUpdate   // Latest unflushed ticker price per instrument
+	pendingMutex sync.Mutex                  // Guards pendingTickers
+	flusherStarted atomic.Bool               // The ticker flusher is running
+	priceSubsMutex sync.Mutex                // Guards priceSubs across a subscription update
+	closedCh     chan<- ClosedHistory        // Recently closed positions, nil disables polling them
+	closedLookback time.Duration             // How far back closed positions are listed
+	closedPolling atomic.Bool                // Closed positions are being polled, started on the first login
+	leverageOverrides map[string]float64     // Leverage assumed per instrument where none is reported
+	fxInstruments []string                   // Tickers kept subscribed for live FX rates, held or not
+	panicHandler PanicHandler                // Receives panics on the client's goroutines, nil leaves them fatal
 }
 
-// NewOKXClient creates a new OKX WebSocket client
+// NewOKXClient creates a new OKX WebSocket client that runs until the process exits
 func NewOKXClient(positionCh chan<- PositionData, balanceCh chan<- BalanceData, errorCh chan<- string) *OKXClient {
-	return &OKXClient{
+	return NewOKXClientWithContext(context.Background(), positionCh, balanceCh, errorCh)
+}
+
+// NewOKXClientWithContext creates a new OKX WebSocket client that shuts down
+// when ctx is cancelled: both connections close, the heartbeats, listeners and
+// request watchers stop, and RunWithReconnect returns. Sends to the client's
+// channels may still block until they are read, so callers should keep
+// draining them until RunWithReconnect has returned.
+func NewOKXClientWithContext(ctx context.Context, positionCh chan<- PositionData, balanceCh chan<- BalanceData, errorCh chan<- string) *OKXClient {
+	c := &OKXClient{
+		ctx:              ctx,
 		positionCh:       positionCh,
 		balanceCh:        balanceCh,
 		errorCh:          errorCh,
+		publicURL:        DefaultPublicURL,
+		privateURL:       DefaultPrivateURL,
+		tickerURL:        DefaultTickerURL,
 		currentPositions: make(map[string]bool),
+		openSides:        make(map[string]map[string]bool),
+		priceSubs:        make(map[string]bool),
+		tickerThrottle:   defaultTickerThrottle,
+		markPriceSeen:    make(map[string]bool),
 		demoPositions:    make(map[string]PositionData),
+		demoEntryOffsets: make(map[string]float64),
+		demoFollowers:    make(map[string][]string),
+		demoEquity:       defaultDemoEquity,
+		subBatchSize:     defaultSubscribeBatchSize,
+		subBatchDelay:    defaultSubscribeBatchDelay,
+		rateLimitBackoff: defaultRateLimitBackoff,
+		restURL:          DefaultRESTURL,
+		balanceTimeout:   defaultBalanceTimeout,
+		minReconnectDelay: defaultMinReconnectDelay,
+		maxReconnectDelay: defaultMaxReconnectDelay,
 	}
+	c.registerDefaultHandlers()
+	return c
 }
 
 // SetCredentials sets the API credentials
@@ -80,30 +223,59 @@ func (c *OKXClient) SetCredentials(apiKey, secretKey, passphrase string) {
 	c.passphrase = passphrase
 }
 
+// SetEndpoints overrides the WebSocket endpoints, empty values keep the current endpoint
+func (c *OKXClient) SetEndpoints(publicURL, privateURL, tickerURL string) {
+	if publicURL != "" {
+		c.publicURL = publicURL
+	}
+	if privateURL != "" {
+		c.privateURL = privateURL
+	}
+	if tickerURL != "" {
+		c.tickerURL = tickerURL
+	}
+}
+
 // Connect establishes WebSocket connection to OKX
 func (c *OKXClient) Connect() error {
-	// Always establish public WebSocket connection for ticker data
-	if err := c.connectTickerWebSocket(); err != nil {
-		c.errorCh <- fmt.Sprintf("Failed to connect to ticker WebSocket: %v", err)
-		// Continue without ticker connection - not critical
+	demo := c.apiKey == "" || c.secretKey == "" || c.passphrase == ""
+
+	// The price connection is new, with nothing subscribed on it yet
+	c.resetPriceSubscriptions()
+
+	if c.markPriceFeed && demo {
+		// The main connection is already public, so it carries the prices too
+		c.shareMainConnForPrices()
+		c.sendError("DEBUG: Mark-price feed on the main public WebSocket, no ticker socket")
+	} else {
+		if c.markPriceFeed {
+			// The private endpoint has no market data, use the standard public one
+			c.tickerURL = c.publicURL
+		}
+
+		// Establish public WebSocket connection for ticker data
+		if err := c.connectTickerWebSocket(); err != nil {
+			c.sendError(fmt.Sprintf("Failed to connect to ticker WebSocket: %v", err))
+			// Continue without ticker connection - not critical
+		}
 	}
 
 	// Use public endpoint for demo/testing without credentials
 	var wsURL string
-	if c.apiKey == "" || c.secretKey == "" || c.passphrase == "" {
+	if demo {
 		// Set demo mode flag
 		c.isDemo = true
 		
 		// Public WebSocket for demo data
-		wsURL = "wss://ws.okx.com:8443/ws/v5/public"
-		c.errorCh <- "Connecting to OKX public WebSocket"
+		wsURL = c.publicURL
+		c.sendError("Connecting to OKX public WebSocket")
 		
 		// Create demo positions for display
 		c.createDemoPositions()
 	} else {
 		// Private WebSocket for real trading data
-		wsURL = "wss://ws.okx.com:8443/ws/v5/private"
-		c.errorCh <- "Connecting to OKX private WebSocket"
+		wsURL = c.privateURL
+		c.sendError("Connecting to OKX private WebSocket")
 	}
 
 	u, err := url.Parse(wsURL)
@@ -111,12 +283,17 @@ func (c *OKXClient) Connect() error {
 		return fmt.Errorf("invalid WebSocket URL: %v", err)
 	}
 	
-	c.conn, _, err = websocket.DefaultDialer.Dial(u.String(), nil)
+	conn, _, err := c.dialer().DialContext(c.ctx, u.String(), c.handshakeHeader())
 	if err != nil {
 		return fmt.Errorf("failed to connect to OKX WebSocket: %v", err)
 	}
 
-	c.errorCh <- "WebSocket connection established"
+	// Pause and resume may read the connection from other goroutines
+	c.connMutex.Lock()
+	c.conn = conn
+	c.connMutex.Unlock()
+
+	c.sendError("WebSocket connection established")
 
 	// If we have credentials, authenticate first, then subscribe
 	if c.apiKey != "" && c.secretKey != "" && c.passphrase != "" {
@@ -139,45 +316,62 @@ func (c *OKXClient) Connect() error {
 // connectTickerWebSocket establishes a separate WebSocket connection for ticker data
 func (c *OKXClient) connectTickerWebSocket() error {
 	// Use the public WebSocket endpoint for ticker data as specified in requirements
-	wsURL := "wss://wspri.okx.com:8443/ws/v5/ipublic"
+	wsURL := c.tickerURL
 	
 	u, err := url.Parse(wsURL)
 	if err != nil {
 		return fmt.Errorf("invalid ticker WebSocket URL: %v", err)
 	}
 	
-	c.tickerConn, _, err = websocket.DefaultDialer.Dial(u.String(), nil)
+	conn, _, err := c.dialer().DialContext(c.ctx, u.String(), c.handshakeHeader())
 	if err != nil {
 		return fmt.Errorf("failed to connect to ticker WebSocket: %v", err)
 	}
 
-	c.errorCh <- "DEBUG: Ticker WebSocket connection established"
+	// Subscription updates may read the connection from other goroutines
+	c.tickerMutex.Lock()
+	c.tickerConn = conn
+	c.tickerMutex.Unlock()
+
+	c.sendError("DEBUG: Ticker WebSocket connection established")
+
+	// Fall back to last prices until mark prices arrive on the new connection
+	c.resetMarkPrices()
 	
 	// Start ticker listener in a separate goroutine
-	go c.startTickerListener()
+	c.goSafe(func() { c.startTickerListener(conn) })
 	
 	return nil
 }
 
-// startTickerListener listens for ticker data on the separate connection
-func (c *OKXClient) startTickerListener() {
-	defer func() {
-		if c.tickerConn != nil {
-			c.tickerConn.Close()
-		}
-	}()
+// startTickerListener listens for ticker data on the separate connection. It
+// works on the connection it was started for, which a reconnect may replace.
+func (c *OKXClient) startTickerListener(conn *websocket.Conn) {
+	defer conn.Close()
+
+	// Stop the heartbeat and shutdown watcher along with the listener
+	done := make(chan struct{})
+	defer close(done)
 
 	// Start heartbeat for ticker connection
-	go c.tickerHeartbeat()
+	c.goSafe(func() { c.tickerHeartbeat(conn, done) })
+	c.goSafe(func() { c.closeOnCancel(conn, &c.tickerMutex, done) })
 
 	for {
-		if c.tickerConn == nil {
-			break
-		}
-		
-		_, message, err := c.tickerConn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
-			c.errorCh <- fmt.Sprintf("DEBUG: Ticker WebSocket read error: %v", err)
+			if c.stopping() {
+				return
+			}
+			c.sendError(fmt.Sprintf("DEBUG: Ticker WebSocket read error: %v", err))
+
+			// Forget the dead connection so subscription changes are queued
+			// instead of failing on it until the next reconnect
+			c.tickerMutex.Lock()
+			if c.tickerConn == conn {
+				c.tickerConn = nil
+			}
+			c.tickerMutex.Unlock()
 			return
 		}
 
@@ -189,44 +383,48 @@ func (c *OKXClient) startTickerListener() {
 		// Parse ticker message
 		var response map[string]interface{}
 		if err := json.Unmarshal(message, &response); err != nil {
-			c.errorCh <- fmt.Sprintf("DEBUG: Failed to parse ticker message: %v", err)
+			c.sendError(fmt.Sprintf("DEBUG: Failed to parse ticker message: %v", err))
+			continue
+		}
+
+		if event, ok := response["event"].(string); ok {
+			c.handleTickerEvent(event, response)
 			continue
 		}
 
-		// Handle ticker data
+		// Route ticker, trade and book data to the registered channel handlers
 		if data, ok := response["data"].([]interface{}); ok {
-			if arg, ok := response["arg"].(map[string]interface{}); ok {
-				if channel, ok := arg["channel"].(string); ok && channel == "tickers" {
-					c.errorCh <- fmt.Sprintf("DEBUG: Received %d ticker items", len(data))
-					for _, item := range data {
-						if tickerData, ok := item.(map[string]interface{}); ok {
-							c.handleTickerData(tickerData)
-						}
-					}
-				}
-			}
+			c.dispatchChannel(c.tickerHandlers, response, data)
 		}
 	}
 }
 
-// tickerHeartbeat sends ping messages to keep ticker connection alive
-func (c *OKXClient) tickerHeartbeat() {
+// tickerHeartbeat sends ping messages to keep ticker connection alive until
+// done is closed or the client shuts down
+func (c *OKXClient) tickerHeartbeat(conn *websocket.Conn, done <-chan struct{}) {
 	ticker := time.NewTicker(25 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-done:
+			return
+		case <-c.ctx.Done():
+			return
 		case <-ticker.C:
-			if c.tickerConn != nil {
-				// Protect ticker WebSocket writes with mutex
-				c.tickerMutex.Lock()
-				err := c.tickerConn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			// Protect ticker WebSocket writes with mutex, never writing once
+			// shutdown has closed the connection
+			c.tickerMutex.Lock()
+			if c.stopping() {
 				c.tickerMutex.Unlock()
-				
-				if err != nil {
-					c.errorCh <- fmt.Sprintf("DEBUG: Failed to send ticker ping: %v", err)
-					return
-				}
+				return
+			}
+			err := conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			c.tickerMutex.Unlock()
+
+			if err != nil {
+				c.sendError(fmt.Sprintf("DEBUG: Failed to send ticker ping: %v", err))
+				return
 			}
 		}
 	}
@@ -239,39 +437,75 @@ func (c *OKXClient) handleTickerData(data map[string]interface{}) {
 		return
 	}
 
-	// Parse last price
-	var lastPrice float64
-	if last, ok := data["last"].(string); ok {
-		fmt.Sscanf(last, "%f", &lastPrice)
+	// Prefer the mark price positions are valued at, the last traded price
+	// only stands in until the instrument's first mark price arrives. The last
+	// price is passed along on its own for showing next to the mark price.
+	var lastPrice, tradedPrice float64
+	priceIsLast := false
+	if markPx, ok := data["markPx"].(string); ok {
+		if c.parseNumber(instId, "markPx", markPx, &lastPrice) {
+			c.noteMarkPrice(instId)
+		}
+	} else if last, ok := data["last"].(string); ok {
+		if !c.parseNumber(instId, "last", last, &tradedPrice) {
+			return
+		}
+		if !c.hasMarkPrice(instId) {
+			lastPrice, priceIsLast = tradedPrice, true
+		}
 	}
 
-	c.errorCh <- fmt.Sprintf("DEBUG: Ticker update for %s: %.6f", instId, lastPrice)
+	c.instDebugf(instId, debugTicker, "Ticker update for %s: %.6f (last %.6f)", instId, lastPrice, tradedPrice)
+
+	// Bid, ask and volume ride along with the prices, throttled the same way
+	var stats *TickerData
+	if c.tickerCh != nil {
+		if ticker, ok := c.parseTickerStats(instId, data); ok {
+			stats = &ticker
+		}
+	}
+
+	receivedAt := nowMillis()
+	update := tickerUpdate{
+		instId:      instId,
+		price:       lastPrice,
+		last:        tradedPrice,
+		priceIsLast: priceIsLast,
+		stats:       stats,
+		exchangeTs:  exchangeTimestamp(data, receivedAt),
+		receivedAt:  receivedAt,
+	}
+
+	// Rapid updates are coalesced to the latest price per instrument when throttled
+	if c.tickerThrottle > 0 {
+		c.queueTicker(update)
+		return
+	}
+	c.applyTicker(update)
+}
+
+// applyTicker applies a ticker price: demo positions are marked at it and
+// resent with their PnL recalculated, real positions get a price-only update.
+// An update with only a last traded price leaves the mark price alone.
+func (c *OKXClient) applyTicker(update tickerUpdate) {
+	instId, lastPrice := update.instId, update.price
+	exchangeTs, receivedAt := update.exchangeTs, update.receivedAt
+	if update.stats != nil {
+		c.sendTickerStats(*update.stats)
+	}
 
 	// In demo mode, update demo positions with ticker data
 	if c.isDemo {
-		if demoPos, exists := c.demoPositions[instId]; exists {
-			// Update the current price and recalculate PnL
-			demoPos.CurrentPrice = lastPrice
-			
-			// Calculate PnL based on position side
-			if demoPos.PositionSide == "short" {
-				// For short positions, profit when price goes down
-				demoPos.PnL = (demoPos.AvgPrice - demoPos.CurrentPrice) * demoPos.Size
-			} else {
-				// For long positions, profit when price goes up
-				demoPos.PnL = (demoPos.CurrentPrice - demoPos.AvgPrice) * demoPos.Size
+		if updated, repriced, exists := c.updateDemoPrices(update); exists {
+			// Send updated demo positions to UI
+			for _, demoPos := range updated {
+				c.sendPosition(demoPos)
 			}
-			
-			if demoPos.AvgPrice > 0 {
-				demoPos.PnLRatio = (demoPos.PnL / (demoPos.AvgPrice * demoPos.Size)) * 100
+
+			// Keep the demo equity in step with the recalculated PnL
+			if repriced {
+				c.sendBalance(c.demoBalance())
 			}
-			demoPos.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
-			
-			// Update stored demo position
-			c.demoPositions[instId] = demoPos
-			
-			// Send updated demo position to UI
-			c.positionCh <- demoPos
 			return
 		}
 	}
@@ -281,145 +515,266 @@ func (c *OKXClient) handleTickerData(data map[string]interface{}) {
 	position := PositionData{
 		InstrumentID: instId,
 		CurrentPrice: lastPrice,
-		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+		LastPrice:    update.last,
+		PriceIsLast:  update.priceIsLast,
+		Timestamp:    exchangeTs,
+		ReceivedAt:   receivedAt,
 	}
 
 	// Send to position channel to update current price
-	c.positionCh <- position
+	c.sendPosition(position)
+}
+
+// recalcDemoPnL returns the demo position marked at lastPrice with PnL and ratio recalculated
+func recalcDemoPnL(pos PositionData, lastPrice float64) PositionData {
+	pos.CurrentPrice = lastPrice
+
+	// Calculate PnL based on position side
+	if pos.PositionSide == "short" {
+		// For short positions, profit when price goes down
+		pos.PnL = (pos.AvgPrice - pos.CurrentPrice) * pos.Size
+	} else {
+		// For long positions, profit when price goes up
+		pos.PnL = (pos.CurrentPrice - pos.AvgPrice) * pos.Size
+	}
+
+	if pos.AvgPrice > 0 && pos.Size > 0 {
+		pos.PnLRatio = estimatePnLRatio(pos)
+	}
+	pos.Margin = estimateMargin(pos)
+	pos.LiqPrice = estimateDemoLiqPrice(pos)
+
+	return pos
+}
+
+// estimateDemoLiqPrice approximates where a demo position would be liquidated:
+// the price at which the loss equals the initial margin, ignoring maintenance
+// margin and fees
+func estimateDemoLiqPrice(pos PositionData) float64 {
+	if pos.Leverage <= 0 || pos.AvgPrice <= 0 {
+		return 0
+	}
+	if pos.IsShort() {
+		return pos.AvgPrice * (1 + 1/pos.Leverage)
+	}
+	return pos.AvgPrice * (1 - 1/pos.Leverage)
+}
+
+// estimateMargin derives the margin committed to a position from notional/leverage
+func estimateMargin(pos PositionData) float64 {
+	if pos.Leverage <= 0 {
+		return 0
+	}
+	return pos.CurrentPrice * math.Abs(pos.Size) / pos.Leverage
 }
 
 // createDemoPositions creates demo trading positions for display in demo mode
 func (c *OKXClient) createDemoPositions() {
-	// Create demo positions for 10 different trading pairs
-	demoInstruments := []struct {
-		instId   string
-		avgPrice float64
-		size     float64
-		side     string
-	}{
-		{"BTC-USDT-SWAP", 45000.0, 0.1, "long"},
-		{"ETH-USDT-SWAP", 2800.0, 1.0, "long"},
-		{"SOL-USDT-SWAP", 178.0, 2.7, "short"},
-		{"ADA-USDT-SWAP", 0.45, 1000.0, "long"},
-		{"DOT-USDT-SWAP", 6.8, 50.0, "short"},
-		{"LINK-USDT-SWAP", 14.2, 25.0, "long"},
-		{"AVAX-USDT-SWAP", 28.5, 15.0, "short"},
-		{"MATIC-USDT-SWAP", 0.85, 500.0, "long"},
-		{"UNI-USDT-SWAP", 7.3, 40.0, "short"},
-		{"LTC-USDT-SWAP", 95.0, 3.0, "long"},
-	}
-
-	for _, demo := range demoInstruments {
+	// The ticker flusher updates the demo positions concurrently, so they are
+	// copied under the lock and sent after it is released
+	c.demoMutex.Lock()
+
+	// On reconnect, resend the existing demo positions instead of creating new ones
+	if len(c.demoPositions) > 0 {
+		restored := make([]PositionData, 0, len(c.demoPositions))
+		for _, position := range c.demoPositions {
+			restored = append(restored, position)
+		}
+		c.demoMutex.Unlock()
+		for _, position := range restored {
+			c.sendPosition(position)
+		}
+		c.sendError("DEBUG: Restored demo positions after reconnect")
+		return
+	}
+
+	var created []PositionData
+	for _, demo := range c.demoInstrumentSet() {
 		position := PositionData{
-			InstrumentID: demo.instId,
-			PositionSide: demo.side,
-			Size:         demo.size,
-			AvgPrice:     demo.avgPrice,
-			CurrentPrice: demo.avgPrice, // Will be updated by ticker data
+			InstrumentID: demo.InstID,
+			PositionSide: demo.Side,
+			Size:         demo.Size,
+			AvgPrice:     demo.AvgPrice,
+			CurrentPrice: demo.AvgPrice, // Will be updated by ticker data
 			PnL:          0.0,           // Will be calculated when ticker updates
 			PnLRatio:     0.0,           // Will be calculated when ticker updates
-			Leverage:     10.0,          // Demo leverage
+			Leverage:     demo.Leverage,
+			MarginEstimated: true,
+			PnLRatioEstimated: true,
 			Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
 		}
+		if position.Leverage <= 0 {
+			position.Leverage = c.assumedLeverage(demo.InstID, defaultDemoLeverage)
+		}
+		position.Margin = estimateMargin(position)
+		position.LiqPrice = estimateDemoLiqPrice(position)
 		
 		// Store demo position
-		c.demoPositions[demo.instId] = position
-		
+		c.demoPositions[demo.InstID] = position
+		created = append(created, position)
+	}
+	c.demoMutex.Unlock()
+
+	for _, position := range created {
 		// Send initial demo position to UI
-		c.positionCh <- position
+		c.sendPosition(position)
 		
-		c.errorCh <- fmt.Sprintf("DEBUG: Created demo position for %s", demo.instId)
-	}
-	
-	// Also create a demo balance
-	demoBalance := BalanceData{
-		Currency:     "USDT",
-		TotalEquity:  10000.0, // Demo balance of $10,000
-		AvailBalance: 5000.0,  // $5,000 available
-		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+		c.sendError(fmt.Sprintf("DEBUG: Created demo position for %s", position.InstrumentID))
 	}
 	
-	c.balanceCh <- demoBalance
-	c.errorCh <- "DEBUG: Created demo balance"
+	// Also create a demo balance, which moves with the demo PnL
+	c.sendBalance(c.demoBalance())
+	c.sendError("DEBUG: Created demo balance")
 }
 
-// updateTickerSubscriptions subscribes to tickers for current positions
+// updateTickerSubscriptions brings the price subscriptions in line with the
+// watched instruments: instruments no longer watched are unsubscribed and
+// newly watched ones subscribed, leaving the rest as they are
 func (c *OKXClient) updateTickerSubscriptions() error {
-	if c.tickerConn == nil {
+	conn, mu := c.priceConn()
+	if conn == nil {
 		return fmt.Errorf("ticker connection not established")
 	}
 
-	// Protect ticker WebSocket writes with mutex
-	c.tickerMutex.Lock()
-	defer c.tickerMutex.Unlock()
-
-	var args []map[string]string
-
 	if c.isDemo {
-		// In demo mode, always subscribe to demo tickers for demo positions
-		args = []map[string]string{
-			{"channel": "tickers", "instId": "BTC-USDT-SWAP"},
-			{"channel": "tickers", "instId": "ETH-USDT-SWAP"},
-			{"channel": "tickers", "instId": "SOL-USDT-SWAP"},
-			{"channel": "tickers", "instId": "ADA-USDT-SWAP"},
-			{"channel": "tickers", "instId": "DOT-USDT-SWAP"},
-			{"channel": "tickers", "instId": "LINK-USDT-SWAP"},
-			{"channel": "tickers", "instId": "AVAX-USDT-SWAP"},
-			{"channel": "tickers", "instId": "MATIC-USDT-SWAP"},
-			{"channel": "tickers", "instId": "UNI-USDT-SWAP"},
-			{"channel": "tickers", "instId": "LTC-USDT-SWAP"},
-		}
-		c.errorCh <- "DEBUG: Demo mode - subscribing to demo tickers"
+		c.sendError("DEBUG: Demo mode - subscribing to demo tickers")
+	} else if positionList := c.trackedInstruments(); len(positionList) > 0 {
+		// Log current positions being tracked
+		c.sendError(fmt.Sprintf("DEBUG: Real mode - tracking positions: %v", positionList))
 	} else {
-		// In real mode, only subscribe to tickers for actual current positions
-		for instId := range c.currentPositions {
-			args = append(args, map[string]string{
-				"channel": "tickers",
-				"instId":  instId,
-			})
+		c.sendError("DEBUG: Real mode - no current positions, no ticker subscriptions needed")
+	}
+
+	c.priceSubsMutex.Lock()
+	defer c.priceSubsMutex.Unlock()
+
+	watched := make(map[string]bool)
+	for _, instId := range c.watchedInstruments() {
+		watched[instId] = true
+	}
+	var added, removed []string
+	for instId := range watched {
+		if !c.priceSubs[instId] {
+			added = append(added, instId)
+		}
+	}
+	for instId := range c.priceSubs {
+		if !watched[instId] {
+			removed = append(removed, instId)
 		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	// Instruments whose positions closed stop streaming, so stale prices
+	// can't touch positions that no longer exist
+	if len(removed) > 0 {
+		c.sendError(fmt.Sprintf("DEBUG: Unsubscribing from price channels for %v", removed))
+		if err := c.sendBatched(conn, mu, "unsubscribe", c.priceArgs(removed)); err != nil {
+			return err
+		}
+		for _, instId := range removed {
+			delete(c.priceSubs, instId)
+		}
+	}
 
-		// Log current positions being tracked
-		if len(c.currentPositions) > 0 {
-			var positionList []string
-			for instId := range c.currentPositions {
-				positionList = append(positionList, instId)
-			}
-			c.errorCh <- fmt.Sprintf("DEBUG: Real mode - tracking positions: %v", positionList)
-		} else {
-			c.errorCh <- "DEBUG: Real mode - no current positions, no ticker subscriptions needed"
-			// In real mode with no positions, don't subscribe to anything
-			return nil
+	// Large instrument sets are split into paced batches to stay under OKX's limits
+	if len(added) > 0 {
+		args := c.priceArgs(added)
+		c.sendError(fmt.Sprintf("DEBUG: Subscribing to %d ticker channels", len(args)))
+		if err := c.sendBatched(conn, mu, "subscribe", args); err != nil {
+			return err
+		}
+		for _, instId := range added {
+			c.priceSubs[instId] = true
 		}
 	}
 
-	subMsg := map[string]interface{}{
-		"op":   "subscribe",
-		"args": args,
+	// The subscriptions cover every tracked position, including queued ones
+	if c.tickerSubsPending.Swap(false) {
+		c.sendError("DEBUG: Flushed ticker subscriptions queued while disconnected")
 	}
+	return nil
+}
 
-	c.errorCh <- fmt.Sprintf("DEBUG: Subscribing to %d ticker channels", len(args))
-	return c.tickerConn.WriteJSON(subMsg)
+// SetFXInstruments keeps the tickers of the given instruments subscribed
+// alongside those of the positions, for live currency rates in the UI
+func (c *OKXClient) SetFXInstruments(instIds []string) {
+	c.fxInstruments = instIds
+}
+
+// watchedInstruments returns the instruments whose prices are subscribed to.
+// Demo mode watches the demo instruments, real mode the tracked positions,
+// both along with the FX rate tickers.
+func (c *OKXClient) watchedInstruments() []string {
+	instIds := append([]string(nil), c.fxInstruments...)
+	if c.isDemo {
+		// Synthetic demo instruments follow these, so they need no ticker
+		for _, demo := range c.demoTemplateSet() {
+			instIds = append(instIds, demo.InstID)
+		}
+	} else {
+		instIds = append(instIds, c.trackedInstruments()...)
+	}
+	return instIds
+}
+
+// trackedInstruments returns the instruments with an open position, sorted
+func (c *OKXClient) trackedInstruments() []string {
+	c.positionsMutex.Lock()
+	defer c.positionsMutex.Unlock()
+
+	instIds := make([]string, 0, len(c.currentPositions))
+	for instId := range c.currentPositions {
+		instIds = append(instIds, instId)
+	}
+	sort.Strings(instIds)
+	return instIds
+}
+
+// priceArgs returns the price channel arguments for the given instruments,
+// with their mark prices alongside tickers, plus their trades when the trades
+// feed is enabled
+func (c *OKXClient) priceArgs(instIds []string) []map[string]string {
+	channels := []string{c.priceChannel()}
+	if channels[0] == "tickers" {
+		// Mark prices take over from the last trade once they arrive
+		channels = append(channels, "mark-price")
+	}
+	if c.tradeCh != nil {
+		// Subscribe to the trades feed for the same instruments when enabled
+		channels = append(channels, "trades")
+	}
+
+	var args []map[string]string
+	for _, channel := range channels {
+		for _, instId := range instIds {
+			args = append(args, map[string]string{"channel": channel, "instId": instId})
+		}
+	}
+	return args
 }
 
 // unsubscribeAllTickers unsubscribes from all ticker channels to clean up subscriptions
 func (c *OKXClient) unsubscribeAllTickers() error {
-	if c.tickerConn == nil {
+	conn, mu := c.priceConn()
+	if conn == nil {
 		return fmt.Errorf("ticker connection not established")
 	}
 
 	// Create unsubscribe message for common demo tickers
+	channel := c.priceChannel()
 	unsubArgs := []map[string]string{
-		{"channel": "tickers", "instId": "BTC-USDT-SWAP"},
-		{"channel": "tickers", "instId": "ETH-USDT-SWAP"},
-		{"channel": "tickers", "instId": "SOL-USDT-SWAP"},
-		{"channel": "tickers", "instId": "ADA-USDT-SWAP"},
-		{"channel": "tickers", "instId": "DOT-USDT-SWAP"},
-		{"channel": "tickers", "instId": "LINK-USDT-SWAP"},
-		{"channel": "tickers", "instId": "AVAX-USDT-SWAP"},
-		{"channel": "tickers", "instId": "MATIC-USDT-SWAP"},
-		{"channel": "tickers", "instId": "UNI-USDT-SWAP"},
-		{"channel": "tickers", "instId": "LTC-USDT-SWAP"},
+		{"channel": channel, "instId": "BTC-USDT-SWAP"},
+		{"channel": channel, "instId": "ETH-USDT-SWAP"},
+		{"channel": channel, "instId": "SOL-USDT-SWAP"},
+		{"channel": channel, "instId": "ADA-USDT-SWAP"},
+		{"channel": channel, "instId": "DOT-USDT-SWAP"},
+		{"channel": channel, "instId": "LINK-USDT-SWAP"},
+		{"channel": channel, "instId": "AVAX-USDT-SWAP"},
+		{"channel": channel, "instId": "MATIC-USDT-SWAP"},
+		{"channel": channel, "instId": "UNI-USDT-SWAP"},
+		{"channel": channel, "instId": "LTC-USDT-SWAP"},
 	}
 
 	unsubMsg := map[string]interface{}{
@@ -427,8 +782,11 @@ func (c *OKXClient) unsubscribeAllTickers() error {
 		"args": unsubArgs,
 	}
 
-	c.errorCh <- fmt.Sprintf("DEBUG: Unsubscribing from %d ticker channels", len(unsubArgs))
-	return c.tickerConn.WriteJSON(unsubMsg)
+	c.sendError(fmt.Sprintf("DEBUG: Unsubscribing from %d ticker channels", len(unsubArgs)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	return conn.WriteJSON(unsubMsg)
 }
 
 // authenticate sends authentication message for private WebSocket
@@ -459,36 +817,46 @@ func (c *OKXClient) authenticate() error {
 	return c.conn.WriteJSON(authMsg)
 }
 
+// privateSubscriptionArgs are the position and account channels subscribed to
+// on the private connection
+var privateSubscriptionArgs = []map[string]string{
+	{
+		"channel":  "positions",
+		"instType": "SWAP",
+	},
+	{
+		"channel": "account",
+	},
+}
+
 // subscribe subscribes to position updates
 func (c *OKXClient) subscribe() error {
+	// A paused client subscribes again on resume
+	if c.paused.Load() {
+		c.sendError("DEBUG: Feed paused, skipping subscriptions")
+		return nil
+	}
+
 	var subMsg map[string]interface{}
 
 	if c.apiKey != "" {
 		// Subscribe to private position and account channels
 		subMsg = map[string]interface{}{
-			"op": "subscribe",
-			"args": []map[string]string{
-				{
-					"channel": "positions",
-					"instType": "SWAP",
-				},
-				{
-					"channel": "account",
-				},
-			},
+			"op":   "subscribe",
+			"args": privateSubscriptionArgs,
 		}
 	} else {
 		// In demo mode, don't subscribe to anything on the main WebSocket
 		// Ticker data will be handled by the dedicated ticker WebSocket
-		c.errorCh <- "DEBUG: Demo mode - no subscriptions needed on main WebSocket"
+		c.sendError("DEBUG: Demo mode - no subscriptions needed on main WebSocket")
 		
 		// Trigger ticker subscriptions on the dedicated ticker WebSocket
-		if c.tickerConn != nil {
-			go func() {
+		if conn, _ := c.priceConn(); conn != nil {
+			c.goSafe(func() {
 				if err := c.updateTickerSubscriptions(); err != nil {
-					c.errorCh <- fmt.Sprintf("Failed to initialize ticker subscriptions: %v", err)
+					c.sendError(fmt.Sprintf("Failed to initialize ticker subscriptions: %v", err))
 				}
-			}()
+			})
 		}
 		return nil
 	}
@@ -503,12 +871,12 @@ func (c *OKXClient) subscribe() error {
 	}
 
 	// Also trigger initial ticker subscriptions if ticker connection is available
-	if c.tickerConn != nil {
-		go func() {
+	if conn, _ := c.priceConn(); conn != nil {
+		c.goSafe(func() {
 			if err := c.updateTickerSubscriptions(); err != nil {
-				c.errorCh <- fmt.Sprintf("Failed to initialize ticker subscriptions: %v", err)
+				c.sendError(fmt.Sprintf("Failed to initialize ticker subscriptions: %v", err))
 			}
-		}()
+		})
 	}
 
 	return nil
@@ -516,15 +884,27 @@ func (c *OKXClient) subscribe() error {
 
 // StartListening starts listening for position updates
 func (c *OKXClient) StartListening() {
-	defer c.conn.Close()
+	conn := c.conn
+	defer conn.Close()
+
+	// Stop the heartbeat and shutdown watcher along with the listener
+	done := make(chan struct{})
+	defer close(done)
 
 	// Start heartbeat goroutine
-	go c.heartbeat()
+	c.goSafe(func() { c.heartbeat(conn, done) })
+	c.goSafe(func() { c.closeOnCancel(conn, &c.connMutex, done) })
 
 	for {
-		_, message, err := c.conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
-			c.errorCh <- fmt.Sprintf("WebSocket read error: %v", err)
+			if c.stopping() {
+				return
+			}
+			c.sendError(fmt.Sprintf("WebSocket read error: %v", err))
+			if code, text, ok := closeCode(err); ok {
+				c.stopOnError(code, text)
+			}
 			return
 		}
 
@@ -536,7 +916,7 @@ func (c *OKXClient) StartListening() {
 		// Parse the message and extract position data
 		var response map[string]interface{}
 		if err := json.Unmarshal(message, &response); err != nil {
-			c.errorCh <- fmt.Sprintf("Failed to parse message: %v", err)
+			c.sendError(fmt.Sprintf("Failed to parse message: %v", err))
 			continue
 		}
 
@@ -545,63 +925,54 @@ func (c *OKXClient) StartListening() {
 			switch event {
 			case "login":
 				if code, ok := response["code"].(string); ok && code == "0" {
-					c.errorCh <- "DEBUG: Successfully authenticated with OKX"
+					c.sendError("DEBUG: Successfully authenticated with OKX")
+					c.accountSeen.Store(false)
+					if c.balanceTimeout > 0 {
+						c.goSafe(c.watchAccountSnapshot)
+					}
+					if c.closedCh != nil && c.closedPolling.CompareAndSwap(false, true) {
+						c.goSafe(c.pollClosedHistory)
+					}
 					// Now subscribe to position updates after successful authentication
 					if err := c.subscribe(); err != nil {
-						c.errorCh <- fmt.Sprintf("Subscription failed after authentication: %v", err)
+						c.sendError(fmt.Sprintf("Subscription failed after authentication: %v", err))
 					}
 				} else {
-					msg := "Authentication failed"
-					if errMsg, ok := response["msg"]; ok {
-						msg = fmt.Sprintf("Authentication failed: %v", errMsg)
+					msg, _ := response["msg"].(string)
+					c.sendError("Authentication failed: " + describeError(code, msg))
+					if c.stopOnError(code, msg) || c.reconnectOnError(code) {
+						return
 					}
-					c.errorCh <- msg
 				}
 			case "subscribe":
-				c.errorCh <- "DEBUG: Successfully subscribed to OKX channels"
+				c.sendError("DEBUG: Successfully subscribed to OKX channels")
+				c.ackSubscription()
 			case "error":
-				errMsg := fmt.Sprintf("OKX error: %v", response["msg"])
-				c.errorCh <- fmt.Sprintf("OKX error received: %v", errMsg)
+				// Fatal codes stop reconnecting, transient ones reconnect, rate
+				// limits back off and the rest, e.g. a rejected subscription,
+				// are only reported
+				code, _ := response["code"].(string)
+				msg, _ := response["msg"].(string)
+				c.sendError(describeError(code, msg))
+				if c.stopOnError(code, msg) || c.reconnectOnError(code) {
+					return
+				}
+				c.backOffOnError(code)
 			}
 			continue
 		}
 
 		// Handle position/ticker data
 		if data, ok := response["data"].([]interface{}); ok {
-			// Check if this is account balance data or position data
-			if arg, ok := response["arg"].(map[string]interface{}); ok {
-				if channel, ok := arg["channel"].(string); ok {
-					switch channel {
-					case "account":
-						// Handle balance data
-						c.errorCh <- fmt.Sprintf("DEBUG: Received %d balance items", len(data))
-						for _, item := range data {
-							if balData, ok := item.(map[string]interface{}); ok {
-								balance := c.parseBalanceData(balData)
-								c.errorCh <- fmt.Sprintf("DEBUG: Parsed balance data for %s", balance.Currency)
-								c.balanceCh <- balance
-							}
-						}
-					case "positions", "tickers":
-						// Handle position/ticker data
-						c.errorCh <- fmt.Sprintf("DEBUG: Received %d position/ticker items", len(data))
-						for _, item := range data {
-							if posData, ok := item.(map[string]interface{}); ok {
-								position := c.parsePositionData(posData)
-								c.errorCh <- fmt.Sprintf("DEBUG: Parsed position data for %s", position.InstrumentID)
-								c.positionCh <- position
-							}
-						}
-					}
-				}
-			} else {
+			// Route account balance and position data to the registered channel handlers
+			if !c.dispatchChannel(c.mainHandlers, response, data) {
 				// Fallback for data without arg (older format)
-				c.errorCh <- fmt.Sprintf("DEBUG: Received %d data items (fallback)", len(data))
+				c.debugf(debugPosition, "Received %d data items (fallback)", len(data))
 				for _, item := range data {
 					if posData, ok := item.(map[string]interface{}); ok {
 						position := c.parsePositionData(posData)
-						c.errorCh <- fmt.Sprintf("DEBUG: Parsed position data for %s", position.InstrumentID)
-						c.positionCh <- position
+						c.instDebugf(position.InstrumentID, debugPosition, "Parsed position data for %s", position.InstrumentID)
+						c.sendPosition(position)
 					}
 				}
 			}
@@ -609,138 +980,97 @@ func (c *OKXClient) StartListening() {
 	}
 }
 
-// heartbeat sends ping messages to keep connection alive
-func (c *OKXClient) heartbeat() {
+// heartbeat sends ping messages to keep connection alive, stopping once the
+// listener closes done so a reconnect never leaves two heartbeats running
+func (c *OKXClient) heartbeat(conn *websocket.Conn, done <-chan struct{}) {
 	ticker := time.NewTicker(25 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-done:
+			return
+		case <-c.ctx.Done():
+			return
 		case <-ticker.C:
-			if c.conn != nil {
-				// Protect main WebSocket writes with mutex
-				c.connMutex.Lock()
-				err := c.conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			// Protect main WebSocket writes with mutex, never writing once
+			// shutdown has closed the connection
+			c.connMutex.Lock()
+			if c.stopping() {
 				c.connMutex.Unlock()
-				
-				if err != nil {
-					c.errorCh <- fmt.Sprintf("Failed to send ping: %v", err)
-					return
-				}
+				return
+			}
+			err := conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			c.connMutex.Unlock()
+
+			if err != nil {
+				c.sendError(fmt.Sprintf("DEBUG: Failed to send ping: %v", err))
+				return
 			}
 		}
 	}
 }
 
-// parsePositionData converts raw data to PositionData struct
-func (c *OKXClient) parsePositionData(data map[string]interface{}) PositionData {
-	position := PositionData{
-		InstrumentID: getString(data, "instId"),
-		PositionSide: getString(data, "posSide"),
-		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
-	}
-
-	// Handle both position data and ticker data
-	if position.PositionSide == "" {
-		position.PositionSide = "long" // Default for ticker data
-	}
-
-	// Parse numeric fields with proper error handling
-	if size, ok := data["pos"].(string); ok {
-		fmt.Sscanf(size, "%f", &position.Size)
+// trackPosition records whether the position's instrument has an open side,
+// reporting whether the instrument was added to or removed from the tracked set
+func (c *OKXClient) trackPosition(position PositionData) bool {
+	instId := position.InstrumentID
+	c.positionsMutex.Lock()
+	var added, removed bool
+	if position.Size != 0 {
+		// Add or update position in tracking map
+		if c.openSides[instId] == nil {
+			c.openSides[instId] = make(map[string]bool)
+		}
+		c.openSides[instId][position.PositionSide] = true
+		if !c.currentPositions[instId] {
+			c.currentPositions[instId] = true
+			added = true
+		}
 	} else {
-		position.Size = 1.0 // Default size for ticker data
-	}
-
-	if avgPx, ok := data["avgPx"].(string); ok {
-		fmt.Sscanf(avgPx, "%f", &position.AvgPrice)
-	} else if last, ok := data["last"].(string); ok {
-		fmt.Sscanf(last, "%f", &position.AvgPrice)
+		// Remove position from tracking map when size is 0 (position closed),
+		// unless the other leg of a hedge is still open on the instrument
+		delete(c.openSides[instId], position.PositionSide)
+		if c.currentPositions[instId] && len(c.openSides[instId]) == 0 {
+			delete(c.currentPositions, instId)
+			delete(c.openSides, instId)
+			removed = true
+		}
 	}
+	c.positionsMutex.Unlock()
 
-	if markPx, ok := data["markPx"].(string); ok {
-		fmt.Sscanf(markPx, "%f", &position.CurrentPrice)
-	} else if last, ok := data["last"].(string); ok {
-		fmt.Sscanf(last, "%f", &position.CurrentPrice)
+	if added {
+		c.sendError(fmt.Sprintf("DEBUG: Added position tracking for %s", instId))
 	}
-
-	// Parse PnL fields - prioritize actual OKX data over calculations
-	pnlFound := false
-	if upl, ok := data["upl"].(string); ok && upl != "" && upl != "0" {
-		fmt.Sscanf(upl, "%f", &position.PnL)
-		pnlFound = true
-		c.errorCh <- fmt.Sprintf("DEBUG: Using UPL (unrealized PnL): %s = %.4f", upl, position.PnL)
-	} else if pnl, ok := data["pnl"].(string); ok && pnl != "" && pnl != "0" {
-		// Fallback to 'pnl' for realized PnL or other data
-		fmt.Sscanf(pnl, "%f", &position.PnL)
-		pnlFound = true
-		c.errorCh <- fmt.Sprintf("DEBUG: Using PNL (realized PnL): %s = %.4f", pnl, position.PnL)
-	}
-	
-	// Only calculate mock PnL if no real PnL data is available and we have valid prices
-	if !pnlFound && position.CurrentPrice > 0 && position.AvgPrice > 0 && position.Size > 0 {
-		if position.PositionSide == "short" {
-			// For short positions, profit when price goes down
-			position.PnL = (position.AvgPrice - position.CurrentPrice) * position.Size
-		} else {
-			// For long positions, profit when price goes up
-			position.PnL = (position.CurrentPrice - position.AvgPrice) * position.Size
-		}
-		c.errorCh <- fmt.Sprintf("DEBUG: Calculated PnL for %s: %.4f (avg: %.4f, current: %.4f, size: %.4f)", 
-			position.PositionSide, position.PnL, position.AvgPrice, position.CurrentPrice, position.Size)
-	}
-
-	// Parse PnL ratio - prioritize actual OKX data
-	ratioFound := false
-	if uplRatio, ok := data["uplRatio"].(string); ok && uplRatio != "" && uplRatio != "0" {
-		fmt.Sscanf(uplRatio, "%f", &position.PnLRatio)
-		position.PnLRatio *= 100 // Convert from decimal to percentage
-		ratioFound = true
-		c.errorCh <- fmt.Sprintf("DEBUG: Using UPL Ratio: %s = %.2f%%", uplRatio, position.PnLRatio)
-	} else if pnlRatio, ok := data["pnlRatio"].(string); ok && pnlRatio != "" && pnlRatio != "0" {
-		// Fallback to 'pnlRatio' for other data
-		fmt.Sscanf(pnlRatio, "%f", &position.PnLRatio)
-		position.PnLRatio *= 100 // Convert from decimal to percentage
-		ratioFound = true
-		c.errorCh <- fmt.Sprintf("DEBUG: Using PNL Ratio: %s = %.2f%%", pnlRatio, position.PnLRatio)
-	}
-	
-	// Only calculate ratio if no real ratio data is available and we have valid data
-	if !ratioFound && position.AvgPrice > 0 && position.Size > 0 {
-		position.PnLRatio = (position.PnL / (position.AvgPrice * position.Size)) * 100
-		c.errorCh <- fmt.Sprintf("DEBUG: Calculated PnL ratio: %.2f%%", position.PnLRatio)
+	if removed {
+		c.sendError(fmt.Sprintf("DEBUG: Removed position tracking for %s (position closed)", instId))
 	}
+	return added || removed
+}
 
-	if lever, ok := data["lever"].(string); ok {
-		fmt.Sscanf(lever, "%f", &position.Leverage)
-	} else {
-		position.Leverage = 1.0 // Default leverage
+// parsePositionData converts raw data to PositionData struct, forwarding the
+// parsing traces to the debug pane and tracking the position for ticker
+// subscriptions
+func (c *OKXClient) parsePositionData(data map[string]interface{}) PositionData {
+	position, traces := parsePosition(data, c.leverageOverrides)
+	for _, trace := range traces {
+		c.instDebugf(position.InstrumentID, trace.category, trace.format, trace.args...)
 	}
 
 	// Track current positions for ticker subscriptions
 	if position.InstrumentID != "" {
-		positionChanged := false
+		positionChanged := c.trackPosition(position)
 		
-		if position.Size > 0 {
-			// Add or update position in tracking map
-			if !c.currentPositions[position.InstrumentID] {
-				c.currentPositions[position.InstrumentID] = true
-				positionChanged = true
-				c.errorCh <- fmt.Sprintf("DEBUG: Added position tracking for %s", position.InstrumentID)
-			}
-		} else {
-			// Remove position from tracking map when size is 0 (position closed)
-			if c.currentPositions[position.InstrumentID] {
-				delete(c.currentPositions, position.InstrumentID)
-				positionChanged = true
-				c.errorCh <- fmt.Sprintf("DEBUG: Removed position tracking for %s (position closed)", position.InstrumentID)
-			}
-		}
-		
-		// Update ticker subscriptions only when positions change
-		if positionChanged && c.tickerConn != nil {
-			if err := c.updateTickerSubscriptions(); err != nil {
-				c.errorCh <- fmt.Sprintf("Failed to update ticker subscriptions: %v", err)
+		// Update ticker subscriptions only when positions change. Without a price
+		// connection the change is queued and flushed once it is back, rather than
+		// failing for every position.
+		if positionChanged {
+			if conn, _ := c.priceConn(); conn == nil {
+				if !c.tickerSubsPending.Swap(true) {
+					c.sendError("DEBUG: No ticker connection, queueing ticker subscriptions until it reconnects")
+				}
+			} else if err := c.updateTickerSubscriptions(); err != nil {
+				c.sendError(fmt.Sprintf("Failed to update ticker subscriptions: %v", err))
 			}
 		}
 	}
@@ -752,18 +1082,26 @@ func (c *OKXClient) parsePositionData(data map[string]interface{}) PositionData
 func (c *OKXClient) parseBalanceData(data map[string]interface{}) BalanceData {
 	balance := BalanceData{
 		Currency:  getString(data, "ccy"),
-		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		Timestamp: exchangeTimestamp(data, nowMillis()),
 	}
 
 	// Parse numeric fields with proper error handling
-	if totalEq, ok := data["totalEq"].(string); ok {
-		fmt.Sscanf(totalEq, "%f", &balance.TotalEquity)
-	}
+	c.parseNumber(balance.Currency, "totalEq", getString(data, "totalEq"), &balance.TotalEquity)
+	c.parseNumber(balance.Currency, "availBal", getString(data, "availBal"), &balance.AvailBalance)
 
-	if availBal, ok := data["availBal"].(string); ok {
-		fmt.Sscanf(availBal, "%f", &balance.AvailBalance)
+	// Account margin ratio - OKX reports it as a decimal
+	if c.parseNumber(balance.Currency, "mgnRatio", getString(data, "mgnRatio"), &balance.MarginRatio) {
+		balance.MarginRatio *= 100
 	}
 
+	// Adjusted equity and order-frozen margin, only sent in some account modes
+	c.parseNumber(balance.Currency, "adjEq", getString(data, "adjEq"), &balance.AdjustedEquity)
+	c.parseNumber(balance.Currency, "ordFroz", getString(data, "ordFroz"), &balance.OrderFrozen)
+
+	// Cross-margin borrowing from the per-currency details
+	balance.Borrows = c.parseBorrows(data)
+	balance.AutoLoan, balance.AutoLoanReported = parseAutoLoan(data)
+
 	return balance
 }
 
@@ -775,20 +1113,42 @@ func getString(data map[string]interface{}, key string) string {
 	return ""
 }
 
+// exchangeTimestamp returns the OKX event time (epoch ms) from ts, pTime or uTime,
+// falling back to the given local time when none is present
+func exchangeTimestamp(data map[string]interface{}, fallback int64) int64 {
+	for _, key := range []string{"ts", "pTime", "uTime"} {
+		if ts, err := strconv.ParseInt(getString(data, key), 10, 64); err == nil && ts > 0 {
+			return ts
+		}
+	}
+	return fallback
+}
+
+// nowMillis returns the current local time in epoch milliseconds
+func nowMillis() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
 // Close closes the WebSocket connections
 func (c *OKXClient) Close() error {
 	var err error
 	
 	// Close main connection
-	if c.conn != nil {
-		if closeErr := c.conn.Close(); closeErr != nil {
+	c.connMutex.Lock()
+	conn := c.conn
+	c.connMutex.Unlock()
+	if conn != nil {
+		if closeErr := conn.Close(); closeErr != nil {
 			err = closeErr
 		}
 	}
 	
 	// Close ticker connection
-	if c.tickerConn != nil {
-		if closeErr := c.tickerConn.Close(); closeErr != nil {
+	c.tickerMutex.Lock()
+	tickerConn := c.tickerConn
+	c.tickerMutex.Unlock()
+	if tickerConn != nil {
+		if closeErr := tickerConn.Close(); closeErr != nil {
 			if err == nil {
 				err = closeErr
 			}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// newTestDemoClient returns a demo client with buffered channels and no ticker
+// throttling, holding the given demo positions without connecting anywhere
+func newTestDemoClient(positions ...PositionData) (*OKXClient, chan PositionData, chan BalanceData, chan string) {
+	positionCh := make(chan PositionData, 100)
+	balanceCh := make(chan BalanceData, 100)
+	errorCh := make(chan string, 100)
+	c := NewOKXClient(positionCh, balanceCh, errorCh)
+	c.isDemo = true
+	c.SetTickerThrottle(0)
+	for _, pos := range positions {
+		c.demoPositions[pos.InstrumentID] = pos
+	}
+	return c, positionCh, balanceCh, errorCh
+}
+
+// receivePosition returns the next position sent, failing the test if none is
+func receivePosition(t *testing.T, ch <-chan PositionData) PositionData {
+	t.Helper()
+	select {
+	case pos := <-ch:
+		return pos
+	case <-time.After(time.Second):
+		t.Fatal("no position sent")
+		return PositionData{}
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestDemoTickerRecalculatesPnL(t *testing.T) {
+	tests := []struct {
+		name      string
+		pos       PositionData
+		last      string
+		wantPrice float64
+		wantPnL   float64
+		wantRatio float64
+	}{
+		{
+			name:      "long up 2%",
+			pos:       PositionData{InstrumentID: "BTC-USDT-SWAP", PositionSide: "long", Size: 2, AvgPrice: 50000, CurrentPrice: 50000, Leverage: 10},
+			last:      "51000",
+			wantPrice: 51000,
+			wantPnL:   2000,
+			wantRatio: 20,
+		},
+		{
+			name:      "short against it 2%",
+			pos:       PositionData{InstrumentID: "ETH-USDT-SWAP", PositionSide: "short", Size: 3, AvgPrice: 3000, CurrentPrice: 3000, Leverage: 5},
+			last:      "3060",
+			wantPrice: 3060,
+			wantPnL:   -180,
+			wantRatio: -10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, positionCh, balanceCh, _ := newTestDemoClient(tt.pos)
+			c.handleTickerData(map[string]interface{}{"instId": tt.pos.InstrumentID, "last": tt.last})
+
+			got := receivePosition(t, positionCh)
+			if got.CurrentPrice != tt.wantPrice {
+				t.Errorf("CurrentPrice = %v, want %v", got.CurrentPrice, tt.wantPrice)
+			}
+			if !approxEqual(got.PnL, tt.wantPnL) || !approxEqual(got.PnLRatio, tt.wantRatio) {
+				t.Errorf("PnL %v (%v%%), want %v (%v%%)", got.PnL, got.PnLRatio, tt.wantPnL, tt.wantRatio)
+			}
+			if stored := c.demoPositions[tt.pos.InstrumentID]; stored.PnL != got.PnL {
+				t.Errorf("stored demo PnL %v, want the sent %v", stored.PnL, got.PnL)
+			}
+
+			// The demo balance follows the PnL
+			balance := <-balanceCh
+			if !approxEqual(balance.TotalEquity, defaultDemoEquity+tt.wantPnL) {
+				t.Errorf("demo equity %v, want %v", balance.TotalEquity, defaultDemoEquity+tt.wantPnL)
+			}
+		})
+	}
+}
+
+func TestRecalcDemoPnLScalesRatioByLeverage(t *testing.T) {
+	tests := []struct {
+		name      string
+		side      string
+		leverage  float64
+		price     float64
+		wantPnL   float64
+		wantRatio float64
+	}{
+		{"10x short down 2%", "short", 10, 98, 4, 20},
+		{"10x short up 2%", "short", 10, 102, -4, -20},
+		{"20x short down 2%", "short", 20, 98, 4, 40},
+		{"1x short down 2%", "short", 1, 98, 4, 2},
+		{"10x long up 2%", "long", 10, 102, 4, 20},
+		{"no leverage assumes 1x", "short", 0, 98, 4, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := PositionData{InstrumentID: "BTC-USDT-SWAP", PositionSide: tt.side, Size: 2, AvgPrice: 100, Leverage: tt.leverage}
+			got := recalcDemoPnL(pos, tt.price)
+			if !approxEqual(got.PnL, tt.wantPnL) {
+				t.Errorf("PnL = %v, want %v", got.PnL, tt.wantPnL)
+			}
+			if !approxEqual(got.PnLRatio, tt.wantRatio) {
+				t.Errorf("PnLRatio = %v%%, want %v%%", got.PnLRatio, tt.wantRatio)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// BookLevel represents a single price level in the order book
+type BookLevel struct {
+	Price float64
+	Size  float64
+}
+
+// BookData represents a books5 depth snapshot for an instrument
+type BookData struct {
+	InstrumentID string
+	Bids         []BookLevel
+	Asks         []BookLevel
+	Timestamp    int64
+}
+
+// SetBookChannel sets the channel order book snapshots are sent to
+func (c *OKXClient) SetBookChannel(bookCh chan<- BookData) {
+	c.bookCh = bookCh
+}
+
+// WatchBookRequests subscribes to books5 for the instrument received on reqCh,
+// replacing the previous subscription. An empty instrument unsubscribes. It
+// returns when reqCh is closed or the client shuts down.
+func (c *OKXClient) WatchBookRequests(reqCh <-chan string) {
+	for {
+		var instId string
+		select {
+		case <-c.ctx.Done():
+			return
+		case req, ok := <-reqCh:
+			if !ok {
+				return
+			}
+			instId = req
+		}
+
+		c.bookMutex.Lock()
+		previous := c.bookInstrument
+		c.bookInstrument = instId
+		c.bookMutex.Unlock()
+
+		// While paused only remember the instrument, Resume subscribes to it
+		if instId == previous || c.paused.Load() {
+			continue
+		}
+
+		if previous != "" {
+			if err := c.sendBookOp("unsubscribe", previous); err != nil {
+				c.sendError(fmt.Sprintf("Failed to unsubscribe order book: %v", err))
+			}
+		}
+
+		if instId == "" {
+			continue
+		}
+
+		if err := c.sendBookOp("subscribe", instId); err != nil {
+			c.sendError(fmt.Sprintf("Failed to subscribe order book: %v", err))
+		}
+	}
+}
+
+// sendBookOp sends a books5 subscribe or unsubscribe on the ticker connection
+func (c *OKXClient) sendBookOp(op, instId string) error {
+	conn, mu := c.priceConn()
+	if conn == nil {
+		return fmt.Errorf("ticker connection not established")
+	}
+
+	msg := map[string]interface{}{
+		"op": op,
+		"args": []map[string]string{
+			{"channel": "books5", "instId": instId},
+		},
+	}
+
+	c.sendError(fmt.Sprintf("DEBUG: Order book %s for %s", op, instId))
+
+	// Protect ticker WebSocket writes with mutex
+	mu.Lock()
+	defer mu.Unlock()
+
+	return conn.WriteJSON(msg)
+}
+
+// handleBookData parses a books5 snapshot and forwards it to the book channel
+func (c *OKXClient) handleBookData(arg map[string]interface{}, data map[string]interface{}) {
+	if c.bookCh == nil {
+		return
+	}
+
+	// books5 always sends full snapshots, so each frame replaces the previous book
+	book := BookData{
+		InstrumentID: getString(data, "instId"),
+		Bids:         parseBookLevels(data["bids"]),
+		Asks:         parseBookLevels(data["asks"]),
+		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if book.InstrumentID == "" {
+		book.InstrumentID = getString(arg, "instId")
+	}
+
+	// Ignore late frames for an instrument we already left
+	c.bookMutex.Lock()
+	current := c.bookInstrument
+	c.bookMutex.Unlock()
+	if book.InstrumentID != current {
+		return
+	}
+
+	select {
+	case c.bookCh <- book:
+	default:
+	}
+}
+
+// parseBookLevels converts raw [price, size, ...] entries into book levels
+func parseBookLevels(raw interface{}) []BookLevel {
+	entries, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var levels []BookLevel
+	for _, entry := range entries {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) < 2 {
+			continue
+		}
+
+		var level BookLevel
+		if px, ok := fields[0].(string); ok {
+			fmt.Sscanf(px, "%f", &level.Price)
+		}
+		if sz, ok := fields[1].(string); ok {
+			fmt.Sscanf(sz, "%f", &level.Size)
+		}
+		levels = append(levels, level)
+	}
+
+	return levels
+}
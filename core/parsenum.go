@@ -0,0 +1,31 @@
+package core
+
+import "strconv"
+
+// parseNumber parses a numeric OKX field into dst, reporting whether it held
+// a number. An empty value counts as absent and is left alone silently; any
+// other value that isn't a number leaves dst unchanged and is traced with the
+// field name and raw value, so unexpected formats from OKX show up in the
+// debug pane instead of as a silent zero.
+func parseNumber(instId, field, raw string, dst *float64, tracef func(category, format string, args ...interface{})) bool {
+	if raw == "" {
+		return false
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		if instId != "" {
+			field = instId + " " + field
+		}
+		tracef(debugParse, "Malformed %s %q from OKX, ignoring it", field, raw)
+		return false
+	}
+	*dst = value
+	return true
+}
+
+// parseNumber is parseNumber tracing malformed values to the debug pane
+func (c *OKXClient) parseNumber(instId, field, raw string, dst *float64) bool {
+	return parseNumber(instId, field, raw, dst, func(category, format string, args ...interface{}) {
+		c.instDebugf(instId, category, format, args...)
+	})
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Pause unsubscribes from every data channel while keeping the connections
+// open with heartbeats only, so nothing streams until Resume. Subscriptions
+// skipped during a reconnect while paused are also restored on resume.
+func (c *OKXClient) Pause() error {
+	if c.paused.Swap(true) {
+		return nil
+	}
+	c.sendError("DEBUG: Pausing feed, keeping connections alive")
+
+	var errs []error
+
+	// Private position and account channels on the main connection, which a
+	// reconnect may replace meanwhile
+	if c.apiKey != "" {
+		c.connMutex.Lock()
+		var err error
+		if c.conn != nil {
+			err = c.conn.WriteJSON(map[string]interface{}{
+				"op":   "unsubscribe",
+				"args": privateSubscriptionArgs,
+			})
+		}
+		c.connMutex.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Prices, trades and the order book on the market data connection
+	if conn, mu := c.priceConn(); conn != nil {
+		// Everything subscribed is dropped, resume subscribes the watched set afresh
+		c.priceSubsMutex.Lock()
+		args := c.priceArgs(c.subscribedInstruments())
+		c.priceSubs = make(map[string]bool)
+		c.priceSubsMutex.Unlock()
+
+		c.bookMutex.Lock()
+		if c.bookInstrument != "" {
+			args = append(args, map[string]string{"channel": "books5", "instId": c.bookInstrument})
+		}
+		c.bookMutex.Unlock()
+
+		if err := c.sendBatched(conn, mu, "unsubscribe", args); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("pause: %v", errs[0])
+	}
+	return nil
+}
+
+// Resume restores the subscriptions dropped by Pause: positions and account
+// with credentials, prices for every tracked position, and the order book of
+// the instrument last requested
+func (c *OKXClient) Resume() error {
+	if !c.paused.Swap(false) {
+		return nil
+	}
+	c.sendError("DEBUG: Resuming feed")
+
+	c.connMutex.Lock()
+	conn := c.conn
+	c.connMutex.Unlock()
+	if conn == nil {
+		// Not connected yet, the next connection subscribes as usual
+		return nil
+	}
+	if err := c.subscribe(); err != nil {
+		return fmt.Errorf("resume: %v", err)
+	}
+	c.resubscribeBook()
+	return nil
+}
+
+// WatchPauseRequests pauses the client when true is received on reqCh and
+// resumes it on false, until reqCh is closed or the client shuts down
+func (c *OKXClient) WatchPauseRequests(reqCh <-chan bool) {
+	for {
+		var pause bool
+		select {
+		case <-c.ctx.Done():
+			return
+		case req, ok := <-reqCh:
+			if !ok {
+				return
+			}
+			pause = req
+		}
+
+		var err error
+		if pause {
+			err = c.Pause()
+		} else {
+			err = c.Resume()
+		}
+		if err != nil {
+			c.sendError(err.Error())
+		}
+	}
+}
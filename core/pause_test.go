@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialTestConn returns a WebSocket connection to a server that reads and
+// discards everything sent to it, closed when the test ends
+func dialTestConn(t *testing.T) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Run with -race: position pushes track instruments on the listener while the
+// pause watcher resubscribes the watched set
+func TestPauseResumeWithPositionUpdates(t *testing.T) {
+	positionCh := make(chan PositionData, 16)
+	balanceCh := make(chan BalanceData, 16)
+	errorCh := make(chan string, 16)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-positionCh:
+			case <-balanceCh:
+			case <-errorCh:
+			}
+		}
+	}()
+
+	c := NewOKXClient(positionCh, balanceCh, errorCh)
+	c.tickerConn = dialTestConn(t)
+
+	instIds := []string{"BTC-USDT-SWAP", "ETH-USDT-SWAP", "SOL-USDT-SWAP"}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			instId := instIds[i%len(instIds)]
+			size := "1"
+			if i%2 == 1 {
+				size = "0"
+			}
+			c.parsePositionData(map[string]interface{}{"instId": instId, "posSide": "long", "pos": size})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if err := c.Pause(); err != nil {
+				t.Error(err)
+			}
+			if err := c.updateTickerSubscriptions(); err != nil { // As Resume does
+				t.Error(err)
+			}
+			c.paused.Store(false)
+		}
+	}()
+	wg.Wait()
+
+	// Once settled, the subscriptions match the open positions
+	if err := c.updateTickerSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	c.priceSubsMutex.Lock()
+	subscribed := strings.Join(c.subscribedInstruments(), ",")
+	c.priceSubsMutex.Unlock()
+	if tracked := strings.Join(c.trackedInstruments(), ","); subscribed != tracked {
+		t.Errorf("subscribed to %q, want the tracked %q", subscribed, tracked)
+	}
+}
+
+// Run with -race: pausing and resuming reads the main connection while
+// reconnects replace it
+func TestPauseResumeWhileReconnecting(t *testing.T) {
+	base, connects := closingServer(t, 4004)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	positionCh := make(chan PositionData, 100)
+	balanceCh := make(chan BalanceData, 100)
+	errorCh := make(chan string, 100)
+	go drainClient(ctx, positionCh, balanceCh, errorCh, make(chan PositionData))
+
+	c := NewOKXClientWithContext(ctx, positionCh, balanceCh, errorCh)
+	c.SetCredentials("key", "secret", "passphrase")
+	// The private connection is closed as soon as it is established
+	c.SetEndpoints(base+"/ticker", base+"/public", base+"/ticker")
+	c.SetReconnectPolicy(time.Millisecond, time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		c.RunWithReconnect()
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for connects.Load() < 20 && time.Now().Before(deadline) {
+		// Writes may hit a connection the server just closed
+		c.Pause()
+		c.Resume()
+		time.Sleep(time.Millisecond)
+	}
+	if n := connects.Load(); n < 20 {
+		t.Errorf("connected %d times, want reconnects throughout", n)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("did not stop on cancel")
+	}
+}
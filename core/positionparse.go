@@ -0,0 +1,168 @@
+package core
+
+import "fmt"
+
+// parseTrace is a debug trace from parsing, kept unformatted with its
+// category so the client can rate limit it before paying for formatting
+type parseTrace struct {
+	category string
+	format   string
+	args     []interface{}
+}
+
+// positionParser collects the traces of parsing one position
+type positionParser struct {
+	instId string
+	traces []parseTrace
+}
+
+// tracef records a debug trace of the given category
+func (p *positionParser) tracef(category, format string, args ...interface{}) {
+	p.traces = append(p.traces, parseTrace{category: category, format: format, args: args})
+}
+
+// ParsePosition converts a raw OKX positions or tickers item to PositionData,
+// returning the debug traces of how each field was derived. It has no side
+// effects; positions without a reported leverage assume 1x.
+func ParsePosition(data map[string]interface{}) (PositionData, []string) {
+	position, traces := parsePosition(data, nil)
+	debug := make([]string, len(traces))
+	for i, trace := range traces {
+		debug[i] = fmt.Sprintf(trace.format, trace.args...)
+	}
+	return position, debug
+}
+
+// parsePosition is ParsePosition with per-instrument leverage overrides,
+// returning the traces unformatted
+func parsePosition(data map[string]interface{}, leverageOverrides map[string]float64) (PositionData, []parseTrace) {
+	receivedAt := nowMillis()
+	position := PositionData{
+		InstrumentID: getString(data, "instId"),
+		PositionSide: getString(data, "posSide"),
+		Timestamp:    exchangeTimestamp(data, receivedAt),
+		ReceivedAt:   receivedAt,
+	}
+	p := &positionParser{instId: position.InstrumentID}
+
+	// Handle both position data and ticker data
+	if position.PositionSide == "" {
+		position.PositionSide = "long" // Default for ticker data
+	}
+
+	// Unknown sides keep their value for display but get no client-side PnL,
+	// since guessing the direction could show PnL with the wrong sign
+	unknownSide := !knownPositionSides[position.PositionSide]
+	if unknownSide {
+		p.tracef(debugPosition, "WARNING unknown posSide %q for %s, skipping client-side PnL", position.PositionSide, position.InstrumentID)
+	}
+
+	// Parse numeric fields with proper error handling
+	if size, ok := data["pos"].(string); ok {
+		parseNumber(p.instId, "pos", size, &position.Size, p.tracef)
+	} else {
+		position.Size = 1.0 // Default size for ticker data
+	}
+
+	if avgPx, ok := data["avgPx"].(string); ok {
+		parseNumber(p.instId, "avgPx", avgPx, &position.AvgPrice, p.tracef)
+	} else if last, ok := data["last"].(string); ok {
+		parseNumber(p.instId, "last", last, &position.AvgPrice, p.tracef)
+	}
+
+	// The last traded price stands in for a missing mark price
+	if last, ok := data["last"].(string); ok {
+		parseNumber(p.instId, "last", last, &position.LastPrice, p.tracef)
+	}
+	if markPx, ok := data["markPx"].(string); ok {
+		parseNumber(p.instId, "markPx", markPx, &position.CurrentPrice, p.tracef)
+	} else {
+		position.CurrentPrice = position.LastPrice
+		position.PriceIsLast = position.LastPrice > 0
+	}
+
+	// Parse PnL fields - prioritize actual OKX data over calculations
+	pnlFound := false
+	if upl, ok := data["upl"].(string); ok && upl != "0" && parseNumber(p.instId, "upl", upl, &position.PnL, p.tracef) {
+		pnlFound = true
+		p.tracef(debugPnL, "Using UPL (unrealized PnL): %s = %.4f", upl, position.PnL)
+	} else if pnl, ok := data["pnl"].(string); ok && pnl != "0" && parseNumber(p.instId, "pnl", pnl, &position.PnL, p.tracef) {
+		// Fallback to 'pnl' for realized PnL or other data
+		pnlFound = true
+		p.tracef(debugPnL, "Using PNL (realized PnL): %s = %.4f", pnl, position.PnL)
+	}
+
+	// Only calculate mock PnL if no real PnL data is available and we have valid prices
+	if !pnlFound && !unknownSide && position.CurrentPrice > 0 && position.AvgPrice > 0 && position.Size != 0 {
+		if position.PositionSide == "net" {
+			// Net-mode size is signed, negative for short exposure
+			position.PnL = (position.CurrentPrice - position.AvgPrice) * position.Size
+		} else if position.PositionSide == "short" {
+			// For short positions, profit when price goes down
+			position.PnL = (position.AvgPrice - position.CurrentPrice) * position.Size
+		} else {
+			// For long positions, profit when price goes up
+			position.PnL = (position.CurrentPrice - position.AvgPrice) * position.Size
+		}
+		p.tracef(debugPnL, "Calculated PnL for %s: %.4f (avg: %.4f, current: %.4f, size: %.4f)",
+			position.PositionSide, position.PnL, position.AvgPrice, position.CurrentPrice, position.Size)
+	}
+
+	// Leverage, assumed where OKX reports none so estimates stay in proportion
+	if lever, ok := data["lever"].(string); !ok || !parseNumber(p.instId, "lever", lever, &position.Leverage, p.tracef) || position.Leverage <= 0 {
+		position.Leverage = defaultLeverage
+		if leverage, ok := leverageOverrides[position.InstrumentID]; ok {
+			position.Leverage = leverage
+		}
+		position.LeverageAssumed = true
+	}
+
+	// Parse PnL ratio - prioritize actual OKX data
+	ratioFound := false
+	if uplRatio, ok := data["uplRatio"].(string); ok && uplRatio != "0" && parseNumber(p.instId, "uplRatio", uplRatio, &position.PnLRatio, p.tracef) {
+		position.PnLRatio *= 100 // Convert from decimal to percentage
+		ratioFound = true
+		p.tracef(debugPnL, "Using UPL Ratio: %s = %.2f%%", uplRatio, position.PnLRatio)
+	} else if pnlRatio, ok := data["pnlRatio"].(string); ok && pnlRatio != "0" && parseNumber(p.instId, "pnlRatio", pnlRatio, &position.PnLRatio, p.tracef) {
+		// Fallback to 'pnlRatio' for other data
+		position.PnLRatio *= 100 // Convert from decimal to percentage
+		ratioFound = true
+		p.tracef(debugPnL, "Using PNL Ratio: %s = %.2f%%", pnlRatio, position.PnLRatio)
+	}
+
+	// Only calculate ratio if no real ratio data is available and we have valid data
+	if !ratioFound && position.AvgPrice > 0 && position.Size != 0 {
+		position.PnLRatio = estimatePnLRatio(position)
+		position.PnLRatioEstimated = true
+		p.tracef(debugPnL, "Calculated PnL ratio at %.0fx: %.2f%%", position.Leverage, position.PnLRatio)
+	}
+
+	// Parse margin ratio - OKX reports it as a decimal
+	if mgnRatio, ok := data["mgnRatio"].(string); ok && parseNumber(p.instId, "mgnRatio", mgnRatio, &position.MarginRatio, p.tracef) {
+		position.MarginRatio *= 100 // Convert from decimal to percentage
+	}
+
+	if realizedPnl, ok := data["realizedPnl"].(string); ok {
+		parseNumber(p.instId, "realizedPnl", realizedPnl, &position.RealizedPnL, p.tracef)
+	}
+
+	if liqPx, ok := data["liqPx"].(string); ok {
+		parseNumber(p.instId, "liqPx", liqPx, &position.LiqPrice, p.tracef)
+	}
+
+	// Margin currency - 'mgnCcy', or 'ccy' where OKX only reports that
+	position.MarginCurrency = getString(data, "mgnCcy")
+	if position.MarginCurrency == "" {
+		position.MarginCurrency = getString(data, "ccy")
+	}
+
+	// Parse margin - 'imr' for cross, 'margin' for isolated positions, estimated without either
+	imr, margin := getString(data, "imr"), getString(data, "margin")
+	if !(imr != "0" && parseNumber(p.instId, "imr", imr, &position.Margin, p.tracef)) &&
+		!(margin != "0" && parseNumber(p.instId, "margin", margin, &position.Margin, p.tracef)) {
+		position.Margin = estimateMargin(position)
+		position.MarginEstimated = true
+	}
+
+	return position, p.traces
+}
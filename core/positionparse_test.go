@@ -0,0 +1,172 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// positionsMatch compares positions field by field, floats within rounding
+func positionsMatch(got, want PositionData) bool {
+	gv, wv := reflect.ValueOf(got), reflect.ValueOf(want)
+	for i := 0; i < gv.NumField(); i++ {
+		g, w := gv.Field(i), wv.Field(i)
+		if g.Kind() == reflect.Float64 {
+			if math.Abs(g.Float()-w.Float()) > 1e-9 {
+				return false
+			}
+			continue
+		}
+		if g.Interface() != w.Interface() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		want      PositionData
+		wantTrace string // Substring one of the debug traces must contain
+	}{
+		{
+			name: "long position with OKX PnL",
+			data: map[string]interface{}{
+				"instId": "BTC-USDT-SWAP", "posSide": "long", "pos": "2", "avgPx": "50000",
+				"markPx": "51000", "last": "51010", "upl": "2000", "uplRatio": "0.4", "lever": "20",
+				"mgnRatio": "1.5", "imr": "5100", "liqPx": "47600", "mgnCcy": "USDT",
+				"realizedPnl": "-3.5", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "BTC-USDT-SWAP", PositionSide: "long", Size: 2, AvgPrice: 50000,
+				CurrentPrice: 51000, LastPrice: 51010, PnL: 2000, PnLRatio: 40, Leverage: 20,
+				Margin: 5100, MarginRatio: 150, RealizedPnL: -3.5, LiqPrice: 47600,
+				MarginCurrency: "USDT", Timestamp: 1700000000000,
+			},
+			wantTrace: "Using UPL (unrealized PnL)",
+		},
+		{
+			name: "short position without OKX PnL",
+			data: map[string]interface{}{
+				"instId": "ETH-USDT-SWAP", "posSide": "short", "pos": "3", "avgPx": "3000",
+				"markPx": "2940", "lever": "10", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "ETH-USDT-SWAP", PositionSide: "short", Size: 3, AvgPrice: 3000,
+				CurrentPrice: 2940, PnL: 180, PnLRatio: 20, PnLRatioEstimated: true, Leverage: 10,
+				Margin: 882, MarginEstimated: true, Timestamp: 1700000000000,
+			},
+			wantTrace: "Calculated PnL for short: 180.0000",
+		},
+		{
+			name: "long position without OKX PnL",
+			data: map[string]interface{}{
+				"instId": "SOL-USDT-SWAP", "posSide": "long", "pos": "10", "avgPx": "100",
+				"markPx": "95", "lever": "5", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "SOL-USDT-SWAP", PositionSide: "long", Size: 10, AvgPrice: 100,
+				CurrentPrice: 95, PnL: -50, PnLRatio: -25, PnLRatioEstimated: true, Leverage: 5,
+				Margin: 190, MarginEstimated: true, Timestamp: 1700000000000,
+			},
+			wantTrace: "Calculated PnL for long: -50.0000",
+		},
+		{
+			name: "net mode short with signed size",
+			data: map[string]interface{}{
+				"instId": "BTC-USDT-SWAP", "posSide": "net", "pos": "-1", "avgPx": "50000",
+				"markPx": "49000", "lever": "10", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "BTC-USDT-SWAP", PositionSide: "net", Size: -1, AvgPrice: 50000,
+				CurrentPrice: 49000, PnL: 1000, PnLRatio: 20, PnLRatioEstimated: true, Leverage: 10,
+				Margin: 4900, MarginEstimated: true, Timestamp: 1700000000000,
+			},
+			wantTrace: "Calculated PnL for net: 1000.0000",
+		},
+		{
+			name: "ticker payload",
+			data: map[string]interface{}{
+				"instId": "BTC-USDT-SWAP", "last": "50000", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "BTC-USDT-SWAP", PositionSide: "long", Size: 1, AvgPrice: 50000,
+				CurrentPrice: 50000, LastPrice: 50000, PriceIsLast: true, PnLRatioEstimated: true,
+				Leverage: 1, LeverageAssumed: true, Margin: 50000, MarginEstimated: true,
+				Timestamp: 1700000000000,
+			},
+		},
+		{
+			name: "missing fields",
+			data: map[string]interface{}{
+				"instId": "XRP-USDT-SWAP", "posSide": "long", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "XRP-USDT-SWAP", PositionSide: "long", Size: 1, Leverage: 1,
+				LeverageAssumed: true, MarginEstimated: true, Timestamp: 1700000000000,
+			},
+		},
+		{
+			name: "malformed numbers are ignored",
+			data: map[string]interface{}{
+				"instId": "ETH-USDT-SWAP", "posSide": "short", "pos": "abc", "avgPx": "3000",
+				"markPx": "n/a", "lever": "10", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "ETH-USDT-SWAP", PositionSide: "short", AvgPrice: 3000, Leverage: 10,
+				MarginEstimated: true, Timestamp: 1700000000000,
+			},
+			wantTrace: `Malformed ETH-USDT-SWAP markPx "n/a" from OKX`,
+		},
+		{
+			name: "unknown side gets no client-side PnL",
+			data: map[string]interface{}{
+				"instId": "BTC-USDT-SWAP", "posSide": "sideways", "pos": "1", "avgPx": "100",
+				"markPx": "110", "lever": "2", "ts": "1700000000000",
+			},
+			want: PositionData{
+				InstrumentID: "BTC-USDT-SWAP", PositionSide: "sideways", Size: 1, AvgPrice: 100,
+				CurrentPrice: 110, PnLRatioEstimated: true, Leverage: 2, Margin: 55,
+				MarginEstimated: true, Timestamp: 1700000000000,
+			},
+			wantTrace: `WARNING unknown posSide "sideways"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, traces := ParsePosition(tt.data)
+			if got.ReceivedAt == 0 {
+				t.Error("ReceivedAt not set")
+			}
+			got.ReceivedAt = 0
+			if !positionsMatch(got, tt.want) {
+				t.Errorf("ParsePosition() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			if tt.wantTrace != "" && !strings.Contains(strings.Join(traces, "\n"), tt.wantTrace) {
+				t.Errorf("traces %q do not mention %q", traces, tt.wantTrace)
+			}
+		})
+	}
+}
+
+func TestParsePositionMalformedSizeIsTraced(t *testing.T) {
+	_, traces := ParsePosition(map[string]interface{}{"instId": "BTC-USDT-SWAP", "posSide": "long", "pos": "1e"})
+	if len(traces) == 0 || !strings.Contains(traces[0], `Malformed BTC-USDT-SWAP pos "1e"`) {
+		t.Errorf("traces = %q, want the malformed size first", traces)
+	}
+}
+
+func TestParsePositionUsesExchangeTimestamp(t *testing.T) {
+	got, _ := ParsePosition(map[string]interface{}{"instId": "BTC-USDT-SWAP", "uTime": "1700000000123"})
+	if got.Timestamp != 1700000000123 {
+		t.Errorf("Timestamp = %d, want uTime", got.Timestamp)
+	}
+	got, _ = ParsePosition(map[string]interface{}{"instId": "BTC-USDT-SWAP"})
+	if got.Timestamp != got.ReceivedAt {
+		t.Errorf("Timestamp = %d without exchange time, want the receipt time %d", got.Timestamp, got.ReceivedAt)
+	}
+}
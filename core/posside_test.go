@@ -0,0 +1,51 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnknownPosSideWarnsOnErrorChannel(t *testing.T) {
+	c, _, _, errorCh := newTestDemoClient()
+	pos := c.parsePositionData(map[string]interface{}{
+		"instId": "BTC-USDT-SWAP", "posSide": "sideways", "pos": "1", "avgPx": "100", "markPx": "110",
+	})
+	if pos.PnL != 0 {
+		t.Errorf("PnL = %v for an unknown side, want none computed", pos.PnL)
+	}
+
+	var messages []string
+	for len(errorCh) > 0 {
+		messages = append(messages, <-errorCh)
+	}
+	if !strings.Contains(strings.Join(messages, "\n"), `WARNING unknown posSide "sideways" for BTC-USDT-SWAP`) {
+		t.Errorf("no posSide warning sent, got %q", messages)
+	}
+}
+
+func TestPositionSides(t *testing.T) {
+	tests := []struct {
+		side  string
+		size  float64
+		short bool
+	}{
+		{"long", 1, false},
+		{"short", 1, true},
+		{"net", 2, false},
+		{"net", -2, true},
+		{"sideways", -2, false},
+	}
+	for _, tt := range tests {
+		pos := PositionData{PositionSide: tt.side, Size: tt.size}
+		if pos.IsShort() != tt.short {
+			t.Errorf("%s size %v: IsShort() = %v, want %v", tt.side, tt.size, pos.IsShort(), tt.short)
+		}
+	}
+
+	// A net short tracks its instrument like any open position
+	c, _, _, _ := newTestDemoClient()
+	c.parsePositionData(map[string]interface{}{"instId": "ETH-USDT-SWAP", "posSide": "net", "pos": "-3", "avgPx": "3000"})
+	if got := c.trackedInstruments(); len(got) != 1 || got[0] != "ETH-USDT-SWAP" {
+		t.Errorf("tracked %v, want the net short", got)
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// SetMarkPriceFeed switches price updates from the ipublic tickers channel to the
+// standard public endpoint's mark-price channel. In demo mode the main public
+// connection carries the prices and no separate ticker socket is opened; with
+// credentials the main connection is private, so a standard public socket is
+// still used for market data.
+func (c *OKXClient) SetMarkPriceFeed(enabled bool) {
+	c.markPriceFeed = enabled
+}
+
+// priceChannel returns the channel subscribed to for position price updates
+func (c *OKXClient) priceChannel() string {
+	if c.markPriceFeed {
+		return "mark-price"
+	}
+	return "tickers"
+}
+
+// priceConn returns the connection carrying public market data and the mutex
+// guarding writes to it. The connection is nil until established. Either
+// connection is read under its mutex, as a reconnect or read error replaces it.
+func (c *OKXClient) priceConn() (*websocket.Conn, *sync.Mutex) {
+	if c.sharedPriceConn {
+		c.connMutex.Lock()
+		defer c.connMutex.Unlock()
+		return c.conn, &c.connMutex
+	}
+	c.tickerMutex.Lock()
+	defer c.tickerMutex.Unlock()
+	return c.tickerConn, &c.tickerMutex
+}
+
+// shareMainConnForPrices routes market data over the main public connection
+// instead of a separate ticker socket
+func (c *OKXClient) shareMainConnForPrices() {
+	c.sharedPriceConn = true
+
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+	for _, channel := range []string{"mark-price", "trades", "books5"} {
+		c.mainHandlers[channel] = c.tickerHandlers[channel]
+	}
+}
+
+// noteMarkPrice records that instId streams a mark price, so its last traded
+// price no longer overrides it
+func (c *OKXClient) noteMarkPrice(instId string) {
+	c.markPriceMutex.Lock()
+	defer c.markPriceMutex.Unlock()
+	if !c.markPriceSeen[instId] {
+		c.markPriceSeen[instId] = true
+		c.instDebugf(instId, debugTicker, "Mark price arrived for %s, preferring it over the last price", instId)
+	}
+}
+
+// hasMarkPrice reports whether instId has streamed a mark price on the
+// current ticker connection
+func (c *OKXClient) hasMarkPrice(instId string) bool {
+	c.markPriceMutex.Lock()
+	defer c.markPriceMutex.Unlock()
+	return c.markPriceSeen[instId]
+}
+
+// subscribedInstruments returns the instruments subscribed on the price
+// connection. The caller holds priceSubsMutex.
+func (c *OKXClient) subscribedInstruments() []string {
+	instIds := make([]string, 0, len(c.priceSubs))
+	for instId := range c.priceSubs {
+		instIds = append(instIds, instId)
+	}
+	sort.Strings(instIds)
+	return instIds
+}
+
+// resetPriceSubscriptions forgets the subscribed instruments, so the next
+// subscription update subscribes every watched instrument again
+func (c *OKXClient) resetPriceSubscriptions() {
+	c.priceSubsMutex.Lock()
+	defer c.priceSubsMutex.Unlock()
+	c.priceSubs = make(map[string]bool)
+}
+
+// resetMarkPrices forgets which instruments stream a mark price, so prices fall
+// back to the last trade until the mark price arrives again
+func (c *OKXClient) resetMarkPrices() {
+	c.markPriceMutex.Lock()
+	defer c.markPriceMutex.Unlock()
+	c.markPriceSeen = make(map[string]bool)
+}
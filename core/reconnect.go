@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConnState describes the state of the connection to OKX
+type ConnState int
+
+const (
+	ConnConnecting   ConnState = iota // First connection attempt in progress
+	ConnConnected                     // Connected and receiving data
+	ConnReconnecting                  // Connection lost, waiting to reconnect
+	ConnStopped                       // Gave up after an error retrying can't fix
+)
+
+const (
+	// defaultMinReconnectDelay is the first wait before reconnecting after a drop
+	defaultMinReconnectDelay = time.Second
+
+	// defaultMaxReconnectDelay caps the exponential reconnect backoff
+	defaultMaxReconnectDelay = 30 * time.Second
+
+	// stableConnection is how long a connection must last to reset the backoff
+	stableConnection = time.Minute
+)
+
+// SetReconnectPolicy sets the first wait before reconnecting after a drop and
+// the cap the wait doubles up to on repeated failures. Zero keeps a default.
+func (c *OKXClient) SetReconnectPolicy(minDelay, maxDelay time.Duration) {
+	if minDelay > 0 {
+		c.minReconnectDelay = minDelay
+	}
+	if maxDelay > 0 {
+		c.maxReconnectDelay = maxDelay
+	}
+}
+
+// SetStatusChannel sets the channel connection state changes are sent to
+func (c *OKXClient) SetStatusChannel(statusCh chan<- ConnState) {
+	c.statusCh = statusCh
+}
+
+// setStatus reports a connection state change without blocking
+func (c *OKXClient) setStatus(state ConnState) {
+	if c.statusCh == nil {
+		return
+	}
+	select {
+	case c.statusCh <- state:
+	default:
+	}
+}
+
+// RunWithReconnect connects and listens until the connection drops, then
+// reconnects with exponential backoff. Position tracking, demo positions and
+// the selected order book carry over so the fresh data merges with what the UI
+// already shows. It returns when the client's context is cancelled, or after
+// an OKX error classified as fatal, e.g. rejected credentials, which it reports
+// as a warning.
+func (c *OKXClient) RunWithReconnect() {
+	delay := c.minReconnectDelay
+	c.setStatus(ConnConnecting)
+
+	for {
+		// Acknowledgements still owed on a dropped connection never arrive
+		c.subAcksPending.Store(0)
+
+		if err := c.Connect(); err != nil {
+			if c.stopping() {
+				c.Close()
+				return
+			}
+			c.sendError(fmt.Sprintf("Failed to connect to OKX: %v", err))
+		} else {
+			c.setStatus(ConnConnected)
+			c.resubscribeBook()
+
+			connectedAt := time.Now()
+			c.StartListening()
+			c.Close()
+
+			if c.stopping() {
+				return
+			}
+
+			if c.fatalErr != "" {
+				c.setStatus(ConnStopped)
+				c.sendError(fmt.Sprintf("WARN: Stopped reconnecting to OKX after %s", c.fatalErr))
+				return
+			}
+
+			// A connection that held up for a while starts the backoff over
+			if time.Since(connectedAt) >= stableConnection {
+				delay = c.minReconnectDelay
+			}
+		}
+
+		c.setStatus(ConnReconnecting)
+		c.sendError(fmt.Sprintf("Connection to OKX lost, reconnecting in %s", delay))
+		select {
+		case <-time.After(delay):
+		case <-c.ctx.Done():
+			return
+		}
+
+		delay *= 2
+		if delay > c.maxReconnectDelay {
+			delay = c.maxReconnectDelay
+		}
+	}
+}
+
+// resubscribeBook restores the order book subscription on a new connection
+func (c *OKXClient) resubscribeBook() {
+	if c.paused.Load() {
+		return
+	}
+
+	c.bookMutex.Lock()
+	instId := c.bookInstrument
+	c.bookMutex.Unlock()
+
+	if instId == "" {
+		return
+	}
+	if err := c.sendBookOp("subscribe", instId); err != nil {
+		c.sendError(fmt.Sprintf("Failed to subscribe order book: %v", err))
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"runtime/debug"
+)
+
+// PanicHandler receives a panic recovered on one of the client's goroutines
+// along with its stack trace. It is expected not to return, e.g. to restore
+// the terminal, log the crash and exit.
+type PanicHandler func(r interface{}, stack []byte)
+
+// SetPanicHandler hands panics on the client's background goroutines (the
+// ticker listener, heartbeats, the ticker flusher and the pollers) to handler,
+// rather than crashing with the terminal left in raw mode. Without one they
+// crash the process as usual. RunWithReconnect and the Watch* methods run on
+// the caller's goroutines, which recover their own panics.
+func (c *OKXClient) SetPanicHandler(handler PanicHandler) {
+	c.panicHandler = handler
+}
+
+// goSafe runs fn on a new goroutine, handing a panic in it to the panic handler
+func (c *OKXClient) goSafe(fn func()) {
+	go func() {
+		defer c.recoverPanic()
+		fn()
+	}()
+}
+
+// recoverPanic hands a panic to the panic handler when one is set. It must be
+// deferred directly, for recover to see the panic.
+func (c *OKXClient) recoverPanic() {
+	if c.panicHandler == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		c.panicHandler(r, debug.Stack())
+	}
+}
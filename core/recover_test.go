@@ -0,0 +1,33 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoSafeHandsPanicsToHandler(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	type crash struct {
+		r     interface{}
+		stack string
+	}
+	crashes := make(chan crash, 1)
+	c.SetPanicHandler(func(r interface{}, stack []byte) {
+		crashes <- crash{r, string(stack)}
+	})
+
+	c.goSafe(func() { panic("flusher broke") })
+
+	select {
+	case got := <-crashes:
+		if got.r != "flusher broke" {
+			t.Errorf("recovered %v, want the panic value", got.r)
+		}
+		if !strings.Contains(got.stack, "TestGoSafeHandsPanicsToHandler") {
+			t.Errorf("stack trace does not show where it panicked:\n%s", got.stack)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic never reached the handler")
+	}
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// closeGracePeriod bounds how long a close frame may take to send on shutdown
+const closeGracePeriod = time.Second
+
+// stopping reports whether the client's context has been cancelled
+func (c *OKXClient) stopping() bool {
+	return c.ctx.Err() != nil
+}
+
+// closeOnCancel closes conn once the client's context is cancelled, which ends
+// its listener's blocking read. The close happens under the connection's write
+// mutex, so writers that check stopping under the same mutex never write to
+// the closed connection. It returns early once done is closed, when the
+// listener has exited by itself.
+func (c *OKXClient) closeOnCancel(conn *websocket.Conn, mu *sync.Mutex, done <-chan struct{}) {
+	select {
+	case <-c.ctx.Done():
+		mu.Lock()
+		defer mu.Unlock()
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeGracePeriod))
+		conn.Close()
+	case <-done:
+	}
+}
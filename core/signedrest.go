@@ -0,0 +1,57 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// restFetchTimeout bounds each signed REST request
+const restFetchTimeout = 10 * time.Second
+
+// signedGet requests requestPath, including any query string, from the signed
+// REST API with the client's credentials and returns the response data
+func (c *OKXClient) signedGet(requestPath string) ([]map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, strings.TrimSuffix(c.restURL, "/")+requestPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	h := hmac.New(sha256.New, []byte(c.secretKey))
+	h.Write([]byte(timestamp + http.MethodGet + requestPath))
+
+	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
+	req.Header.Set("OK-ACCESS-SIGN", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", c.passphrase)
+	for key, values := range c.handshakeHeader() {
+		req.Header[key] = values
+	}
+
+	client := c.httpClient(restFetchTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Code string                   `json:"code"`
+		Msg  string                   `json:"msg"`
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode response: %v", err)
+	}
+	if body.Code != "0" {
+		return nil, errors.New(describeError(body.Code, body.Msg))
+	}
+	return body.Data, nil
+}
@@ -0,0 +1,20 @@
+package core
+
+import "net/http"
+
+// SetSimulatedTrading connects to OKX's demo-trading (paper) environment by
+// sending the x-simulated-trading header on every WebSocket handshake, so demo
+// API keys authenticate and their paper positions stream like live ones
+func (c *OKXClient) SetSimulatedTrading(enabled bool) {
+	c.simulatedTrading = enabled
+}
+
+// handshakeHeader returns the headers sent when dialing OKX, nil when none
+func (c *OKXClient) handshakeHeader() http.Header {
+	if !c.simulatedTrading {
+		return nil
+	}
+	header := http.Header{}
+	header.Set("x-simulated-trading", "1")
+	return header
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPriceSubscriptionsFollowPositions(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	c.isDemo = false
+	position := func(instId, size string) {
+		c.parsePositionData(map[string]interface{}{"instId": instId, "posSide": "long", "pos": size, "avgPx": "100"})
+	}
+
+	// Positions seen before the ticker connection are subscribed together
+	position("BTC-USDT-SWAP", "1")
+	position("ETH-USDT-SWAP", "1")
+	conn, received := recordingConn(t)
+	c.tickerConn = conn
+	if err := c.updateTickerSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	sent := 0
+	step := func(name, wantSub, wantUnsub string) {
+		t.Helper()
+		msgs := sentSince(t, conn, received, sent)
+		sent += len(msgs) + 1
+		if got := fmt.Sprint(tickerInstruments(msgs, "subscribe")); got != wantSub {
+			t.Errorf("%s subscribed %s, want %s", name, got, wantSub)
+		}
+		if got := fmt.Sprint(tickerInstruments(msgs, "unsubscribe")); got != wantUnsub {
+			t.Errorf("%s unsubscribed %s, want %s", name, got, wantUnsub)
+		}
+	}
+	step("first update", "[BTC-USDT-SWAP ETH-USDT-SWAP]", "[]")
+
+	position("BTC-USDT-SWAP", "0")
+	step("closing BTC", "[]", "[BTC-USDT-SWAP]")
+
+	position("SOL-USDT-SWAP", "1")
+	step("opening SOL", "[SOL-USDT-SWAP]", "[]")
+
+	if err := c.updateTickerSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	step("an update without changes", "[]", "[]")
+
+	c.priceSubsMutex.Lock()
+	subs := c.subscribedInstruments()
+	c.priceSubsMutex.Unlock()
+	if fmt.Sprint(subs) != "[ETH-USDT-SWAP SOL-USDT-SWAP]" {
+		t.Errorf("subscribed set = %v, want ETH and SOL", subs)
+	}
+
+	// Pause drops exactly what is subscribed, so resume starts afresh
+	if err := c.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	step("pausing", "[]", "[ETH-USDT-SWAP SOL-USDT-SWAP]")
+	if len(c.priceSubs) != 0 {
+		t.Errorf("subscribed set = %v after pausing, want it empty", c.priceSubs)
+	}
+}
+
+func TestResetPriceSubscriptions(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	c.priceSubs["BTC-USDT-SWAP"] = true
+	c.resetPriceSubscriptions()
+	if len(c.priceSubs) != 0 {
+		t.Errorf("subscribed set = %v after a reset, want it empty for the new connection", c.priceSubs)
+	}
+}
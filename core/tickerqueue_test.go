@@ -0,0 +1,54 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTickerSubscriptionsQueueWithoutConnection(t *testing.T) {
+	c, _, _, errorCh := newTestDemoClient()
+	c.isDemo = false
+
+	for _, instId := range []string{"BTC-USDT-SWAP", "ETH-USDT-SWAP", "SOL-USDT-SWAP"} {
+		c.parsePositionData(map[string]interface{}{"instId": instId, "posSide": "long", "pos": "1", "avgPx": "100"})
+	}
+
+	// One notice for the queue instead of an error per position
+	var queued, failed int
+	for len(errorCh) > 0 {
+		msg := <-errorCh
+		if strings.Contains(msg, "No ticker connection") {
+			queued++
+		}
+		if strings.Contains(msg, "Failed to update ticker subscriptions") {
+			failed++
+		}
+	}
+	if queued != 1 || failed != 0 {
+		t.Errorf("%d queue notices and %d errors for 3 positions, want 1 and 0", queued, failed)
+	}
+	if !c.tickerSubsPending.Load() {
+		t.Fatal("subscriptions not queued")
+	}
+
+	// Once the price connection is back the queue is flushed in one go
+	c.tickerConn = dialTestConn(t)
+	if err := c.updateTickerSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	if c.tickerSubsPending.Load() {
+		t.Error("queue still pending after the flush")
+	}
+	for _, instId := range c.trackedInstruments() {
+		if !c.priceSubs[instId] {
+			t.Errorf("%s not subscribed after the flush", instId)
+		}
+	}
+	var flushed bool
+	for len(errorCh) > 0 {
+		flushed = flushed || strings.Contains(<-errorCh, "Flushed ticker subscriptions queued while disconnected")
+	}
+	if !flushed {
+		t.Error("flush not reported")
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+// SetTickerChannel sets the channel full ticker stats (bid, ask and 24h
+// volume) are sent to. They come from the tickers channel only, so the
+// mark-price feed sends none.
+func (c *OKXClient) SetTickerChannel(tickerCh chan<- TickerData) {
+	c.tickerCh = tickerCh
+}
+
+// parseTickerStats parses a tickers channel item into TickerData, reporting
+// false for items without a last price such as mark prices
+func (c *OKXClient) parseTickerStats(instId string, data map[string]interface{}) (TickerData, bool) {
+	ticker := TickerData{InstrumentID: instId}
+	if !c.parseNumber(instId, "last", getString(data, "last"), &ticker.LastPrice) {
+		return ticker, false
+	}
+	c.parseNumber(instId, "bidPx", getString(data, "bidPx"), &ticker.BidPrice)
+	c.parseNumber(instId, "askPx", getString(data, "askPx"), &ticker.AskPrice)
+	c.parseNumber(instId, "vol24h", getString(data, "vol24h"), &ticker.Volume24h)
+	ticker.Timestamp = exchangeTimestamp(data, nowMillis())
+	return ticker, true
+}
+
+// sendTickerStats forwards ticker stats to the ticker channel, dropping them
+// rather than block the listener when the UI falls behind
+func (c *OKXClient) sendTickerStats(ticker TickerData) {
+	if c.tickerCh == nil {
+		return
+	}
+	select {
+	case c.tickerCh <- ticker:
+	default:
+	}
+}
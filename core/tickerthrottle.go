@@ -0,0 +1,86 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultTickerThrottle is how often coalesced ticker prices are flushed
+const defaultTickerThrottle = 250 * time.Millisecond
+
+// tickerUpdate is a parsed ticker price waiting to be applied
+type tickerUpdate struct {
+	instId      string
+	price       float64     // Mark price, or the last price until a mark price arrives; 0 for none
+	last        float64     // Last traded price, 0 for none
+	priceIsLast bool        // price is the last traded price standing in for the mark
+	stats       *TickerData // Full ticker stats, nil for none
+	exchangeTs  int64       // OKX event time in epoch ms
+	receivedAt  int64       // Local receipt time in epoch ms, kept from the latest message
+}
+
+// SetTickerThrottle coalesces ticker prices per instrument and flushes only the
+// latest one every d, so busy instruments don't redraw the UI on every tick.
+// The last price of a burst is always delivered on the next flush. 0 sends
+// every ticker update as it arrives.
+func (c *OKXClient) SetTickerThrottle(d time.Duration) {
+	c.tickerThrottle = d
+}
+
+// queueTicker holds a ticker price until the next flush, replacing any older
+// one of the same instrument, and starts the flusher on first use. Mark and
+// last prices arrive separately, so a price the update lacks is kept from the
+// older one.
+func (c *OKXClient) queueTicker(update tickerUpdate) {
+	c.pendingMutex.Lock()
+	if c.pendingTickers == nil {
+		c.pendingTickers = make(map[string]tickerUpdate)
+	}
+	if older, ok := c.pendingTickers[update.instId]; ok {
+		if update.price <= 0 {
+			update.price, update.priceIsLast = older.price, older.priceIsLast
+		}
+		if update.last <= 0 {
+			update.last = older.last
+		}
+		if update.stats == nil {
+			update.stats = older.stats
+		}
+	}
+	c.pendingTickers[update.instId] = update
+	c.pendingMutex.Unlock()
+
+	if c.flusherStarted.CompareAndSwap(false, true) {
+		c.goSafe(c.flushTickers)
+	}
+}
+
+// flushTickers applies the queued ticker prices every throttle interval until
+// the client shuts down. Demo positions it reprices are shared with the
+// reconnect path, which is why they are guarded by demoMutex.
+func (c *OKXClient) flushTickers() {
+	ticker := time.NewTicker(c.tickerThrottle)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.pendingMutex.Lock()
+		pending := c.pendingTickers
+		c.pendingTickers = nil
+		c.pendingMutex.Unlock()
+
+		// Apply in instrument order so updates arrive in a stable sequence
+		instIds := make([]string, 0, len(pending))
+		for instId := range pending {
+			instIds = append(instIds, instId)
+		}
+		sort.Strings(instIds)
+		for _, instId := range instIds {
+			c.applyTicker(pending[instId])
+		}
+	}
+}
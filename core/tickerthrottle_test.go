@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// drainClient reads a client's channels until ctx is done, forwarding
+// positions so tests can follow them
+func drainClient(ctx context.Context, positionCh <-chan PositionData, balanceCh <-chan BalanceData, errorCh <-chan string, positions chan<- PositionData) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case pos := <-positionCh:
+			select {
+			case positions <- pos:
+			default:
+			}
+		case <-balanceCh:
+		case <-errorCh:
+		}
+	}
+}
+
+// Run with -race: the flusher applies demo prices while the reconnect path
+// resends the demo positions and totals the demo balance
+func TestThrottledDemoTickersWithReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	positionCh := make(chan PositionData, 16)
+	balanceCh := make(chan BalanceData, 16)
+	errorCh := make(chan string, 16)
+	positions := make(chan PositionData, 1024)
+	go drainClient(ctx, positionCh, balanceCh, errorCh, positions)
+
+	c := NewOKXClientWithContext(ctx, positionCh, balanceCh, errorCh)
+	c.isDemo = true
+	c.SetTickerThrottle(time.Millisecond)
+	c.createDemoPositions()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			c.handleTickerData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "last": strconv.Itoa(45000 + i)})
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			c.createDemoPositions() // Resends the demo positions, as on reconnect
+			c.demoBalance()
+			time.Sleep(400 * time.Microsecond)
+		}
+	}()
+	wg.Wait()
+
+	// The last price of the burst is delivered on a later flush
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case pos := <-positions:
+			if pos.InstrumentID == "BTC-USDT-SWAP" && pos.CurrentPrice == 45199 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("last throttled price never delivered")
+		}
+	}
+}
+
+func TestTickerThrottleCoalescesPerInstrument(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	c.tickerThrottle = time.Hour // Never flushes on its own during the test
+	c.flusherStarted.Store(true)
+
+	c.handleTickerData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "markPx": "50000"})
+	c.handleTickerData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "last": "50010"})
+	c.handleTickerData(map[string]interface{}{"instId": "ETH-USDT-SWAP", "markPx": "3000"})
+	c.handleTickerData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "markPx": "50100"})
+
+	if len(c.pendingTickers) != 2 {
+		t.Fatalf("pending = %d instruments, want 2", len(c.pendingTickers))
+	}
+	btc := c.pendingTickers["BTC-USDT-SWAP"]
+	if btc.price != 50100 || btc.last != 50010 {
+		t.Errorf("BTC pending price %v last %v, want the latest mark 50100 with last 50010 kept", btc.price, btc.last)
+	}
+}
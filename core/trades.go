@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// TradeData represents a single public trade print
+type TradeData struct {
+	InstrumentID string  `json:"instId"`
+	TradeID      string  `json:"tradeId"`
+	Price        float64 `json:"px,string"`
+	Size         float64 `json:"sz,string"`
+	Side         string  `json:"side"`
+	Timestamp    int64   `json:"ts,string"`
+}
+
+// SetTradeChannel enables the public trades feed and sets the channel trades are sent to
+func (c *OKXClient) SetTradeChannel(tradeCh chan<- TradeData) {
+	c.tradeCh = tradeCh
+}
+
+// handleTradeData parses a trade print and forwards it to the trade channel
+func (c *OKXClient) handleTradeData(data map[string]interface{}) {
+	if c.tradeCh == nil {
+		return
+	}
+
+	trade := TradeData{
+		InstrumentID: getString(data, "instId"),
+		TradeID:      getString(data, "tradeId"),
+		Side:         getString(data, "side"),
+		Timestamp:    time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if trade.InstrumentID == "" {
+		return
+	}
+
+	if px, ok := data["px"].(string); ok {
+		fmt.Sscanf(px, "%f", &trade.Price)
+	}
+	if sz, ok := data["sz"].(string); ok {
+		fmt.Sscanf(sz, "%f", &trade.Size)
+	}
+	if ts, ok := data["ts"].(string); ok {
+		fmt.Sscanf(ts, "%d", &trade.Timestamp)
+	}
+
+	// Trades can be high-volume, drop prints rather than block the listener
+	select {
+	case c.tradeCh <- trade:
+	default:
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// recordingConn returns a WebSocket connection to a server that records the
+// JSON messages sent to it, closed when the test ends
+func recordingConn(t *testing.T) (*websocket.Conn, func() []map[string]interface{}) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg map[string]interface{}
+			if json.Unmarshal(message, &msg) == nil {
+				mu.Lock()
+				received = append(received, msg)
+				mu.Unlock()
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]interface{}(nil), received...)
+	}
+}
+
+func TestHeldFXInstrumentSubscribedOnce(t *testing.T) {
+	c, _, _, _ := newTestDemoClient()
+	c.isDemo = false
+	c.SetFXInstruments([]string{"USDC-USDT"})
+
+	// The rate's instrument is also held
+	c.parsePositionData(map[string]interface{}{"instId": "USDC-USDT", "posSide": "long", "pos": "100", "avgPx": "1"})
+	c.parsePositionData(map[string]interface{}{"instId": "BTC-USDT-SWAP", "posSide": "long", "pos": "1", "avgPx": "50000"})
+
+	conn, received := recordingConn(t)
+	c.tickerConn = conn
+	if err := c.updateTickerSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+	// A second pass finds nothing left to subscribe
+	if err := c.updateTickerSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything sent before the marker has arrived once it has
+	if err := conn.WriteJSON(map[string]string{"op": "marker"}); err != nil {
+		t.Fatal(err)
+	}
+	var msgs []map[string]interface{}
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if msgs = received(); len(msgs) > 0 && msgs[len(msgs)-1]["op"] == "marker" {
+			break
+		}
+	}
+
+	subs := make(map[string]int)
+	for _, msg := range msgs {
+		if msg["op"] != "subscribe" {
+			continue
+		}
+		args, _ := msg["args"].([]interface{})
+		for _, arg := range args {
+			arg, _ := arg.(map[string]interface{})
+			if arg["channel"] == "tickers" {
+				subs[arg["instId"].(string)]++
+			}
+		}
+	}
+	if subs["USDC-USDT"] != 1 || subs["BTC-USDT-SWAP"] != 1 || len(subs) != 2 {
+		t.Errorf("ticker subscriptions %v, want one each for USDC-USDT and BTC-USDT-SWAP", subs)
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/config"
 	"github.com/gandol/okx-tui-monitor/core"
+	"github.com/gandol/okx-tui-monitor/store"
 	"github.com/gandol/okx-tui-monitor/ui"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/joho/godotenv"
 )
 
@@ -40,73 +49,832 @@ func validateAPICredentials(apiKey, secretKey, passphrase string) bool {
 	return true
 }
 
+// envOr returns the environment variable key, or fallback when it is unset or empty
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// accountCredentials is the API credentials of one OKX account or subaccount
+type accountCredentials struct {
+	label      string // Name shown in the header, "" with a single account
+	apiKey     string
+	secretKey  string
+	passphrase string
+}
+
+// loadAccounts reads numbered credential sets OKX_API_KEY_1, OKX_API_SECRET_1,
+// OKX_API_PASSPHRASE_1 and an optional OKX_ACCOUNT_NAME_1, then _2 and so on
+// until a number has no API key. Invalid sets are skipped with a warning.
+func loadAccounts() (accounts []accountCredentials, warnings []string) {
+	for i := 1; ; i++ {
+		apiKey := os.Getenv(fmt.Sprintf("OKX_API_KEY_%d", i))
+		if apiKey == "" {
+			return accounts, warnings
+		}
+
+		account := accountCredentials{
+			label:      os.Getenv(fmt.Sprintf("OKX_ACCOUNT_NAME_%d", i)),
+			apiKey:     apiKey,
+			secretKey:  os.Getenv(fmt.Sprintf("OKX_API_SECRET_%d", i)),
+			passphrase: os.Getenv(fmt.Sprintf("OKX_API_PASSPHRASE_%d", i)),
+		}
+		if account.label == "" {
+			account.label = fmt.Sprintf("Account %d", i)
+		}
+		if !validateAPICredentials(account.apiKey, account.secretKey, account.passphrase) {
+			warnings = append(warnings, fmt.Sprintf("WARN: Skipping %s: invalid or incomplete OKX_*_%d credentials", account.label, i))
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+}
+
+// fanOutPauseRequests forwards every pause request to n channels, one per
+// client, so all accounts pause and resume together
+func fanOutPauseRequests(reqCh <-chan bool, n int) []chan bool {
+	outs := make([]chan bool, n)
+	for i := range outs {
+		outs[i] = make(chan bool, 1)
+	}
+	go func() {
+		for req := range reqCh {
+			for _, out := range outs {
+				out <- req
+			}
+		}
+	}()
+	return outs
+}
+
+// warnUnknownInstruments checks the instruments given per option against OKX
+// instrument metadata and sends a warning for each option naming unknown ones
+func warnUnknownInstruments(restClient *http.Client, errorCh chan<- string, instIdsByOption map[string][]string) {
+	known, err := core.FetchInstrumentIDs(restClient, core.DefaultRESTURL, "SWAP", "FUTURES")
+	if err != nil {
+		errorCh <- fmt.Sprintf("DEBUG: Skipping instrument validation: %v", err)
+		return
+	}
+
+	for option, instIds := range instIdsByOption {
+		if unknown := core.UnknownInstruments(known, instIds); len(unknown) > 0 {
+			errorCh <- fmt.Sprintf("WARN: %s names instruments OKX doesn't list as SWAP or FUTURES: %s", option, strings.Join(unknown, ", "))
+		}
+	}
+}
+
+// recoverProgram handles a panic on the goroutine it is deferred on: it
+// restores the terminal, writes the panic and stack trace to a crash log and
+// exits non-zero. It is deferred around program.Run and on every goroutine main
+// starts, and the clients hand it the panics on theirs. Commands the UI runs
+// on Bubble Tea's goroutines are not covered. program is nil when headless.
+func recoverProgram(program *tea.Program) {
+	if r := recover(); r != nil {
+		crash(program, r, debug.Stack())
+	}
+}
+
+// crash restores the terminal, writes the panic r and its stack trace to a
+// crash log and exits non-zero
+func crash(program *tea.Program, r interface{}, stack []byte) {
+	// Leave the alternate screen and raw mode before printing anything
+	if program != nil {
+		_ = program.ReleaseTerminal()
+	}
+
+	report := fmt.Sprintf("okx-tui-monitor crashed at %s: %v\n\n%s", time.Now().Format(time.RFC3339), r, stack)
+	path := fmt.Sprintf("okx-monitor-crash-%s.log", time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(path, []byte(report), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\nCould not write crash log: %v\n", report, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "okx-tui-monitor crashed: %v\nStack trace written to %s\n", r, path)
+	}
+	os.Exit(2)
+}
+
+// clientShutdownTimeout bounds how long main waits for the client to stop
+const clientShutdownTimeout = 2 * time.Second
+
+// drainClient discards client output the stopped UI no longer reads, so sends
+// blocked on full channels can't keep the client from shutting down, until it
+// has stopped or clientShutdownTimeout passes
+func drainClient(done <-chan struct{}, positionCh <-chan core.PositionData, balanceCh <-chan core.BalanceData,
+	errorCh <-chan string, tradeCh <-chan core.TradeData, bookCh <-chan core.BookData, tickerCh <-chan core.TickerData,
+	closedCh <-chan core.ClosedHistory) {
+	timeout := time.After(clientShutdownTimeout)
+	for {
+		select {
+		case <-done:
+			return
+		case <-timeout:
+			return
+		case <-positionCh:
+		case <-balanceCh:
+		case <-errorCh:
+		case <-tradeCh:
+		case <-bookCh:
+		case <-tickerCh:
+		case <-closedCh:
+		}
+	}
+}
+
 func main() {
 	// Parse command line flags
 	var debugMode bool
 	flag.BoolVar(&debugMode, "debug", false, "Enable debug mode")
 	flag.BoolVar(&debugMode, "d", false, "Enable debug mode (shorthand)")
+	var showTrades bool
+	flag.BoolVar(&showTrades, "trades", false, "Enable the recent trades feed for watched instruments (high-volume)")
+	var lossAlertPct float64
+	flag.Float64Var(&lossAlertPct, "loss-alert", 0, "Alert when a position's PnL % drops below -N (0 disables)")
+	var gainAlertPct, alertHysteresis float64
+	flag.Float64Var(&gainAlertPct, "gain-alert", 0, "Alert when a position's PnL % rises above N (0 disables)")
+	flag.Float64Var(&alertHysteresis, "alert-hysteresis", 1, "PnL % points a position must move back past a loss or gain threshold before it alerts again (negative alerts on every crossing)")
+	var alertSelect bool
+	flag.BoolVar(&alertSelect, "alert-select", false, "Auto-select the worst-PnL position when an alert fires")
+	var demoRandom bool
+	flag.BoolVar(&demoRandom, "demo-random", false, "Randomize demo positions instead of the fixed set")
+	var demoSeed int64
+	flag.Int64Var(&demoSeed, "demo-seed", 0, "Seed for demo randomization (0 uses the current time)")
+	var dbPath string
+	flag.StringVar(&dbPath, "db", "", "Persist position and balance snapshots to a SQLite database")
+	var updateLogPath string
+	flag.StringVar(&updateLogPath, "log", "", "Append every position and balance update to this JSONL file")
+	var timelapsePath string
+	flag.StringVar(&timelapsePath, "timelapse", "", "Replay snapshots from a SQLite database instead of connecting to OKX")
+	var timelapseSpeed float64
+	flag.Float64Var(&timelapseSpeed, "timelapse-speed", 60, "Timelapse playback speed multiplier")
+	var equityWindow string
+	flag.StringVar(&equityWindow, "equity-window", "session", "Equity history window to display (e.g. 5m, 1h, session)")
+	var equityBucket time.Duration
+	flag.DurationVar(&equityBucket, "equity-bucket", 10*time.Second, "Equity history aggregation bucket width")
+	var pairHedges bool
+	flag.BoolVar(&pairHedges, "pair-hedges", false, "Show long and short legs of the same instrument as one card")
+	var marginAlertPct float64
+	flag.Float64Var(&marginAlertPct, "margin-alert", 150, "Alert when a position's margin ratio drops below N% (0 disables)")
+	var liqAlertPct float64
+	flag.Float64Var(&liqAlertPct, "liq-alert", 0, "Alert when a position comes within N% of its liquidation price (0 disables)")
+	var alertWebhook string
+	flag.StringVar(&alertWebhook, "alert-webhook", "", "Post alerts as JSON to this URL")
+	var alertDesktop bool
+	flag.BoolVar(&alertDesktop, "alert-desktop", false, "Also show alerts as desktop notifications (notify-send on Linux, osascript on macOS)")
+	var alertSound, alertSoundPlayer string
+	flag.StringVar(&alertSound, "alert-sound", "", "Play this audio file on alerts, and on new positions whose -open-alert-* includes sound")
+	flag.StringVar(&alertSoundPlayer, "alert-sound-player", "auto", "Command playing -alert-sound, {file} marks the file (auto: afplay on macOS, paplay/aplay/ffplay on Linux, PowerShell on Windows)")
+	var openAlertLong, openAlertShort string
+	flag.StringVar(&openAlertLong, "open-alert-long", "", "Announce new long positions: bell, bell:N (ring N times), sound (play -alert-sound), desktop and/or a webhook URL, comma-separated")
+	flag.StringVar(&openAlertShort, "open-alert-short", "", "Announce new short positions, like -open-alert-long")
+	var openAlertDebounce time.Duration
+	flag.DurationVar(&openAlertDebounce, "open-alert-debounce", 10*time.Second, "Least time between new-position alerts on one side, so rapid fills alert once")
+	var pauseUnfocused bool
+	flag.BoolVar(&pauseUnfocused, "pause-unfocused", true, "Throttle the clock and redraws while the terminal is unfocused")
+	var priceSpread bool
+	flag.BoolVar(&priceSpread, "price-spread", true, "Show entry, mark and last prices with the last-vs-mark basis in the detail view")
+	var pnlSparkline bool
+	flag.BoolVar(&pnlSparkline, "pnl-sparkline", true, "Show a sparkline of each position's last 30 PnL values on its card")
+	var showTimestamps bool
+	flag.BoolVar(&showTimestamps, "show-timestamps", false, "Show raw OKX timestamps and receipt latency in the detail view")
+	var showMaxLeverage bool
+	flag.BoolVar(&showMaxLeverage, "max-leverage", false, "Show each position's leverage against OKX's maximum in the detail view (toggle with M)")
+	var sortModeName string
+	flag.StringVar(&sortModeName, "sort", "instrument", "Initial card order: instrument, latency (stalest first), pnl, pnl_pct or size (largest first)")
+	var staleAfter time.Duration
+	flag.DurationVar(&staleAfter, "stale-after", 10*time.Second, "Time without updates before an instrument counts as stale")
+	var staleGrace time.Duration
+	flag.DurationVar(&staleGrace, "stale-grace", 2*time.Second, "Extra wait before a card is marked stale, so a single missed frame doesn't flicker it (shorter than -stale-after)")
+	var debugWidth int
+	flag.IntVar(&debugWidth, "debug-width", 0, "Truncate debug lines to N characters (0 fits the terminal width)")
+	var noAltScreen bool
+	flag.BoolVar(&noAltScreen, "no-altscreen", false, "Render inline instead of on the alternate screen, keeping output in scrollback")
+	var debugStderr bool
+	flag.BoolVar(&debugStderr, "debug-stderr", false, "Also write debug messages to stderr (requires -no-altscreen or -headless)")
+	var headless bool
+	flag.BoolVar(&headless, "headless", false, "Run without a terminal UI as an alert monitor: alerts go to stdout, errors to stderr, plus any -alert-webhook, -alert-sound or -alert-desktop, until interrupted")
+	var cardFields string
+	flag.StringVar(&cardFields, "card-fields", "", "Comma-separated card fields: side,size,entry,current,pnl,pnl_pct,leverage,margin,margin_ratio,settle,notional,liq")
+	var minChange string
+	flag.StringVar(&minChange, "min-change", "", "Ignore position updates moving PnL less than N units of its last displayed digit, or N% (e.g. 5 or 0.1%)")
+	var pnlTrend string
+	flag.StringVar(&pnlTrend, "pnl-trend", "", "Comma-separated intervals to show each position's PnL change over, e.g. 1m,5m")
+	var tape bool
+	flag.BoolVar(&tape, "tape", false, "Start in single-line ticker tape mode (toggle with T)")
+	var rounding string
+	flag.StringVar(&rounding, "rounding", "default", "Rounding for displayed values: default, half-up or truncate")
+	var negativeAvail string
+	flag.StringVar(&negativeAvail, "negative-avail", "show", "Display of a negative available balance: show (flagged) or clamp (to zero, flagged)")
+	var alertRulesPath string
+	flag.StringVar(&alertRulesPath, "alert-rules", "", "JSON file of per-instrument alert thresholds (wildcards like *-USDT-SWAP allowed)")
+	var subscribeBatch int
+	flag.IntVar(&subscribeBatch, "subscribe-batch", 50, "Most channels per OKX subscribe request, larger sets are sent in batches")
+	var subscribeDelay time.Duration
+	flag.DurationVar(&subscribeDelay, "subscribe-delay", 350*time.Millisecond, "Wait between batched subscribe requests, to stay under OKX's request rate limit")
+	var balanceTimeout time.Duration
+	flag.DurationVar(&balanceTimeout, "balance-timeout", 10*time.Second, "Fetch the balance over REST when no account snapshot arrives this long after login (0 disables)")
+	var simulated bool
+	flag.BoolVar(&simulated, "simulated", false, "Use OKX demo-trading (paper) API keys: sends the x-simulated-trading header on connect")
+	var markPrice bool
+	flag.BoolVar(&markPrice, "mark-price", false, "Take prices only from the standard public mark-price channel, without the ipublic ticker socket")
+	var usdConvert bool
+	flag.BoolVar(&usdConvert, "usd-convert", false, "Also show PnL of coin-margined positions (e.g. BTC-USD-SWAP) converted to USD")
+	var colorCurrent bool
+	flag.BoolVar(&colorCurrent, "color-current", false, "Color the current price green above entry for longs (below for shorts), red otherwise")
+	var feedDiagnostics bool
+	flag.BoolVar(&feedDiagnostics, "feed-diagnostics", false, "Show a countdown on each card to the -stale-after feed timeout (always shown in debug mode)")
+	var demoCount int
+	flag.IntVar(&demoCount, "demo-count", 0, "Number of demo positions, 0 for the whole demo set; beyond it adds synthetic TESTn-USDT-SWAP instruments for load testing")
+	var kpis string
+	flag.StringVar(&kpis, "kpis", "", "Comma-separated account KPIs: equity,available,upnl,rpnl,margin_ratio,positions,leverage (none hides the row)")
+	var balanceBaseline string
+	flag.StringVar(&balanceBaseline, "balance-baseline", "tick", "Balance color baseline: tick (vs last update) or session (vs equity at startup)")
+	var debugRate int
+	flag.IntVar(&debugRate, "debug-rate", 20, "Max high-volume debug messages per second per category, excess dropped with a summary (0 disables)")
+	var debugInstruments string
+	flag.StringVar(&debugInstruments, "debug-instrument", "", "Comma-separated instruments to limit ticker/position/PnL debug traces to (e.g. BTC-USDT-SWAP)")
+	var liqWarnPct float64
+	flag.Float64Var(&liqWarnPct, "liq-warn", 5, "Warn on cards within N% of the liquidation price (0 disables)")
+	var liqETA bool
+	flag.BoolVar(&liqETA, "liq-eta", false, "Add a rough time-to-liquidation estimate from the last minute's price trend to liquidation warnings")
+	var demoEquity float64
+	flag.Float64Var(&demoEquity, "demo-equity", 10000, "Demo account equity before demo PnL, which moves it as prices change")
+	var minCardWidth int
+	flag.IntVar(&minCardWidth, "card-min-width", 24, "Minimum position card width; narrow terminals show fewer columns instead of narrower cards")
+	var notesFile string
+	flag.StringVar(&notesFile, "notes", ui.DefaultNotesFile(), "File position notes (edit with n) are saved to")
+	var pin string
+	flag.StringVar(&pin, "pin", "", "Comma-separated instruments always shown first, in this order, whatever the sort")
+	var uiSettingsFile string
+	flag.StringVar(&uiSettingsFile, "ui-settings", ui.DefaultUISettingsFile(), "File the debug toggle, sort, filters and pins are saved to on quit and restored from (empty disables)")
+	var keepClosedNotes bool
+	flag.BoolVar(&keepClosedNotes, "keep-closed-notes", false, "Keep notes of positions that close, for when they reopen, instead of pruning them")
+	var dustNotional float64
+	flag.Float64Var(&dustNotional, "dust-notional", 0, "Collapse positions with notional (price x size) below this into one Others card, expanded with enter or o (0 disables)")
+	var compactPnL bool
+	flag.BoolVar(&compactPnL, "compact-pnl", false, "Abbreviate PnL and notional amounts with K/M suffixes (e.g. +1.23K), keeping PnL % in full")
+	var accountTotals bool
+	flag.BoolVar(&accountTotals, "account-totals", false, "With several accounts, show equity and PnL totaled across them in dollars (rates from -fx) with per-account subtotals")
+	var demoPositionsPath string
+	flag.StringVar(&demoPositionsPath, "demo-positions", "", "JSON file of demo positions ({instId, avgPx, size, side, lever}) to use instead of the built-in set")
+	var errorCodes string
+	flag.StringVar(&errorCodes, "error-codes", "", "Override reconnect handling of OKX error/close codes as code=retry|fatal|backoff, e.g. 60014=fatal,4001=retry")
+	var channelPolicy string
+	flag.StringVar(&channelPolicy, "channel-policy", "", "What clients do when a channel to the UI is full, as channel=block|drop for position, balance, error and debug (debug drops, the rest block by default)")
+	var proxyURL string
+	flag.StringVar(&proxyURL, "proxy", "", "Route OKX WebSocket connections and signed REST requests through this http or socks5 proxy URL, e.g. socks5://127.0.0.1:1080 (HTTPS_PROXY from the environment otherwise)")
+	var debugPanic time.Duration
+	flag.DurationVar(&debugPanic, "debug-panic", 0, "Panic while rendering after this long, to check the terminal is restored and a crash log written (0 disables)")
+	var fxRates string
+	flag.StringVar(&fxRates, "fx", "", "Dollar rates for totaling PnL across settlement currencies, as CCY=rate or CCY=live (USDT ticker), e.g. USDC=live (default USDT, USDC and USD 1:1)")
+	var leverage string
+	flag.StringVar(&leverage, "leverage", "", "Leverage to assume where OKX reports none, as instId=leverage pairs, e.g. BTC-USDT-SWAP=20 (default 1x; also used by demo positions without their own)")
+	var metadataRefresh time.Duration
+	flag.DurationVar(&metadataRefresh, "metadata-refresh", 4*time.Hour, "Fetch cached instrument metadata (max leverage) again this often, keeping the old data if it fails (0 disables)")
+	var recentlyClosed time.Duration
+	flag.DurationVar(&recentlyClosed, "recently-closed", 0, "List positions closed within this lookback below the cards, with realized PnL and close time (needs API credentials; 0 disables)")
+	var tickerThrottle time.Duration
+	flag.DurationVar(&tickerThrottle, "ticker-throttle", 250*time.Millisecond, "Coalesce ticker prices per instrument and send only the latest one this often (0 sends every update)")
+	var bufferLimits string
+	flag.StringVar(&bufferLimits, "buffer-limits", "", "Per-buffer history caps as name=N pairs, e.g. price=240,trades=20 (price 120, pnl 1000, trades 50, equity 720 by default)")
+	var bufferCap int
+	flag.IntVar(&bufferCap, "buffer-cap", 100000, "Most samples held across per-instrument history buffers before the least recently updated instruments are evicted (negative uncaps)")
+	var configPath string
+	flag.StringVar(&configPath, "config", "config.yaml", "YAML settings file (demo positions, theme, refresh, reconnect, sort); flags override it, a missing file is ignored")
 	flag.Parse()
 
+	// Settings file, flags given on the command line take precedence over it
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	if cfg.Sort != "" && !setFlags["sort"] {
+		sortModeName = cfg.Sort
+	}
+	if cfg.Proxy != "" && !setFlags["proxy"] {
+		proxyURL = cfg.Proxy
+	}
+	if cfg.Alerts.LossPct > 0 && !setFlags["loss-alert"] {
+		lossAlertPct = cfg.Alerts.LossPct
+	}
+	if cfg.Alerts.GainPct > 0 && !setFlags["gain-alert"] {
+		gainAlertPct = cfg.Alerts.GainPct
+	}
+	if cfg.Alerts.Hysteresis != 0 && !setFlags["alert-hysteresis"] {
+		alertHysteresis = cfg.Alerts.Hysteresis
+	}
+	if len(cfg.Pins) > 0 && !setFlags["pin"] {
+		pin = strings.Join(cfg.Pins, ",")
+	}
+	if cfg.Demo.Count > 0 && !setFlags["demo-count"] {
+		demoCount = cfg.Demo.Count
+	}
+	if cfg.Demo.Equity > 0 && !setFlags["demo-equity"] {
+		demoEquity = cfg.Demo.Equity
+	}
+
+	var debugInstIds []string
+	for _, instId := range strings.Split(debugInstruments, ",") {
+		if instId = strings.ToUpper(strings.TrimSpace(instId)); instId != "" {
+			debugInstIds = append(debugInstIds, instId)
+		}
+	}
+
 	// Create channels for communication first
 	positionCh := make(chan core.PositionData, 100)
 	balanceCh := make(chan core.BalanceData, 100)
 	errorCh := make(chan string, 10)
 
+	// Trades feed is optional since it can be high-volume
+	var tradeCh chan core.TradeData
+	if showTrades {
+		tradeCh = make(chan core.TradeData, 200)
+	}
+
+	// Recently closed positions are only polled when asked for
+	var closedCh chan core.ClosedHistory
+	if recentlyClosed > 0 {
+		closedCh = make(chan core.ClosedHistory, 10)
+	}
+
+	// Order book snapshots and subscription requests for the detail view
+	bookCh := make(chan core.BookData, 10)
+	bookReqCh := make(chan string, 1)
+	pauseReqCh := make(chan bool, 1)
+
+	// Bid, ask and 24h volume for the t toggle, only carried by the tickers channel
+	var tickerCh chan core.TickerData
+	if !markPrice {
+		tickerCh = make(chan core.TickerData, 100)
+	}
+
+	// Connection state changes, used to keep positions across reconnects
+	statusCh := make(chan core.ConnState, 10)
+
+	// Messages from startup, sent to the error channel once something reads it:
+	// more than it buffers would otherwise block before the UI starts
+	var startupMsgs []string
+
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
-		errorCh <- fmt.Sprintf("DEBUG: Warning: Could not load .env file: %v", err)
+		startupMsgs = append(startupMsgs, fmt.Sprintf("DEBUG: Warning: Could not load .env file: %v", err))
 	}
 
-	// Get API credentials from environment variables
-	apiKey := os.Getenv("OKX_API_KEY")
-	secretKey := os.Getenv("OKX_API_SECRET")
-	passphrase := os.Getenv("OKX_API_PASSPHRASE")
+	// Numbered credential sets monitor several accounts, switched between in the UI
+	accounts, accountWarnings := loadAccounts()
+	startupMsgs = append(startupMsgs, accountWarnings...)
+	switch len(accounts) {
+	case 0:
+		// Get API credentials from environment variables, or the settings file
+		apiKey := envOr("OKX_API_KEY", cfg.Credentials.APIKey)
+		secretKey := envOr("OKX_API_SECRET", cfg.Credentials.APISecret)
+		passphrase := envOr("OKX_API_PASSPHRASE", cfg.Credentials.Passphrase)
 
-	// Validate credentials
-	validCredentials := validateAPICredentials(apiKey, secretKey, passphrase)
-	
-	if !validCredentials {
-		errorCh <- "DEBUG: Running in demo mode - Invalid or missing API credentials"
-		errorCh <- "DEBUG: To use live data, please set valid OKX API credentials in .env file"
-		// Clear invalid credentials to ensure demo mode
-		apiKey, secretKey, passphrase = "", "", ""
-	} else {
-		errorCh <- "DEBUG: Running in authenticated mode with valid API credentials"
+		// Validate credentials
+		if !validateAPICredentials(apiKey, secretKey, passphrase) {
+			startupMsgs = append(startupMsgs,
+				"DEBUG: Running in demo mode - Invalid or missing API credentials",
+				"DEBUG: To use live data, please set valid OKX API credentials in .env file")
+			// Clear invalid credentials to ensure demo mode
+			apiKey, secretKey, passphrase = "", "", ""
+		} else {
+			startupMsgs = append(startupMsgs, "DEBUG: Running in authenticated mode with valid API credentials")
+		}
+		accounts = []accountCredentials{{apiKey: apiKey, secretKey: secretKey, passphrase: passphrase}}
+	case 1:
+		// A single numbered set works like the unnumbered one, without a label
+		accounts[0].label = ""
+		startupMsgs = append(startupMsgs, "DEBUG: Running in authenticated mode with valid API credentials")
+	default:
+		startupMsgs = append(startupMsgs, fmt.Sprintf("DEBUG: Running in authenticated mode with %d accounts", len(accounts)))
+	}
+
+	// Open optional snapshot persistence, a failure only disables it
+	var recorder *store.Recorder
+	if dbPath != "" {
+		var err error
+		if recorder, err = store.Open(dbPath); err != nil {
+			startupMsgs = append(startupMsgs, fmt.Sprintf("Snapshot persistence disabled: %v", err))
+		} else {
+			defer recorder.Close()
+		}
+	}
+
+	// Open the optional update log, flushed when main returns after the TUI quits
+	var updateLog *store.UpdateLog
+	if updateLogPath != "" {
+		var err error
+		if updateLog, err = store.OpenUpdateLog(updateLogPath); err != nil {
+			startupMsgs = append(startupMsgs, fmt.Sprintf("Update logging disabled: %v", err))
+		} else {
+			defer updateLog.Close()
+		}
+	}
+
+	// Load per-instrument alert overrides
+	var alertRules []ui.AlertRule
+	if alertRulesPath != "" {
+		var err error
+		if alertRules, err = ui.LoadAlertRules(alertRulesPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load alert rules: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Restore the UI settings saved on the last quit, below flags and config
+	var uiSettings ui.UISettings
+	if uiSettingsFile != "" {
+		uiSettings = ui.LoadUISettings(uiSettingsFile)
+	}
+	if !setFlags["debug"] && !setFlags["d"] {
+		debugMode = uiSettings.ShowDebug
+	}
+	if uiSettings.SortMode != "" && cfg.Sort == "" && !setFlags["sort"] {
+		sortModeName = uiSettings.SortMode
+	}
+
+	// Load position notes, a broken file only disables editing so it isn't overwritten
+	notes, err := ui.LoadNotes(notesFile)
+	if err != nil {
+		startupMsgs = append(startupMsgs, fmt.Sprintf("WARN: Position notes disabled: %v", err))
+		notesFile = ""
 	}
 
-	// Create and start the TUI immediately with debug mode setting
+	// Load custom demo positions, from the settings file unless given with -demo-positions
+	demoPositions := cfg.Demo.Positions
+	if demoPositionsPath != "" {
+		if demoPositions, err = core.LoadDemoPositions(demoPositionsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load demo positions: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Thresholds, sizes and intervals where a negative value means nothing
+	for _, f := range []struct {
+		name     string
+		negative bool
+	}{
+		{"loss-alert", lossAlertPct < 0},
+		{"gain-alert", gainAlertPct < 0},
+		{"margin-alert", marginAlertPct < 0},
+		{"liq-alert", liqAlertPct < 0},
+		{"liq-warn", liqWarnPct < 0},
+		{"dust-notional", dustNotional < 0},
+		{"demo-equity", demoEquity < 0},
+		{"demo-count", demoCount < 0},
+		{"debug-rate", debugRate < 0},
+		{"debug-width", debugWidth < 0},
+		{"card-min-width", minCardWidth < 0},
+		{"subscribe-batch", subscribeBatch < 0},
+		{"equity-bucket", equityBucket < 0},
+		{"open-alert-debounce", openAlertDebounce < 0},
+		{"stale-after", staleAfter < 0},
+		{"subscribe-delay", subscribeDelay < 0},
+		{"balance-timeout", balanceTimeout < 0},
+		{"metadata-refresh", metadataRefresh < 0},
+		{"recently-closed", recentlyClosed < 0},
+		{"ticker-throttle", tickerThrottle < 0},
+		{"debug-panic", debugPanic < 0},
+	} {
+		if f.negative {
+			fmt.Fprintf(os.Stderr, "Invalid -%s: must not be negative\n", f.name)
+			os.Exit(1)
+		}
+	}
+	if timelapseSpeed <= 0 {
+		fmt.Fprintf(os.Stderr, "Invalid -timelapse-speed: must be positive\n")
+		os.Exit(1)
+	}
+
+	if staleGrace < 0 || (staleAfter > 0 && staleGrace >= staleAfter) {
+		fmt.Fprintf(os.Stderr, "Invalid -stale-grace %s: must be at least 0 and shorter than -stale-after %s\n", staleGrace, staleAfter)
+		os.Exit(1)
+	}
+
+	// Overrides of which OKX errors stop reconnecting
+	errorActions, err := core.ParseErrorActions(errorCodes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -error-codes: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Block or drop when the UI falls behind, counted for the debug pane
+	channelPolicies, err := core.ParseChannelPolicies(channelPolicy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -channel-policy: %v\n", err)
+		os.Exit(1)
+	}
+	backpressure := core.NewBackpressure(channelPolicies)
+
+	// An explicit proxy replaces the environment's for every client and for
+	// the instrument metadata requests
+	restClient, err := core.NewHTTPClient(proxyURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -proxy: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Assumed leverage per instrument, flags take precedence over the settings file
+	leverageOverrides, err := core.ParseLeverageOverrides(leverage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -leverage: %v\n", err)
+		os.Exit(1)
+	}
+	for instId, lever := range cfg.Leverage {
+		if instId = strings.ToUpper(instId); leverageOverrides[instId] == 0 {
+			leverageOverrides[instId] = lever
+		}
+	}
+
+	// Tickers followed for live FX rates
+	fxInstruments, err := ui.FXInstruments(fxRates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -fx: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load recorded history for timelapse playback
+	var player *store.Player
+	if timelapsePath != "" {
+		frames, err := store.LoadFrames(timelapsePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load timelapse: %v\n", err)
+			os.Exit(1)
+		}
+		player = store.NewPlayer(frames, timelapseSpeed)
+	}
+
+	// Create and start the TUI immediately with debug mode and feed settings
+	opts := ui.Options{
+		Debug:      debugMode,
+		TradeCh:    tradeCh,
+		BookCh:     bookCh,
+		TickerCh:   tickerCh,
+		BookReqCh:  bookReqCh,
+		PauseReqCh: pauseReqCh,
+
+		LossAlertPct: lossAlertPct,
+		GainAlertPct: gainAlertPct,
+		AlertHysteresis: alertHysteresis,
+		AlertSelect:  alertSelect,
+
+		Recorder:  recorder,
+		UpdateLog: updateLog,
+
+		EquityWindow: equityWindow,
+		EquityBucket: equityBucket,
+
+		PairHedges: pairHedges,
+
+		MarginAlertPct: marginAlertPct,
+		LiqAlertPct:    liqAlertPct,
+		AlertWebhook:   alertWebhook,
+		AlertDesktop:   alertDesktop,
+		AlertSound:       alertSound,
+		AlertSoundPlayer: alertSoundPlayer,
+		OpenAlertLong:     openAlertLong,
+		OpenAlertShort:    openAlertShort,
+		OpenAlertDebounce: openAlertDebounce,
+		AlertRules:     alertRules,
+
+		PauseUnfocused: pauseUnfocused,
+		DebugWidth:     debugWidth,
+		Rounding:       rounding,
+		NegativeAvail:  negativeAvail,
+		USDConvert:     usdConvert,
+		FXRates:        fxRates,
+		ColorCurrent:   colorCurrent,
+		FeedDiagnostics: feedDiagnostics,
+		LiqWarnPct:     liqWarnPct,
+		LiqETA:         liqETA,
+		MinCardWidth:   minCardWidth,
+		Notes:          notes,
+		NotesFile:      notesFile,
+		KeepClosedNotes: keepClosedNotes,
+
+		Pins: ui.MergePins(strings.Split(pin, ","), uiSettings.Pins),
+		DustNotional:   dustNotional,
+		CompactPnL:     compactPnL,
+		PanicAfter:     debugPanic,
+		CardFields:     cardFields,
+		PnLTrend:       pnlTrend,
+		MinChange:      minChange,
+		KPIs:           kpis,
+		BalanceBaseline: balanceBaseline,
+		DebugInstruments: debugInstIds,
+		Tape:           tape,
+		ShowTimestamps: showTimestamps,
+		PriceSpread:    priceSpread,
+		PnLSparkline:   pnlSparkline,
+		ShowMaxLeverage: showMaxLeverage,
+
+		SortMode:   sortModeName,
+		StaleAfter: staleAfter,
+		StaleGrace: staleGrace,
+		StaleOnly:  uiSettings.StaleOnly,
+		Filter:     uiSettings.Filter,
+
+		NoAltScreen: noAltScreen,
+
+		StatusCh: statusCh,
+
+		BufferLimits: bufferLimits,
+		BufferCap:    bufferCap,
+
+		Backpressure: backpressure,
+		RESTClient:   restClient,
+
+		ClosedCh:       closedCh,
+		ClosedLookback: recentlyClosed,
+
+		RefreshInterval: cfg.Refresh,
+		MetadataRefresh: metadataRefresh,
+		Theme:           cfg.Theme,
+	}
+	if len(accounts) > 1 {
+		for _, account := range accounts {
+			opts.Accounts = append(opts.Accounts, account.label)
+		}
+		opts.AccountTotals = accountTotals
+	}
+	if debugStderr {
+		// Writing to the terminal under the alternate screen would corrupt the display
+		if noAltScreen || headless {
+			opts.DebugWriter = os.Stderr
+		} else {
+			startupMsgs = append(startupMsgs, "-debug-stderr requires -no-altscreen, ignoring")
+		}
+	}
+	if player != nil {
+		// No live order book or connection state while replaying recorded history
+		opts.Playback = player
+		opts.BookReqCh = nil
+		opts.PauseReqCh = nil
+		opts.StatusCh = nil
+	}
+	if headless {
+		// Nothing to show an order book in
+		opts.BookReqCh = nil
+	}
+
+	// Report mistyped UI options now rather than as an error inside the UI
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %v\n", err)
+		os.Exit(1)
+	}
+
+	// The TUI is created before anything starts, so a panic on any goroutine
+	// can restore its terminal
 	var program *tea.Program
-	if debugMode {
-		program = ui.NewProgramWithDebug(positionCh, balanceCh, errorCh)
-	} else {
-		program = ui.NewProgram(positionCh, balanceCh, errorCh)
+	if !headless {
+		program = ui.NewProgramWithOptions(positionCh, balanceCh, errorCh, opts)
+	}
+	goSafe := func(fn func()) {
+		go func() {
+			defer recoverProgram(program)
+			fn()
+		}()
 	}
 	
-	// Start API connection in a separate goroutine
-	go func() {
-		// Create OKX client with channels
-		client := core.NewOKXClient(positionCh, balanceCh, errorCh)
+	// The client runs until the TUI exits and cancels its context
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clientDone := make(chan struct{})
+
+	// API connections, one client per account, started in a separate goroutine
+	startClients := func() {
+		defer close(clientDone)
 
-		// Set API credentials if available and valid
-		if validCredentials {
-			client.SetCredentials(apiKey, secretKey, passphrase)
+		pauseReqs := []chan bool{pauseReqCh}
+		if len(accounts) > 1 {
+			pauseReqs = fanOutPauseRequests(pauseReqCh, len(accounts))
 		}
 
-		// Connect to OKX WebSocket
-		if err := client.Connect(); err != nil {
-			errorCh <- fmt.Sprintf("Failed to connect to OKX: %v", err)
-			return
+		var wg sync.WaitGroup
+		for i, account := range accounts {
+			// Create OKX client with channels
+			client := core.NewOKXClientWithContext(ctx, positionCh, balanceCh, errorCh)
+			if tradeCh != nil {
+				client.SetTradeChannel(tradeCh)
+			}
+			client.SetBookChannel(bookCh)
+			if tickerCh != nil {
+				client.SetTickerChannel(tickerCh)
+			}
+			if demoRandom {
+				client.SetDemoRandom(demoSeed)
+			}
+			client.SetDemoInstruments(demoPositions)
+			client.SetDemoCount(demoCount)
+			client.SetDemoEquity(demoEquity)
+			client.SetDebugRate(debugRate)
+			client.SetDebugInstruments(debugInstIds)
+			client.SetMarkPriceFeed(markPrice)
+			client.SetSimulatedTrading(simulated)
+			client.SetErrorActions(errorActions)
+			client.SetBackpressure(backpressure)
+			client.SetProxy(proxyURL) // Validated above
+			client.SetSubscribeBatching(subscribeBatch, subscribeDelay)
+			client.SetBalanceFallback(balanceTimeout)
+			client.SetClosedHistory(closedCh, recentlyClosed)
+			client.SetTickerThrottle(tickerThrottle)
+			client.SetReconnectPolicy(cfg.Reconnect.MinDelay, cfg.Reconnect.MaxDelay)
+			client.SetLeverageOverrides(leverageOverrides)
+			client.SetFXInstruments(fxInstruments)
+			client.SetAccount(account.label)
+			client.SetPanicHandler(func(r interface{}, stack []byte) { crash(program, r, stack) })
+
+			// Set API credentials if available and valid, demo mode otherwise
+			if account.apiKey != "" {
+				client.SetCredentials(account.apiKey, account.secretKey, account.passphrase)
+			}
+
+			if i == 0 {
+				// The first account's connection drives the connection status,
+				// and one order book subscription serves every account
+				client.SetStatusChannel(statusCh)
+
+				// Follow order book requests from the detail view
+				goSafe(func() { client.WatchBookRequests(bookReqCh) })
+			}
+
+			// Pause and resume subscriptions on request from the UI
+			pauseReq := pauseReqs[i]
+			goSafe(func() { client.WatchPauseRequests(pauseReq) })
+
+			// Connect to OKX WebSocket and listen, reconnecting whenever the connection drops
+			wg.Add(1)
+			goSafe(func() {
+				defer wg.Done()
+				client.RunWithReconnect()
+			})
+		}
+		wg.Wait()
+	}
+
+	// Replay recorded history instead of connecting when running a timelapse
+	if player != nil {
+		goSafe(func() { player.Run(positionCh, balanceCh, errorCh) })
+		close(clientDone)
+	} else {
+		goSafe(startClients)
+
+		// Warn about configured instruments OKX doesn't list, which would
+		// otherwise just never match or show anything
+		var ruleInstIds []string
+		for _, rule := range alertRules {
+			ruleInstIds = append(ruleInstIds, rule.Inst)
 		}
-		defer client.Close()
+		pinInstIds := ui.MergePins(strings.Split(pin, ","), nil)
+		if len(ruleInstIds) > 0 || len(debugInstIds) > 0 || len(pinInstIds) > 0 {
+			instIdsByOption := map[string][]string{
+				"-alert-rules":      ruleInstIds,
+				"-debug-instrument": debugInstIds,
+				"-pin":              pinInstIds,
+			}
+			goSafe(func() { warnUnknownInstruments(restClient, errorCh, instIdsByOption) })
+		}
+	}
 
-		// Start listening for position updates
-		client.StartListening()
+	// The UI or headless loop is about to read the error channel
+	go func() {
+		for _, msg := range startupMsgs {
+			errorCh <- msg
+		}
 	}()
 
+	// Without a UI, only alert until interrupted or terminated
+	if headless {
+		sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		ui.RunHeadless(sigCtx, positionCh, balanceCh, errorCh, opts, os.Stdout, os.Stderr)
+		stop()
+
+		cancel()
+		drainClient(clientDone, positionCh, balanceCh, errorCh, tradeCh, bookCh, tickerCh, closedCh)
+		return
+	}
+
 	// Run the TUI (this blocks until the user quits)
-	if _, err := program.Run(); err != nil {
-		// Send error to error channel and exit gracefully
-		errorCh <- fmt.Sprintf("FATAL: Error running program: %v", err)
+	defer recoverProgram(program)
+	finalModel, err := program.Run()
+
+	// Shut the client down: close its connections and stop its goroutines
+	cancel()
+	drainClient(clientDone, positionCh, balanceCh, errorCh, tradeCh, bookCh, tickerCh, closedCh)
+
+	// Save the UI settings for the next run
+	if model, ok := finalModel.(ui.Model); ok && err == nil && uiSettingsFile != "" {
+		if err := ui.SaveUISettings(uiSettingsFile, model.UISettings()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save UI settings: %v\n", err)
+		}
+	}
+
+	if err != nil {
+		// Nothing reads the error channel anymore
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
\ No newline at end of file
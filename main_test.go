@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLoadAccountsCollectsWarnings(t *testing.T) {
+	// More broken sets than the error channel buffers must not block
+	const broken = 15
+	for i := 1; i <= broken; i++ {
+		t.Setenv(fmt.Sprintf("OKX_API_KEY_%d", i), "not-a-key")
+	}
+	t.Setenv(fmt.Sprintf("OKX_API_KEY_%d", broken+1), "0123abcd-0123-4567-89ab-0123456789ab")
+	t.Setenv(fmt.Sprintf("OKX_API_SECRET_%d", broken+1), strings.Repeat("s", 32))
+	t.Setenv(fmt.Sprintf("OKX_API_PASSPHRASE_%d", broken+1), "pass")
+	t.Setenv(fmt.Sprintf("OKX_ACCOUNT_NAME_%d", broken+1), "Main")
+
+	accounts, warnings := loadAccounts()
+	if len(accounts) != 1 || accounts[0].label != "Main" {
+		t.Errorf("accounts = %+v, want only Main", accounts)
+	}
+	if len(warnings) != broken {
+		t.Fatalf("warnings = %d, want %d", len(warnings), broken)
+	}
+	if want := "WARN: Skipping Account 1: invalid or incomplete OKX_*_1 credentials"; warnings[0] != want {
+		t.Errorf("first warning = %q, want %q", warnings[0], want)
+	}
+}
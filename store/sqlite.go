@@ -0,0 +1,172 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, no cgo required
+)
+
+const (
+	// flushInterval is how often queued snapshots are written to disk
+	flushInterval = 30 * time.Second
+
+	// maxPending forces a flush once this many rows are queued
+	maxPending = 500
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	ts        INTEGER NOT NULL,
+	kind      TEXT    NOT NULL,
+	inst_id   TEXT    NOT NULL,
+	pos_side  TEXT    NOT NULL DEFAULT '',
+	size      REAL    NOT NULL DEFAULT 0,
+	avg_px    REAL    NOT NULL DEFAULT 0,
+	mark_px   REAL    NOT NULL DEFAULT 0,
+	pnl       REAL    NOT NULL DEFAULT 0,
+	pnl_ratio REAL    NOT NULL DEFAULT 0,
+	lever     REAL    NOT NULL DEFAULT 0,
+	margin    REAL    NOT NULL DEFAULT 0,
+	total_eq  REAL    NOT NULL DEFAULT 0,
+	avail_bal REAL    NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS snapshots_ts ON snapshots (ts);
+`
+
+// Snapshot kinds stored in the kind column
+const (
+	KindPosition = "position"
+	KindBalance  = "balance"
+)
+
+// Snapshot is a single row of position or balance state at a point in time
+type Snapshot struct {
+	Timestamp    int64 // Unix milliseconds
+	Kind         string
+	InstrumentID string // Instrument for positions, currency for balances
+	PositionSide string
+	Size         float64
+	AvgPrice     float64
+	CurrentPrice float64
+	PnL          float64
+	PnLRatio     float64
+	Leverage     float64
+	Margin       float64
+	TotalEquity  float64
+	AvailBalance float64
+}
+
+// Recorder batches position and balance snapshots into a SQLite database
+type Recorder struct {
+	db        *sql.DB
+	mu        sync.Mutex
+	pending   []Snapshot
+	lastFlush time.Time
+}
+
+// Open opens or creates the snapshot database at path
+func Open(path string) (*Recorder, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot database: %v", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create snapshot schema: %v", err)
+	}
+
+	return &Recorder{db: db, lastFlush: time.Now()}, nil
+}
+
+// Record queues a snapshot of the given positions and balances and flushes the
+// batch when it is due
+func (r *Recorder) Record(positions []core.PositionData, balances []core.BalanceData, at time.Time) error {
+	ts := at.UnixNano() / int64(time.Millisecond)
+
+	r.mu.Lock()
+	for _, pos := range positions {
+		r.pending = append(r.pending, Snapshot{
+			Timestamp:    ts,
+			Kind:         KindPosition,
+			InstrumentID: pos.InstrumentID,
+			PositionSide: pos.PositionSide,
+			Size:         pos.Size,
+			AvgPrice:     pos.AvgPrice,
+			CurrentPrice: pos.CurrentPrice,
+			PnL:          pos.PnL,
+			PnLRatio:     pos.PnLRatio,
+			Leverage:     pos.Leverage,
+			Margin:       pos.Margin,
+		})
+	}
+	for _, bal := range balances {
+		r.pending = append(r.pending, Snapshot{
+			Timestamp:    ts,
+			Kind:         KindBalance,
+			InstrumentID: bal.Currency,
+			TotalEquity:  bal.TotalEquity,
+			AvailBalance: bal.AvailBalance,
+		})
+	}
+	due := len(r.pending) >= maxPending || time.Since(r.lastFlush) >= flushInterval
+	r.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return r.Flush()
+}
+
+// Flush writes all queued snapshots in a single transaction
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = nil
+	r.lastFlush = time.Now()
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin snapshot write: %v", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO snapshots
+		(ts, kind, inst_id, pos_side, size, avg_px, mark_px, pnl, pnl_ratio, lever, margin, total_eq, avail_bal)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare snapshot write: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range batch {
+		if _, err := stmt.Exec(s.Timestamp, s.Kind, s.InstrumentID, s.PositionSide, s.Size, s.AvgPrice,
+			s.CurrentPrice, s.PnL, s.PnLRatio, s.Leverage, s.Margin, s.TotalEquity, s.AvailBalance); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to write snapshot: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit snapshots: %v", err)
+	}
+	return nil
+}
+
+// Close flushes any queued snapshots and closes the database
+func (r *Recorder) Close() error {
+	flushErr := r.Flush()
+	if err := r.db.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// maxGapWait caps the real time spent holding state across a sparse gap in the data
+const maxGapWait = 2 * time.Second
+
+// Frame is the full recorded state at a single snapshot timestamp
+type Frame struct {
+	Timestamp int64 // Unix milliseconds
+	Positions []core.PositionData
+	Balances  []core.BalanceData
+}
+
+// LoadFrames reads all recorded snapshots from the database at path, grouped by timestamp
+func LoadFrames(path string) ([]Frame, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT ts, kind, inst_id, pos_side, size, avg_px, mark_px, pnl, pnl_ratio,
+		lever, margin, total_eq, avail_bal FROM snapshots ORDER BY ts`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	var frames []Frame
+	for rows.Next() {
+		var s Snapshot
+		if err := rows.Scan(&s.Timestamp, &s.Kind, &s.InstrumentID, &s.PositionSide, &s.Size, &s.AvgPrice,
+			&s.CurrentPrice, &s.PnL, &s.PnLRatio, &s.Leverage, &s.Margin, &s.TotalEquity, &s.AvailBalance); err != nil {
+			return nil, fmt.Errorf("failed to read snapshot row: %v", err)
+		}
+
+		if len(frames) == 0 || frames[len(frames)-1].Timestamp != s.Timestamp {
+			frames = append(frames, Frame{Timestamp: s.Timestamp})
+		}
+		frame := &frames[len(frames)-1]
+
+		switch s.Kind {
+		case KindPosition:
+			frame.Positions = append(frame.Positions, core.PositionData{
+				InstrumentID: s.InstrumentID,
+				PositionSide: s.PositionSide,
+				Size:         s.Size,
+				AvgPrice:     s.AvgPrice,
+				CurrentPrice: s.CurrentPrice,
+				PnL:          s.PnL,
+				PnLRatio:     s.PnLRatio,
+				Leverage:     s.Leverage,
+				Margin:       s.Margin,
+				Timestamp:    s.Timestamp,
+			})
+		case KindBalance:
+			frame.Balances = append(frame.Balances, core.BalanceData{
+				Currency:     s.InstrumentID,
+				TotalEquity:  s.TotalEquity,
+				AvailBalance: s.AvailBalance,
+				Timestamp:    s.Timestamp,
+			})
+		}
+	}
+
+	return frames, rows.Err()
+}
+
+// Player replays recorded frames through the UI channels at an accelerated rate
+type Player struct {
+	frames []Frame
+	mu     sync.Mutex
+	speed  float64
+	paused bool
+}
+
+// NewPlayer creates a player for frames at the given speed multiplier
+func NewPlayer(frames []Frame, speed float64) *Player {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &Player{frames: frames, speed: speed}
+}
+
+// TogglePause pauses or resumes playback and reports whether it is now paused
+func (p *Player) TogglePause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = !p.paused
+	return p.paused
+}
+
+// Paused reports whether playback is paused
+func (p *Player) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// SetSpeed sets the playback speed multiplier
+func (p *Player) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+	p.mu.Lock()
+	p.speed = speed
+	p.mu.Unlock()
+}
+
+// Speed returns the playback speed multiplier
+func (p *Player) Speed() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.speed
+}
+
+// Run replays all frames, closing positions that disappear between frames.
+// State is held across gaps in the data until the next frame is due.
+func (p *Player) Run(positionCh chan<- core.PositionData, balanceCh chan<- core.BalanceData, errorCh chan<- string) {
+	if len(p.frames) == 0 {
+		errorCh <- "Timelapse: no snapshots recorded"
+		return
+	}
+
+	errorCh <- fmt.Sprintf("DEBUG: Timelapse replaying %d frames", len(p.frames))
+
+	open := make(map[string]core.PositionData)
+	for i, frame := range p.frames {
+		if i > 0 {
+			p.wait(time.Duration(frame.Timestamp-p.frames[i-1].Timestamp) * time.Millisecond)
+		}
+
+		seen := make(map[string]bool)
+		for _, pos := range frame.Positions {
+			key := pos.InstrumentID + "-" + pos.PositionSide
+			seen[key] = true
+			open[key] = pos
+			positionCh <- pos
+		}
+
+		// Positions missing from this frame were closed
+		for key, pos := range open {
+			if !seen[key] {
+				pos.Size = 0
+				positionCh <- pos
+				delete(open, key)
+			}
+		}
+
+		for _, bal := range frame.Balances {
+			balanceCh <- bal
+		}
+	}
+
+	errorCh <- "DEBUG: Timelapse finished"
+}
+
+// wait sleeps for the scaled gap between frames, honoring pause and speed changes
+func (p *Player) wait(gap time.Duration) {
+	const step = 50 * time.Millisecond
+
+	var elapsed time.Duration
+	for {
+		p.mu.Lock()
+		paused, speed := p.paused, p.speed
+		p.mu.Unlock()
+
+		if !paused {
+			scaled := time.Duration(float64(gap) / speed)
+			if scaled > maxGapWait {
+				scaled = maxGapWait
+			}
+			if elapsed >= scaled {
+				return
+			}
+			elapsed += step
+		}
+		time.Sleep(step)
+	}
+}
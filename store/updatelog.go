@@ -0,0 +1,75 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// UpdateLog appends every position and balance update to a JSONL file, one
+// JSON object per line with its local receipt time
+type UpdateLog struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// updateRecord is one line of the update log
+type updateRecord struct {
+	ReceivedAt int64              `json:"receivedAt"` // Local receipt time, Unix milliseconds
+	Kind       string             `json:"kind"`       // KindPosition or KindBalance
+	Position   *core.PositionData `json:"position,omitempty"`
+	Balance    *core.BalanceData  `json:"balance,omitempty"`
+}
+
+// OpenUpdateLog opens the update log at path for appending, creating it if needed
+func OpenUpdateLog(path string) (*UpdateLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open update log: %v", err)
+	}
+	return &UpdateLog{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// LogPosition appends a position update
+func (l *UpdateLog) LogPosition(pos core.PositionData) error {
+	return l.append(updateRecord{Kind: KindPosition, Position: &pos})
+}
+
+// LogBalance appends a balance update
+func (l *UpdateLog) LogBalance(balance core.BalanceData) error {
+	return l.append(updateRecord{Kind: KindBalance, Balance: &balance})
+}
+
+// append writes a record stamped with the current time to the buffer
+func (l *UpdateLog) append(record updateRecord) error {
+	record.ReceivedAt = time.Now().UnixNano() / int64(time.Millisecond)
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write update log: %v", err)
+	}
+	return nil
+}
+
+// Close flushes buffered updates and closes the file
+func (l *UpdateLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.w.Flush()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// accountNameStyle shows the active account next to the title
+var accountNameStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("86")).
+	Bold(true)
+
+// accountState is the data the model keeps per account. The active account's
+// state lives in the model's own fields and is swapped out on a switch.
+type accountState struct {
+	positions          map[string]core.PositionData
+	balances           map[string]core.BalanceData
+	rendered           *renderedBalance
+	sessionStartEquity float64
+	equity             *equityHistory
+	pnlHistories       map[string]*pnlHistory
+	pnlSparks          map[string]*pnlSpark
+	refreshPending     map[string]bool
+	alerted            map[string]bool
+	gainAlerted        map[string]bool
+	alertFlashes       map[string]alertFlash
+	marginAlerted      map[string]bool
+	liqAlerted         map[string]bool
+	priceAlerted       map[string]bool
+	closing            map[string]closedPosition
+	recentlyClosed     []core.ClosedPosition
+	closedFetched      bool
+}
+
+// newAccountState returns the empty state of an account not seen before
+func (m Model) newAccountState() *accountState {
+	return &accountState{
+		positions:      make(map[string]core.PositionData),
+		balances:       make(map[string]core.BalanceData),
+		rendered:       &renderedBalance{},
+		equity:         newEquityHistory(m.equity.bucket, m.equity.limit),
+		pnlHistories:   make(map[string]*pnlHistory),
+		pnlSparks:      make(map[string]*pnlSpark),
+		refreshPending: make(map[string]bool),
+		alerted:        make(map[string]bool),
+		gainAlerted:    make(map[string]bool),
+		alertFlashes:   make(map[string]alertFlash),
+		marginAlerted:  make(map[string]bool),
+		liqAlerted:     make(map[string]bool),
+		priceAlerted:   make(map[string]bool),
+	}
+}
+
+// setAccounts registers the account labels in switcher order, the first one
+// shown at start. A single unlabelled account leaves the switcher off.
+func (m *Model) setAccounts(labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+	m.accounts = labels
+	m.account = labels[0]
+}
+
+// useAccount swaps the given account's state into the model, keeping the
+// current account's state for when it is switched back to
+func (m *Model) useAccount(label string) {
+	if label == m.account {
+		return
+	}
+	if m.accountStates == nil {
+		m.accountStates = make(map[string]*accountState)
+	}
+
+	m.accountStates[m.account] = &accountState{
+		positions:          m.positions,
+		balances:           m.balances,
+		rendered:           m.rendered,
+		sessionStartEquity: m.sessionStartEquity,
+		equity:             m.equity,
+		pnlHistories:       m.pnlHistories,
+		pnlSparks:          m.pnlSparks,
+		refreshPending:     m.refreshPending,
+		alerted:            m.alerted,
+		gainAlerted:        m.gainAlerted,
+		alertFlashes:       m.alertFlashes,
+		marginAlerted:      m.marginAlerted,
+		liqAlerted:         m.liqAlerted,
+		priceAlerted:       m.priceAlerted,
+		closing:            m.closing,
+		recentlyClosed:     m.recentlyClosed,
+		closedFetched:      m.closedFetched,
+	}
+
+	state, ok := m.accountStates[label]
+	if !ok {
+		state = m.newAccountState()
+	}
+	m.account = label
+	m.positions = state.positions
+	m.balances = state.balances
+	m.rendered = state.rendered
+	m.sessionStartEquity = state.sessionStartEquity
+	m.equity = state.equity
+	m.pnlHistories = state.pnlHistories
+	m.pnlSparks = state.pnlSparks
+	m.refreshPending = state.refreshPending
+	m.alerted = state.alerted
+	m.gainAlerted = state.gainAlerted
+	m.alertFlashes = state.alertFlashes
+	m.marginAlerted = state.marginAlerted
+	m.liqAlerted = state.liqAlerted
+	m.priceAlerted = state.priceAlerted
+	m.closing = state.closing
+	m.recentlyClosed = state.recentlyClosed
+	m.closedFetched = state.closedFetched
+}
+
+// updateAccount applies an update for an account other than the one shown,
+// with that account's state swapped in so alerts, history and logging work
+// as for the active account. Card selection and scrolling are left alone.
+func (m Model) updateAccount(label string, msg tea.Msg) (tea.Model, tea.Cmd) {
+	known := false
+	for _, account := range m.accounts {
+		known = known || account == label
+	}
+	if !known {
+		m.accounts = append(m.accounts, label)
+	}
+
+	active, selected, scrollOffset := m.account, m.selected, m.scrollOffset
+	m.useAccount(label)
+	updated, cmd := m.Update(msg)
+	m = updated.(Model)
+	m.useAccount(active)
+	m.selected, m.scrollOffset = selected, scrollOffset
+	return m, cmd
+}
+
+// switchAccount shows the account at index i of the switcher, reporting false
+// when there is no such account or it is already shown
+func (m *Model) switchAccount(i int) bool {
+	if i < 0 || i >= len(m.accounts) || m.accounts[i] == m.account {
+		return false
+	}
+	m.useAccount(m.accounts[i])
+	m.selected = 0
+	m.scrollOffset = 0
+	m.detailView = false
+	m.showToast("Account: " + m.account)
+	return true
+}
+
+// nextAccount shows the account after the current one, wrapping around
+func (m *Model) nextAccount() bool {
+	for i, account := range m.accounts {
+		if account == m.account {
+			return m.switchAccount((i + 1) % len(m.accounts))
+		}
+	}
+	return false
+}
+
+// renderAccountName renders the active account for the header title, or ""
+// with a single account
+func (m Model) renderAccountName() string {
+	if len(m.accounts) < 2 {
+		return ""
+	}
+	return accountNameStyle.Render(" · " + m.account)
+}
+
+// accountHelp is the footer hint for the account switcher, "" with a single account
+func (m Model) accountHelp() string {
+	if len(m.accounts) < 2 {
+		return ""
+	}
+	return " | 1-9/Tab account"
+}
+
+// accountTotal is an account's equity and unrealized PnL in dollars
+type accountTotal struct {
+	label   string
+	equity  float64
+	pnl     float64
+	partial bool // Some amounts have no dollar rate yet and are left out
+}
+
+// accountTotalsByAccount returns the totals of each account in switcher
+// order, converted to dollars with the FX table like the other totals.
+// Accounts that haven't sent anything yet total zero.
+func (m Model) accountTotalsByAccount() []accountTotal {
+	totals := make([]accountTotal, 0, len(m.accounts))
+	for _, label := range m.accounts {
+		positions, balances := m.positions, m.balances
+		if label != m.account {
+			state, ok := m.accountStates[label]
+			if !ok {
+				totals = append(totals, accountTotal{label: label})
+				continue
+			}
+			positions, balances = state.positions, state.balances
+		}
+
+		total := accountTotal{label: label}
+		for _, balance := range balances {
+			rate, ok := m.dollarRate(balance.Currency)
+			if !ok {
+				total.partial = true
+				continue
+			}
+			total.equity += balance.TotalEquity * rate
+		}
+
+		list := make([]core.PositionData, 0, len(positions))
+		for _, pos := range positions {
+			list = append(list, pos)
+		}
+		var partial bool
+		total.pnl, _, partial = m.usdSum(list, func(pos core.PositionData) float64 { return pos.PnL })
+		total.partial = total.partial || partial
+		totals = append(totals, total)
+	}
+	return totals
+}
+
+// grandTotal sums the totals of every account
+func grandTotal(totals []accountTotal) accountTotal {
+	grand := accountTotal{label: "All"}
+	for _, total := range totals {
+		grand.equity += total.equity
+		grand.pnl += total.pnl
+		grand.partial = grand.partial || total.partial
+	}
+	return grand
+}
+
+// renderAccountTotal renders one account's dollar equity and PnL, marked "~"
+// when some of it couldn't be converted
+func (m Model) renderAccountTotal(total accountTotal) string {
+	rendered := fmt.Sprintf("%s %s %s",
+		labelStyle.Render(total.label+":"),
+		valueStyle.Render("$"+m.formatAmount(total.equity, 2)),
+		m.stylePnL(total.pnl, 2))
+	if total.partial {
+		rendered = labelStyle.Render("~") + rendered
+	}
+	return rendered
+}
+
+// renderAccountTotals renders the equity and PnL totaled across accounts
+// followed by each account's subtotal, or "" unless enabled with several
+// accounts
+func (m Model) renderAccountTotals() string {
+	if !m.accountTotals || len(m.accounts) < 2 {
+		return ""
+	}
+	totals := m.accountTotalsByAccount()
+
+	parts := []string{m.renderAccountTotal(grandTotal(totals))}
+	for _, total := range totals {
+		parts = append(parts, m.renderAccountTotal(total))
+	}
+	return strings.Join(parts, labelStyle.Render(" | "))
+}
+
+// renderGrandTotalEquity renders the dollar equity across accounts for the
+// risk summary, or "" unless the account totals are shown
+func (m Model) renderGrandTotalEquity() string {
+	if !m.accountTotals || len(m.accounts) < 2 {
+		return ""
+	}
+	grand := grandTotal(m.accountTotalsByAccount())
+	equity := "$" + m.formatAmount(grand.equity, 2)
+	if grand.partial {
+		equity = "~" + equity
+	}
+	return fmt.Sprintf("%s %s", labelStyle.Render("All accounts equity:"), valueStyle.Render(equity))
+}
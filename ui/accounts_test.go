@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAccountTotalsAcrossAccounts(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{
+		Accounts:      []string{"Main", "Sub"},
+		AccountTotals: true,
+		FXRates:       "USDC=0.99",
+	})
+
+	mainPos := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500)
+	mainPos.Account = "Main"
+	subPos := testPosition("ETH-USDC-SWAP", "short", 2, 3000, 2950, 100)
+	subPos.Account = "Sub"
+	m = updateModel(m,
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 1000, Account: "Main"},
+		mainPos,
+		balanceUpdateMsg{Currency: "USDC", TotalEquity: 2000, Account: "Sub"},
+		subPos,
+	)
+
+	totals := m.accountTotalsByAccount()
+	want := []accountTotal{
+		{label: "Main", equity: 1000, pnl: 500},
+		{label: "Sub", equity: 1980, pnl: 99},
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("totals = %+v, want %+v", totals, want)
+	}
+	for i := range want {
+		got := totals[i]
+		if got.label != want[i].label || !approxEqual(got.equity, want[i].equity) || !approxEqual(got.pnl, want[i].pnl) || got.partial {
+			t.Errorf("account %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	grand := grandTotal(totals)
+	if !approxEqual(grand.equity, 2980) || !approxEqual(grand.pnl, 599) {
+		t.Errorf("grand total = %+v, want 2980 equity and 599 PnL", grand)
+	}
+
+	// Switching accounts moves the state around but not the totals
+	m = updateModel(m, testKey("tab"))
+	if m.account != "Sub" {
+		t.Fatalf("account = %q after tab, want Sub", m.account)
+	}
+	if got := grandTotal(m.accountTotalsByAccount()); !approxEqual(got.equity, grand.equity) || !approxEqual(got.pnl, grand.pnl) {
+		t.Errorf("grand total after switching = %+v, want %+v", got, grand)
+	}
+
+	row := m.renderAccountTotals()
+	for _, part := range []string{"All: $2980.00 +599.00", "Main: $1000.00 +500.00", "Sub: $1980.00 +99.00"} {
+		if !strings.Contains(row, part) {
+			t.Errorf("totals row %q missing %q", row, part)
+		}
+	}
+	if risk := m.renderRiskSummary(); !strings.Contains(risk, "All accounts equity: $2980.00") {
+		t.Errorf("risk summary %q missing the grand total", risk)
+	}
+}
+
+func TestAccountTotalsPartialWithoutRate(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{
+		Accounts:      []string{"Main", "Sub"},
+		AccountTotals: true,
+		FXRates:       "USDC=live",
+	})
+	m = updateModel(m,
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 1000, Account: "Main"},
+		balanceUpdateMsg{Currency: "USDC", TotalEquity: 2000, Account: "Sub"},
+	)
+
+	grand := grandTotal(m.accountTotalsByAccount())
+	if !grand.partial || !approxEqual(grand.equity, 1000) {
+		t.Errorf("grand total = %+v, want 1000 marked partial until the USDC rate arrives", grand)
+	}
+	if row := m.renderAccountTotals(); !strings.HasPrefix(row, "~All:") {
+		t.Errorf("totals row %q not marked partial", row)
+	}
+}
+
+func TestAccountTotalsHiddenByDefault(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{Accounts: []string{"Main", "Sub"}})
+	if row := m.renderAccountTotals(); row != "" {
+		t.Errorf("totals row %q shown without the option", row)
+	}
+	m = newModelWithOptions(nil, nil, nil, Options{AccountTotals: true})
+	if row := m.renderAccountTotals(); row != "" {
+		t.Errorf("totals row %q shown with a single account", row)
+	}
+}
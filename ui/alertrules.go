@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// AlertRule overrides alert thresholds for instruments matching Inst, which is
+// an exact instrument ID or a wildcard pattern such as "*-USDT-SWAP"
+type AlertRule struct {
+	Inst       string  `json:"inst"`
+	LossPct    float64 `json:"loss_pct"`    // Loss alert threshold in %, 0 uses the global -loss-alert
+	GainPct    float64 `json:"gain_pct"`    // Gain alert threshold in %, 0 uses the global -gain-alert
+	PriceAbove float64 `json:"price_above"` // Alert when the price rises to this level, 0 disables
+	PriceBelow float64 `json:"price_below"` // Alert when the price falls to this level, 0 disables
+}
+
+// LoadAlertRules reads per-instrument alert rules from a JSON array file
+func LoadAlertRules(file string) ([]AlertRule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []AlertRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Inst, ""); err != nil || rule.Inst == "" {
+			return nil, fmt.Errorf("invalid alert rule instrument pattern %q", rule.Inst)
+		}
+	}
+	return rules, nil
+}
+
+// alertRuleFor returns the rule for an instrument. An exact match wins over
+// wildcards, and among wildcards the longest (most specific) pattern wins.
+func (m Model) alertRuleFor(instId string) (AlertRule, bool) {
+	var best AlertRule
+	found := false
+	for _, rule := range m.alertRules {
+		if rule.Inst == instId {
+			return rule, true
+		}
+		if !strings.ContainsAny(rule.Inst, "*?[") {
+			continue
+		}
+		if ok, _ := path.Match(rule.Inst, instId); ok && (!found || len(rule.Inst) > len(best.Inst)) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+// lossThreshold returns the loss alert threshold for an instrument, preferring
+// its rule over the global setting. 0 means no loss alert.
+func (m Model) lossThreshold(instId string) float64 {
+	if rule, ok := m.alertRuleFor(instId); ok && rule.LossPct > 0 {
+		return rule.LossPct
+	}
+	return m.lossAlertPct
+}
+
+// gainThreshold returns the gain alert threshold for an instrument, preferring
+// its rule over the global setting. 0 means no gain alert.
+func (m Model) gainThreshold(instId string) float64 {
+	if rule, ok := m.alertRuleFor(instId); ok && rule.GainPct > 0 {
+		return rule.GainPct
+	}
+	return m.gainAlertPct
+}
+
+// checkPriceAlerts fires a price alert once when a position's price reaches its
+// rule's level and rearms it when the price moves back
+func (m *Model) checkPriceAlerts() string {
+	if len(m.alertRules) == 0 {
+		return ""
+	}
+
+	fired := m.trackCrossings(m.priceAlerted, func(pos core.PositionData) bool {
+		rule, ok := m.alertRuleFor(pos.InstrumentID)
+		if !ok || pos.CurrentPrice <= 0 {
+			return false
+		}
+		return (rule.PriceAbove > 0 && pos.CurrentPrice >= rule.PriceAbove) ||
+			(rule.PriceBelow > 0 && pos.CurrentPrice <= rule.PriceBelow)
+	})
+	if len(fired) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, pos := range fired {
+		parts = append(parts, fmt.Sprintf("%s @ %s", pos.InstrumentID, m.formatPrice(pos.CurrentPrice)))
+	}
+	return fmt.Sprintf("Price alert: %s", strings.Join(parts, ", "))
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestAlertRulePrecedence(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.lossAlertPct, m.gainAlertPct = 10, 20
+	m.alertRules = []AlertRule{
+		{Inst: "*", LossPct: 8},
+		{Inst: "*-USDT-SWAP", LossPct: 3, GainPct: 6},
+		{Inst: "BTC-USDT-SWAP", LossPct: 15},
+		{Inst: "ETH-*", PriceAbove: 4000},
+	}
+
+	tests := []struct {
+		instId     string
+		loss, gain float64
+	}{
+		{"BTC-USDT-SWAP", 15, 20},  // Exact match wins, its unset gain falls back to the global one
+		{"SOL-USDT-SWAP", 3, 6},    // Longest wildcard beats "*"
+		{"ETH-USDT-SWAP", 3, 6},    // "*-USDT-SWAP" is longer than "ETH-*"
+		{"ETH-USD-SWAP", 10, 20},   // "ETH-*" has no thresholds, so the globals apply
+		{"DOGE-USD-250328", 8, 20}, // Only "*" matches
+	}
+	for _, tt := range tests {
+		if loss, gain := m.lossThreshold(tt.instId), m.gainThreshold(tt.instId); loss != tt.loss || gain != tt.gain {
+			t.Errorf("%s: thresholds = %v/%v, want %v/%v", tt.instId, loss, gain, tt.loss, tt.gain)
+		}
+	}
+
+	m.alertRules = nil
+	if loss, gain := m.lossThreshold("BTC-USDT-SWAP"), m.gainThreshold("BTC-USDT-SWAP"); loss != 10 || gain != 20 {
+		t.Errorf("without rules thresholds = %v/%v, want the globals 10/20", loss, gain)
+	}
+}
+
+func TestAlertRulesFireLossAndPriceAlerts(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.lossAlertPct = 50
+	m.alertRules = []AlertRule{
+		{Inst: "*-USDT-SWAP", LossPct: 5},
+		{Inst: "ETH-USDT-SWAP", PriceBelow: 2900},
+	}
+
+	btc := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 48000, -2000)
+	btc.PnLRatio = -6
+	eth := testPosition("ETH-USDT-SWAP", "long", 1, 3000, 2950, -50)
+	eth.PnLRatio = -1
+	m.positions["BTC-USDT-SWAP-long"] = core.PositionData(btc)
+	m.positions["ETH-USDT-SWAP-long"] = core.PositionData(eth)
+
+	if reason := m.checkLossAlerts(); reason != "Loss alert: BTC-USDT-SWAP long -6.00%" {
+		t.Errorf("loss alerts = %q, want BTC past its 5%% rule", reason)
+	}
+	if reason := m.checkPriceAlerts(); reason != "" {
+		t.Errorf("price alerts = %q before ETH reached 2900", reason)
+	}
+
+	eth.CurrentPrice = 2890
+	m.positions["ETH-USDT-SWAP-long"] = core.PositionData(eth)
+	if reason := m.checkPriceAlerts(); reason != "Price alert: ETH-USDT-SWAP @ 2890.00" {
+		t.Errorf("price alerts = %q, want ETH at its level", reason)
+	}
+	if reason := m.checkPriceAlerts(); reason != "" {
+		t.Errorf("price alert fired again while still below: %q", reason)
+	}
+}
+
+func TestLoadAlertRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	rules, err := LoadAlertRules(write("ok.json", `[{"inst":"*-USDT-SWAP","loss_pct":3},{"inst":"BTC-USDT-SWAP","price_above":70000}]`))
+	if err != nil || len(rules) != 2 || rules[0].LossPct != 3 || rules[1].PriceAbove != 70000 {
+		t.Fatalf("LoadAlertRules() = %+v, %v", rules, err)
+	}
+
+	for name, content := range map[string]string{
+		"empty.json":   `[{"loss_pct":3}]`,
+		"pattern.json": `[{"inst":"[BTC","loss_pct":3}]`,
+		"broken.json":  `{"inst":`,
+	} {
+		if _, err := LoadAlertRules(write(name, content)); err == nil {
+			t.Errorf("%s: loaded %s without error", name, content)
+		}
+	}
+	if _, err := LoadAlertRules(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("missing file error = %v", err)
+	}
+}
@@ -0,0 +1,363 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+const (
+	// toastDuration is how long a transient notification stays visible
+	toastDuration = 5 * time.Second
+
+	// manualNavOverride suppresses alert auto-selection after manual navigation
+	manualNavOverride = 10 * time.Second
+
+	// webhookTimeout bounds how long an alert webhook request may take
+	webhookTimeout = 5 * time.Second
+
+	// alertFlashDuration is how long a card's border stays highlighted after
+	// its PnL alert fires
+	alertFlashDuration = 3 * time.Second
+
+	// defaultAlertHysteresis is how many PnL % points a position must move
+	// back past a loss or gain threshold before that alert can fire again
+	defaultAlertHysteresis = 1.0
+)
+
+// alertFlash highlights a card after a PnL alert, green for gains, red for losses
+type alertFlash struct {
+	until time.Time
+	gain  bool
+}
+
+var (
+	toastStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("214")).
+			Bold(true).
+			Padding(0, 1)
+
+	alertBannerStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196")).
+				Bold(true)
+
+	// Card borders while a PnL alert flashes
+	gainFlashCardStyle = cardStyle.Copy().BorderForeground(lipgloss.Color("46"))
+	lossFlashCardStyle = cardStyle.Copy().BorderForeground(lipgloss.Color("196"))
+)
+
+// checkAlerts evaluates all position alerts and returns the commands for any that fired
+func (m *Model) checkAlerts() tea.Cmd {
+	var fired []string
+	if reason := m.checkLossAlerts(); reason != "" {
+		fired = append(fired, reason)
+	}
+	if reason := m.checkGainAlerts(); reason != "" {
+		fired = append(fired, reason)
+	}
+	if reason := m.checkMarginAlerts(); reason != "" {
+		fired = append(fired, reason)
+	}
+	if reason := m.checkLiqAlerts(); reason != "" {
+		fired = append(fired, reason)
+	}
+	if reason := m.checkPriceAlerts(); reason != "" {
+		fired = append(fired, reason)
+	}
+
+	if len(fired) == 0 {
+		return nil
+	}
+	return m.fireAlert(strings.Join(fired, " | "))
+}
+
+// checkLossAlerts fires a large-loss alert once when a position crosses its
+// threshold and rearms it once the position recovers past the hysteresis band
+func (m *Model) checkLossAlerts() string {
+	if m.lossAlertPct <= 0 && len(m.alertRules) == 0 {
+		return ""
+	}
+
+	fired := m.trackHysteresis(m.alerted, func(pos core.PositionData) bool {
+		threshold := m.lossThreshold(pos.InstrumentID)
+		return threshold > 0 && pos.PnLRatio <= -threshold
+	}, func(pos core.PositionData) bool {
+		threshold := m.lossThreshold(pos.InstrumentID)
+		return threshold <= 0 || pos.PnLRatio > -threshold+m.alertHysteresis
+	})
+	if len(fired) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, pos := range fired {
+		m.flashAlert(pos, false)
+		parts = append(parts, fmt.Sprintf("%s %s %s%%", pos.InstrumentID, pos.PositionSide, m.formatFixed(pos.PnLRatio, 2)))
+	}
+	return fmt.Sprintf("Loss alert: %s", strings.Join(parts, ", "))
+}
+
+// checkGainAlerts fires a gain alert once when a position's PnL % rises to its
+// threshold and rearms it once the position pulls back past the hysteresis band
+func (m *Model) checkGainAlerts() string {
+	if m.gainAlertPct <= 0 && len(m.alertRules) == 0 {
+		return ""
+	}
+
+	fired := m.trackHysteresis(m.gainAlerted, func(pos core.PositionData) bool {
+		threshold := m.gainThreshold(pos.InstrumentID)
+		return threshold > 0 && pos.PnLRatio >= threshold
+	}, func(pos core.PositionData) bool {
+		threshold := m.gainThreshold(pos.InstrumentID)
+		return threshold <= 0 || pos.PnLRatio < threshold-m.alertHysteresis
+	})
+	if len(fired) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, pos := range fired {
+		m.flashAlert(pos, true)
+		parts = append(parts, fmt.Sprintf("%s %s +%s%%", pos.InstrumentID, pos.PositionSide, m.formatFixed(pos.PnLRatio, 2)))
+	}
+	return fmt.Sprintf("Gain alert: %s", strings.Join(parts, ", "))
+}
+
+// checkMarginAlerts fires a margin-ratio alert once when a position's margin ratio
+// drops below the threshold and rearms it when the ratio recovers
+func (m *Model) checkMarginAlerts() string {
+	if m.marginAlertPct <= 0 {
+		return ""
+	}
+
+	// Positions without a reported margin ratio never alert
+	fired := m.trackCrossings(m.marginAlerted, func(pos core.PositionData) bool {
+		return pos.MarginRatio > 0 && pos.MarginRatio < m.marginAlertPct
+	})
+	if len(fired) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, pos := range fired {
+		parts = append(parts, fmt.Sprintf("%s %s %s%%", pos.InstrumentID, pos.PositionSide, m.formatFixed(pos.MarginRatio, 0)))
+	}
+	return fmt.Sprintf("Margin ratio below %.0f%%: %s", m.marginAlertPct, strings.Join(parts, ", "))
+}
+
+// checkLiqAlerts fires a liquidation alert once when a position comes within
+// the threshold of its liquidation price and rearms it when it moves away
+func (m *Model) checkLiqAlerts() string {
+	if m.liqAlertPct <= 0 {
+		return ""
+	}
+
+	// Positions without a reported liquidation price never alert
+	fired := m.trackCrossings(m.liqAlerted, func(pos core.PositionData) bool {
+		distance, ok := liqDistancePct(pos)
+		return ok && distance <= m.liqAlertPct
+	})
+	if len(fired) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, pos := range fired {
+		distance, _ := liqDistancePct(pos)
+		parts = append(parts, fmt.Sprintf("%s %s %s%% away", pos.InstrumentID, pos.PositionSide, m.formatFixed(distance, 1)))
+	}
+	return fmt.Sprintf("Liquidation within %s%%: %s", m.formatFixed(m.liqAlertPct, 1), strings.Join(parts, ", "))
+}
+
+// trackCrossings updates per-position alert state and returns the positions that
+// newly entered the alert condition. State for recovered or closed positions is cleared.
+func (m *Model) trackCrossings(state map[string]bool, inAlert func(core.PositionData) bool) []core.PositionData {
+	return m.trackHysteresis(state, inAlert, func(pos core.PositionData) bool { return !inAlert(pos) })
+}
+
+// trackHysteresis is trackCrossings where a position stays in alert until
+// rearm reports it has moved back far enough, so a value hovering around the
+// threshold alerts once instead of on every crossing
+func (m *Model) trackHysteresis(state map[string]bool, inAlert, rearm func(core.PositionData) bool) []core.PositionData {
+	var fired []core.PositionData
+	for key, pos := range m.positions {
+		if inAlert(pos) {
+			if !state[key] {
+				fired = append(fired, pos)
+			}
+			state[key] = true
+		} else if rearm(pos) {
+			delete(state, key)
+		}
+	}
+
+	// Prune alert state for positions that no longer exist
+	for key := range state {
+		if _, exists := m.positions[key]; !exists {
+			delete(state, key)
+		}
+	}
+
+	sort.Slice(fired, func(i, j int) bool {
+		return fired[i].InstrumentID < fired[j].InstrumentID
+	})
+	return fired
+}
+
+// fireAlert shows and logs the alert, optionally jumps to the worst position,
+// and returns commands ringing the terminal bell, playing the alert sound,
+// posting the webhook and showing the desktop notification
+func (m *Model) fireAlert(reason string) tea.Cmd {
+	m.AddDebugMessage(reason)
+	m.logAlert(reason)
+
+	if m.alertSelect && time.Since(m.lastManualNav) > manualNavOverride {
+		m.selectWorstPosition()
+		reason += " (selected worst PnL)"
+	}
+
+	m.showToast(reason)
+
+	var bell, desktop tea.Cmd
+	if !m.headless {
+		bell = ringBell()
+	}
+	if m.alertDesktop {
+		desktop = notifyDesktop(reason)
+	}
+	return tea.Batch(bell, m.sound.play(), postAlertWebhook(m.alertWebhook, reason), desktop)
+}
+
+// logAlert writes the alert with its time to the alert log, if there is one
+func (m Model) logAlert(text string) {
+	if m.alertLog == nil {
+		return
+	}
+	fmt.Fprintf(m.alertLog, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), text)
+}
+
+// flashAlert highlights a position's card for a few seconds after its PnL
+// alert fires, dropping the highlights that have run out
+func (m *Model) flashAlert(pos core.PositionData, gain bool) {
+	now := time.Now()
+	for key, flash := range m.alertFlashes {
+		if now.After(flash.until) {
+			delete(m.alertFlashes, key)
+		}
+	}
+	m.alertFlashes[fmt.Sprintf("%s-%s", pos.InstrumentID, pos.PositionSide)] = alertFlash{until: now.Add(alertFlashDuration), gain: gain}
+}
+
+// alertCardStyle returns the highlighted card style while a position's alert
+// flash lasts, reporting false otherwise
+func (m Model) alertCardStyle(pos core.PositionData) (lipgloss.Style, bool) {
+	flash, ok := m.alertFlashes[fmt.Sprintf("%s-%s", pos.InstrumentID, pos.PositionSide)]
+	if !ok || time.Now().After(flash.until) {
+		return lipgloss.Style{}, false
+	}
+	if flash.gain {
+		return gainFlashCardStyle, true
+	}
+	return lossFlashCardStyle, true
+}
+
+// renderAlertBanner renders the persistent banner for positions in margin alert
+func (m Model) renderAlertBanner() string {
+	if len(m.marginAlerted) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for key := range m.marginAlerted {
+		if pos, ok := m.positions[key]; ok {
+			parts = append(parts, fmt.Sprintf("%s %s %s%%", pos.InstrumentID, pos.PositionSide, m.formatFixed(pos.MarginRatio, 0)))
+		}
+	}
+	sort.Strings(parts)
+
+	return alertBannerStyle.Render(fmt.Sprintf("⚠ Margin ratio below %.0f%%: %s", m.marginAlertPct, strings.Join(parts, ", ")))
+}
+
+// selectWorstPosition selects the position with the lowest PnL and scrolls it into view
+func (m *Model) selectWorstPosition() {
+	groups := m.positionGroups()
+	if len(groups) == 0 {
+		return
+	}
+
+	worst, worstPnL := 0, groups[0][0].PnL
+	for i, group := range groups {
+		for _, pos := range group {
+			if pos.PnL < worstPnL {
+				worst, worstPnL = i, pos.PnL
+			}
+		}
+	}
+	m.selected = worst
+
+	// Scroll so the selected card's row is at the top of the view
+	cardHeight := lipgloss.Height(m.renderGroupCard(groups[worst], true))
+	m.scrollOffset = (worst / m.cardsPerRow()) * cardHeight
+
+	lines := strings.Split(m.renderPositionCards(), "\n")
+	availableHeight := m.height - 10
+	if availableHeight < 5 {
+		availableHeight = 5
+	}
+	maxScroll := len(lines) - availableHeight
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if m.scrollOffset > maxScroll {
+		m.scrollOffset = maxScroll
+	}
+}
+
+// showToast shows a transient notification in the footer
+func (m *Model) showToast(msg string) {
+	m.toastMsg = msg
+	m.toastUntil = time.Now().Add(toastDuration)
+}
+
+// ringBell rings the terminal bell
+func ringBell() tea.Cmd {
+	return func() tea.Msg {
+		fmt.Fprint(os.Stderr, "\a")
+		return nil
+	}
+}
+
+// postAlertWebhook posts the alert text as JSON to the webhook URL, if configured
+func postAlertWebhook(url, text string) tea.Cmd {
+	if url == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		body, err := json.Marshal(map[string]string{"text": text})
+		if err != nil {
+			return errorMsg(fmt.Sprintf("DEBUG: Alert webhook failed: %v", err))
+		}
+
+		client := &http.Client{Timeout: webhookTimeout}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return errorMsg(fmt.Sprintf("DEBUG: Alert webhook failed: %v", err))
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			return errorMsg(fmt.Sprintf("DEBUG: Alert webhook returned %s", resp.Status))
+		}
+		return nil
+	}
+}
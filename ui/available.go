@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// negativeAvailMode controls how a negative available balance is displayed
+type negativeAvailMode int
+
+const (
+	negativeAvailShow  negativeAvailMode = iota // Show the reported negative value, flagged
+	negativeAvailClamp                          // Show zero, flagged
+)
+
+// negativeAvailModeNames maps negative balance modes to their flag names
+var negativeAvailModeNames = []string{"show", "clamp"}
+
+// parseNegativeAvailMode parses a negative available balance mode such as "clamp"
+func parseNegativeAvailMode(value string) (negativeAvailMode, error) {
+	if value == "" {
+		return negativeAvailShow, nil
+	}
+	for i, name := range negativeAvailModeNames {
+		if value == name {
+			return negativeAvailMode(i), nil
+		}
+	}
+	return negativeAvailShow, fmt.Errorf("invalid negative balance mode %q, use one of: %s", value, strings.Join(negativeAvailModeNames, ", "))
+}
+
+// availableBalance sums available balance across all currencies
+func (m Model) availableBalance() float64 {
+	var total float64
+	for _, balance := range m.balances {
+		total += balance.AvailBalance
+	}
+	return total
+}
+
+// renderAvailable renders the available balance and the share of equity in use.
+// A negative available balance, possible in some margin states, is flagged in
+// red and the usage is capped at 100% rather than exceeding it. Without equity
+// nothing is in use unless the available balance is negative.
+func (m Model) renderAvailable(equity float64) string {
+	avail := m.availableBalance()
+
+	var usedPct float64
+	if equity > 0 {
+		usedPct = (equity - avail) / equity * 100
+	} else if avail < 0 {
+		usedPct = 100
+	}
+	if usedPct > 100 {
+		usedPct = 100
+	} else if usedPct < 0 {
+		usedPct = 0
+	}
+	usage := labelStyle.Render(fmt.Sprintf("(%s%% used)", m.formatFixed(usedPct, 1)))
+
+	if avail >= 0 {
+		return fmt.Sprintf("%s %s %s", labelStyle.Render("Avail:"), valueStyle.Render(m.formatFixed(avail, 2)), usage)
+	}
+
+	shown := avail
+	if m.negativeAvail == negativeAvailClamp {
+		shown = 0
+	}
+	return fmt.Sprintf("%s %s %s", labelStyle.Render("Avail:"),
+		negativeStyle.Render(fmt.Sprintf("%s ⚠ negative", m.formatFixed(shown, 2))), usage)
+}
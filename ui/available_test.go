@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderAvailable(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      negativeAvailMode
+		avail     float64
+		equity    float64
+		want      []string
+		wantNoNeg bool
+	}{
+		{"positive", negativeAvailShow, 400, 1000, []string{"Avail: 400.00", "(60.0% used)"}, true},
+		{"negative shown", negativeAvailShow, -250, 1000, []string{"-250.00 ⚠ negative", "(100.0% used)"}, false},
+		{"negative clamped", negativeAvailClamp, -250, 1000, []string{"0.00 ⚠ negative", "(100.0% used)"}, false},
+		{"more available than equity", negativeAvailShow, 1200, 1000, []string{"(0.0% used)"}, true},
+		{"empty account", negativeAvailShow, 0, 0, []string{"Avail: 0.00", "(0.0% used)"}, true},
+		{"negative without equity", negativeAvailShow, -5, 0, []string{"-5.00 ⚠ negative", "(100.0% used)"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(nil, nil, nil)
+			m.negativeAvail = tt.mode
+			m = updateModel(m, balanceUpdateMsg{Currency: "USDT", TotalEquity: tt.equity, AvailBalance: tt.avail})
+
+			got := m.renderAvailable(tt.equity)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("renderAvailable() = %q, want it to contain %q", got, want)
+				}
+			}
+			if strings.Contains(got, "NaN") || strings.Contains(got, "Inf") {
+				t.Errorf("renderAvailable() = %q, want a finite usage", got)
+			}
+			if tt.wantNoNeg && strings.Contains(got, "⚠") {
+				t.Errorf("renderAvailable() = %q flags a balance that is not negative", got)
+			}
+		})
+	}
+}
+
+func TestAvailableKPIFlagsNegative(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m = updateModel(m, balanceUpdateMsg{Currency: "USDT", TotalEquity: 100, AvailBalance: -30})
+	if got := accountKPIs["available"].render(m); !strings.Contains(got, "-30.00 ⚠") {
+		t.Errorf("available KPI = %q, want the negative value flagged", got)
+	}
+	m.negativeAvail = negativeAvailClamp
+	if got := accountKPIs["available"].render(m); !strings.Contains(got, "0.00 ⚠") || strings.Contains(got, "-") {
+		t.Errorf("clamped available KPI = %q, want 0.00 flagged", got)
+	}
+}
+
+func TestParseNegativeAvailMode(t *testing.T) {
+	for value, want := range map[string]negativeAvailMode{"": negativeAvailShow, "show": negativeAvailShow, "clamp": negativeAvailClamp} {
+		if got, err := parseNegativeAvailMode(value); err != nil || got != want {
+			t.Errorf("parseNegativeAvailMode(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseNegativeAvailMode("hide"); err == nil {
+		t.Error("parseNegativeAvailMode(hide) accepted an unknown mode")
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// balanceBaselineMode controls what the balance color is compared against
+type balanceBaselineMode int
+
+const (
+	balanceBaselineTick    balanceBaselineMode = iota // Last rendered balance, flips on every move
+	balanceBaselineSession                            // Equity at the first balance update of the session
+)
+
+// balanceBaselineModeNames maps balance baseline modes to their flag names
+var balanceBaselineModeNames = []string{"tick", "session"}
+
+// parseBalanceBaselineMode parses a balance baseline mode such as "session"
+func parseBalanceBaselineMode(value string) (balanceBaselineMode, error) {
+	if value == "" {
+		return balanceBaselineTick, nil
+	}
+	for i, name := range balanceBaselineModeNames {
+		if value == name {
+			return balanceBaselineMode(i), nil
+		}
+	}
+	return balanceBaselineTick, fmt.Errorf("invalid balance baseline %q, use one of: %s", value, strings.Join(balanceBaselineModeNames, ", "))
+}
+
+// renderedBalance is the total balance of the last rendered frame, the baseline
+// the next change is colored against. It is committed by the render itself, so
+// updates arriving between two frames only count with their net change, and is
+// shared by pointer like viewCache since Bubble Tea copies the model.
+type renderedBalance struct {
+	total float64 // Balance shown, 0 before the first
+	trend int     // Net direction of the last shown change: 1 up, -1 down, 0 flat
+}
+
+// commit records the balance of a rendered frame with the trend it was shown
+// with. The first balance has nothing to compare with, so it keeps the trend flat.
+func (r *renderedBalance) commit(total float64, trend int) {
+	if r.total > 0 {
+		r.trend = trend
+	}
+	r.total = total
+}
+
+// recordSessionStart stores the equity of the first balance update as the
+// session baseline
+func (m *Model) recordSessionStart() {
+	if m.sessionStartEquity == 0 {
+		m.sessionStartEquity = m.totalEquity()
+	}
+}
+
+// balanceColorTrend returns the direction used to color the balance: against
+// the session start in session mode, otherwise against the last rendered frame.
+// It reports false when there is no baseline to compare with yet.
+func (m Model) balanceColorTrend(total float64) (int, bool) {
+	if m.balanceBaseline == balanceBaselineSession {
+		if m.sessionStartEquity <= 0 {
+			return 0, false
+		}
+		switch {
+		case total > m.sessionStartEquity:
+			return 1, true
+		case total < m.sessionStartEquity:
+			return -1, true
+		}
+		return 0, true
+	}
+
+	if m.rendered.total <= 0 {
+		return 0, false
+	}
+	return m.pendingBalanceTrend(total), true
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"testing"
+)
+
+func TestBalanceTrendFollowsRenderedFrames(t *testing.T) {
+	usdt := func(equity float64) balanceUpdateMsg {
+		return balanceUpdateMsg{Currency: "USDT", TotalEquity: equity, AvailBalance: equity}
+	}
+
+	type frame struct {
+		updates   []float64 // USDT equity updates arriving before the frame
+		wantTrend int
+	}
+	tests := []struct {
+		name   string
+		frames []frame
+	}{
+		{
+			name: "back-to-back updates count by their net change",
+			frames: []frame{
+				{[]float64{1000}, 0},
+				{[]float64{1010, 1005}, 1}, // Net +5 although the last step is down
+				{[]float64{1004, 1006}, 1}, // Net +1
+				{[]float64{1008, 1003}, -1},
+			},
+		},
+		{
+			name: "unchanged frames keep the last trend",
+			frames: []frame{
+				{[]float64{1000}, 0},
+				{[]float64{990}, -1},
+				{nil, -1},
+				{[]float64{995, 990}, -1}, // Back to the rendered total
+			},
+		},
+		{
+			name: "updates that cancel out between frames don't flip it",
+			frames: []frame{
+				{[]float64{1000}, 0},
+				{[]float64{1001}, 1},
+				{[]float64{990, 1001}, 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(nil, nil, nil)
+			for i, f := range tt.frames {
+				for _, equity := range f.updates {
+					m = updateModel(m, usdt(equity))
+				}
+				_ = m.View()
+				if m.rendered.trend != f.wantTrend {
+					t.Errorf("frame %d: trend = %d, want %d", i+1, m.rendered.trend, f.wantTrend)
+				}
+			}
+		})
+	}
+}
+
+func TestBalanceBaselineOnlyMovesOnRender(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m = updateModel(m, balanceUpdateMsg{Currency: "USDT", TotalEquity: 1000})
+	_ = m.View()
+
+	// Ticks and updates alone leave the baseline at the shown balance
+	m = updateModel(m, balanceUpdateMsg{Currency: "USDT", TotalEquity: 1020}, tickMsg{}, tickMsg{})
+	if m.rendered.total != 1000 {
+		t.Fatalf("baseline = %v before the next frame, want the rendered 1000", m.rendered.total)
+	}
+	if trend, ok := m.balanceColorTrend(1020); !ok || trend != 1 {
+		t.Errorf("pending trend = %d (%v), want up against the rendered frame", trend, ok)
+	}
+
+	_ = m.View()
+	if m.rendered.total != 1020 {
+		t.Errorf("baseline = %v after rendering, want 1020", m.rendered.total)
+	}
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBufferCap is the most samples the per-instrument history buffers
+// hold in total before the least recently updated instruments are evicted
+const defaultBufferCap = 100000
+
+// bufferLimits caps each kind of history buffer
+type bufferLimits struct {
+	price  int // Samples per instrument price ring
+	pnl    int // Samples per position PnL history
+	trades int // Prints per instrument trades buffer
+	equity int // Buckets of the equity history
+}
+
+// defaultBufferLimits are the per-buffer caps unless configured
+var defaultBufferLimits = bufferLimits{
+	price:  defaultPriceSamples,
+	pnl:    1000,
+	trades: 50,
+	equity: defaultEquityBuckets,
+}
+
+// parseBufferLimits parses comma-separated per-buffer caps like
+// "price=240,trades=20" on top of the defaults. An empty value keeps them all.
+func parseBufferLimits(value string) (bufferLimits, error) {
+	limits := defaultBufferLimits
+	fields := map[string]*int{
+		"price":  &limits.price,
+		"pnl":    &limits.pnl,
+		"trades": &limits.trades,
+		"equity": &limits.equity,
+	}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(part, "=")
+		field, known := fields[strings.ToLower(strings.TrimSpace(name))]
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if !ok || !known || err != nil || n < 2 {
+			return defaultBufferLimits, fmt.Errorf("invalid buffer limit %q, use price, pnl, trades or equity=N with N of at least 2", part)
+		}
+		*field = n
+	}
+	return limits, nil
+}
+
+// bufferStats counts the history buffers and the samples they hold
+type bufferStats struct {
+	priceRings int
+	pnlSeries  int
+	tradeBufs  int
+	samples    int // Samples across price, PnL and trade buffers
+	equity     int // Equity history buckets
+}
+
+// bufferStats counts the samples held in the history buffers
+func (m Model) bufferStats() bufferStats {
+	stats := bufferStats{
+		priceRings: len(m.priceRings),
+		pnlSeries:  len(m.pnlHistories),
+		tradeBufs:  len(m.trades),
+		equity:     len(m.equity.buckets),
+	}
+	for _, ring := range m.priceRings {
+		stats.samples += ring.count
+	}
+	for _, history := range m.pnlHistories {
+		stats.samples += len(history.samples)
+	}
+	for _, trades := range m.trades {
+		stats.samples += len(trades)
+	}
+	return stats
+}
+
+// enforceBufferCap evicts the history buffers of the least recently updated
+// instruments until the samples they hold fit the global cap. Instruments
+// without an open position go first; evicted ones start over if they update.
+func (m *Model) enforceBufferCap() {
+	if m.bufferCap <= 0 {
+		return
+	}
+	total := m.bufferStats().samples
+	if total <= m.bufferCap {
+		return
+	}
+
+	held := make(map[string]bool)
+	for _, pos := range m.positions {
+		held[pos.InstrumentID] = true
+	}
+	var instIds []string
+	for instId := range m.priceRings {
+		instIds = append(instIds, instId)
+	}
+	for instId := range m.trades {
+		if _, ok := m.priceRings[instId]; !ok {
+			instIds = append(instIds, instId)
+		}
+	}
+	sort.Slice(instIds, func(i, j int) bool {
+		a, b := instIds[i], instIds[j]
+		if held[a] != held[b] {
+			return !held[a]
+		}
+		return m.lastSeen[a].Before(m.lastSeen[b])
+	})
+
+	evicted := 0
+	for _, instId := range instIds {
+		if total <= m.bufferCap {
+			break
+		}
+		total -= m.evictInstrumentBuffers(instId)
+		evicted++
+	}
+	m.AddDebugMessage(fmt.Sprintf("History buffers over %d samples, evicted %d least recently updated instruments", m.bufferCap, evicted))
+}
+
+// evictInstrumentBuffers drops an instrument's price, trade and PnL history,
+// returning how many samples were freed
+func (m *Model) evictInstrumentBuffers(instId string) int {
+	freed := 0
+	if ring, ok := m.priceRings[instId]; ok {
+		freed += ring.count
+		delete(m.priceRings, instId)
+	}
+	freed += len(m.trades[instId])
+	delete(m.trades, instId)
+	for key, history := range m.pnlHistories {
+		if pos, ok := m.positions[key]; ok && pos.InstrumentID == instId {
+			freed += len(history.samples)
+			delete(m.pnlHistories, key)
+		}
+	}
+	return freed
+}
+
+// sampleHeap records the heap in use for the buffer diagnostics. Reading it
+// briefly stops the world, so it is sampled on the clock tick, not per frame.
+func (m *Model) sampleHeap() {
+	if !m.showDebug || time.Since(m.heapSampledAt) < time.Second {
+		return
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	m.heapInUse = mem.HeapInuse
+	m.heapSampledAt = time.Now()
+}
+
+// renderBufferStats renders the history buffer and heap diagnostics for the
+// debug pane
+func (m Model) renderBufferStats() string {
+	stats := m.bufferStats()
+	capText := "uncapped"
+	if m.bufferCap > 0 {
+		capText = fmt.Sprintf("cap %d", m.bufferCap)
+	}
+	return fmt.Sprintf("Buffers: %d price, %d PnL, %d trades · %d samples (%s) · equity %d/%d · heap %.1f MB",
+		stats.priceRings, stats.pnlSeries, stats.tradeBufs, stats.samples, capText,
+		stats.equity, m.equity.limit, float64(m.heapInUse)/(1<<20))
+}
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestParseBufferLimits(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bufferLimits
+		wantErr bool
+	}{
+		{"", defaultBufferLimits, false},
+		{"price=240, Trades=20", bufferLimits{price: 240, pnl: defaultBufferLimits.pnl, trades: 20, equity: defaultBufferLimits.equity}, false},
+		{"pnl=2,equity=10", bufferLimits{price: defaultBufferLimits.price, pnl: 2, trades: defaultBufferLimits.trades, equity: 10}, false},
+		{"prices=100", defaultBufferLimits, true},
+		{"price=1", defaultBufferLimits, true},
+		{"price", defaultBufferLimits, true},
+		{"price=lots", defaultBufferLimits, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBufferLimits(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseBufferLimits(%q) = %+v, %v; want %+v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBuffersRespectTheirCaps(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.bufLimits = bufferLimits{price: 5, pnl: 4, trades: 3, equity: 6}
+
+	t.Run("price ring keeps the newest", func(t *testing.T) {
+		for i := 1; i <= 20; i++ {
+			m.recordPrice("BTC-USDT-SWAP", float64(i))
+		}
+		ring := m.priceRings["BTC-USDT-SWAP"]
+		if ring.count != 5 || len(ring.samples) != 5 {
+			t.Fatalf("price ring holds %d of %d samples, want 5", ring.count, len(ring.samples))
+		}
+		oldest, latest, _ := ring.Span(time.Time{})
+		if oldest.price != 16 || latest.price != 20 {
+			t.Errorf("price ring spans %v to %v, want 16 to 20", oldest.price, latest.price)
+		}
+	})
+
+	t.Run("trades keep the newest", func(t *testing.T) {
+		for i := 1; i <= 10; i++ {
+			m = updateModel(m, tradeUpdateMsg{InstrumentID: "BTC-USDT-SWAP", TradeID: fmt.Sprint(i)})
+		}
+		var ids []string
+		for _, trade := range m.trades["BTC-USDT-SWAP"] {
+			ids = append(ids, trade.TradeID)
+		}
+		if fmt.Sprint(ids) != "[8 9 10]" {
+			t.Errorf("trades buffer = %v, want the last 3 prints", ids)
+		}
+	})
+
+	t.Run("PnL history keeps the newest", func(t *testing.T) {
+		history := &pnlHistory{limit: m.bufLimits.pnl}
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			history.Add(start.Add(time.Duration(i)*pnlSampleSpacing), float64(i), time.Hour)
+		}
+		var pnls []float64
+		for _, sample := range history.samples {
+			pnls = append(pnls, sample.pnl)
+		}
+		if fmt.Sprint(pnls) != "[6 7 8 9]" {
+			t.Errorf("PnL history = %v, want the last 4 samples", pnls)
+		}
+	})
+
+	t.Run("equity history merges the oldest", func(t *testing.T) {
+		history := newEquityHistory(time.Second, m.bufLimits.equity)
+		start := time.Now().Truncate(time.Second)
+		for i := 0; i < 50; i++ {
+			history.Add(start.Add(time.Duration(i)*time.Second), float64(i))
+		}
+		n := len(history.buckets)
+		if n > 6 {
+			t.Fatalf("equity history holds %d buckets, want at most 6", n)
+		}
+		// Merging keeps the whole session, only at lower resolution
+		if !history.buckets[0].Start.Equal(start) || history.buckets[0].Min != 0 {
+			t.Errorf("oldest bucket = %+v, want the session start", history.buckets[0])
+		}
+		if last := history.buckets[n-1]; last.Last != 49 || last.Start.Sub(history.buckets[n-2].Start) != time.Second {
+			t.Errorf("newest bucket = %+v, want the last sample at full resolution", last)
+		}
+	})
+}
+
+func TestEnforceBufferCapEvictionOrder(t *testing.T) {
+	now := time.Now()
+	setup := func(bufferCap int) Model {
+		m := NewModel(nil, nil, nil)
+		m.bufferCap = bufferCap
+		m.positions["HELD-USDT-SWAP-long"] = core.PositionData{InstrumentID: "HELD-USDT-SWAP", PositionSide: "long", Size: 1}
+		seen := map[string]time.Duration{
+			"HELD-USDT-SWAP":   -time.Hour, // Least recently updated, but held
+			"OLD-USDT-SWAP":    -time.Minute,
+			"RECENT-USDT-SWAP": -time.Second,
+		}
+		for instId, ago := range seen {
+			m.lastSeen[instId] = now.Add(ago)
+			for i := 1; i <= 4; i++ {
+				m.recordPrice(instId, float64(i))
+			}
+		}
+		// Trades alone count towards the cap too
+		m.lastSeen["TRADES-USDT-SWAP"] = now.Add(-30 * time.Second)
+		m.trades["TRADES-USDT-SWAP"] = make([]core.TradeData, 4)
+		return m
+	}
+	remaining := func(m Model) string {
+		var instIds []string
+		for instId := range m.priceRings {
+			instIds = append(instIds, instId)
+		}
+		for instId := range m.trades {
+			instIds = append(instIds, instId)
+		}
+		sort.Strings(instIds)
+		return strings.Join(instIds, " ")
+	}
+
+	tests := []struct {
+		cap  int
+		want string
+	}{
+		{16, "HELD-USDT-SWAP OLD-USDT-SWAP RECENT-USDT-SWAP TRADES-USDT-SWAP"}, // Within the cap
+		{12, "HELD-USDT-SWAP RECENT-USDT-SWAP TRADES-USDT-SWAP"},               // Oldest unheld first
+		{8, "HELD-USDT-SWAP RECENT-USDT-SWAP"},
+		{4, "HELD-USDT-SWAP"}, // Held instruments go last
+		{1, ""},
+		{-1, "HELD-USDT-SWAP OLD-USDT-SWAP RECENT-USDT-SWAP TRADES-USDT-SWAP"}, // Uncapped
+	}
+	for _, tt := range tests {
+		m := setup(tt.cap)
+		m.enforceBufferCap()
+		if got := remaining(m); got != tt.want {
+			t.Errorf("cap %d kept %q, want %q", tt.cap, got, tt.want)
+		}
+		if samples := m.bufferStats().samples; tt.cap > 0 && samples > tt.cap {
+			t.Errorf("cap %d left %d samples", tt.cap, samples)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// cardField renders one labelled line of a position card
+type cardField struct {
+	label  string
+	render func(m Model, pos core.PositionData) string
+}
+
+// defaultCardFields matches the original fixed card layout
+var defaultCardFields = []string{"side", "size", "entry", "current", "pnl", "pnl_pct", "leverage", "margin", "liq"}
+
+// cardFields lists every field that can be shown on a position card
+var cardFields = map[string]cardField{
+	"side": {"Side:", func(m Model, pos core.PositionData) string {
+		return valueStyle.Render(pos.PositionSide)
+	}},
+	"size": {"Size:", func(m Model, pos core.PositionData) string {
+		size := valueStyle.Render(m.formatFixed(pos.Size, 4))
+		if pos.MarginCurrency != "" {
+			size += "\n" + labelStyle.Render("Mgn Ccy:") + " " + valueStyle.Render(pos.MarginCurrency)
+		}
+		return size
+	}},
+	"entry": {"Entry:", func(m Model, pos core.PositionData) string {
+		return valueStyle.Render(m.formatPrice(pos.AvgPrice))
+	}},
+	"current": {"Current:", func(m Model, pos core.PositionData) string {
+		if m.colorCurrent {
+			return currentPriceStyle(pos).Render(m.formatPrice(pos.CurrentPrice))
+		}
+		return valueStyle.Render(m.formatPrice(pos.CurrentPrice))
+	}},
+	"pnl": {"PnL:", func(m Model, pos core.PositionData) string {
+		// PnL is in the settlement currency, e.g. BTC for BTC-USD-SWAP
+		pnl := m.stylePnL(pos.PnL, pnlPrecision(pos)) + " " + labelStyle.Render(pos.SettleCurrency())
+		if m.usdConvert && isCoinSettled(pos) {
+			pnl += "\n" + m.renderUSDPnL(pos)
+		}
+		return pnl
+	}},
+	"pnl_pct": {"PnL %:", func(m Model, pos core.PositionData) string {
+		// "~" marks a ratio estimated at an assumed leverage
+		if pos.PnLRatioEstimated && pos.LeverageAssumed {
+			return labelStyle.Render("~") + m.styleSigned(pos.PnLRatio, 2, "%")
+		}
+		return m.styleSigned(pos.PnLRatio, 2, "%")
+	}},
+	"leverage": {"Leverage:", func(m Model, pos core.PositionData) string {
+		// "~" marks leverage OKX didn't report, taken from an override or 1x
+		leverage := m.formatFixed(pos.Leverage, 0) + "x"
+		if pos.LeverageAssumed {
+			leverage = "~" + leverage
+		}
+		return valueStyle.Render(leverage)
+	}},
+	"margin": {"Margin:", func(m Model, pos core.PositionData) string {
+		// "~" marks an estimate from notional/leverage
+		marginStr := m.formatFixed(pos.Margin, pnlPrecision(pos))
+		if pos.MarginEstimated {
+			marginStr = "~" + marginStr
+		}
+		return valueStyle.Render(marginStr)
+	}},
+	"margin_ratio": {"Mgn Ratio:", func(m Model, pos core.PositionData) string {
+		if pos.MarginRatio <= 0 {
+			return neutralStyle.Render("n/a")
+		}
+		return valueStyle.Render(m.formatFixed(pos.MarginRatio, 0) + "%")
+	}},
+	"settle": {"Settle:", func(m Model, pos core.PositionData) string {
+		return valueStyle.Render(pos.SettleCurrency())
+	}},
+	"notional": {"Notional:", func(m Model, pos core.PositionData) string {
+		return valueStyle.Render(m.formatAmount(pos.CurrentPrice*pos.Size, 2))
+	}},
+	"liq": {"Liq:", func(m Model, pos core.PositionData) string {
+		distance, ok := liqDistancePct(pos)
+		if !ok {
+			return neutralStyle.Render("n/a")
+		}
+		return m.liqStyle(distance).Render(m.formatPrice(pos.LiqPrice)) + labelStyle.Render(fmt.Sprintf(" %s%%", m.formatFixed(distance, 1)))
+	}},
+}
+
+// currentPriceStyle colors the current price by which side of entry it is on:
+// green when the position is in the money (above entry for longs, below for
+// shorts), red when out of the money
+func currentPriceStyle(pos core.PositionData) lipgloss.Style {
+	if pos.AvgPrice <= 0 || pos.CurrentPrice <= 0 || pos.CurrentPrice == pos.AvgPrice {
+		return neutralStyle
+	}
+	inTheMoney := pos.CurrentPrice > pos.AvgPrice
+	if pos.IsShort() {
+		inTheMoney = !inTheMoney
+	}
+	if inTheMoney {
+		return positiveStyle
+	}
+	return negativeStyle
+}
+
+// parseCardFields parses a comma-separated list of card field names, returning
+// the default layout for an empty list
+func parseCardFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return defaultCardFields, nil
+	}
+
+	var fields []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := cardFields[name]; !ok {
+			return nil, fmt.Errorf("unknown card field %q, use any of: %s", name, strings.Join(cardFieldNames(), ", "))
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// cardFieldNames returns the valid card field names in default layout order
+func cardFieldNames() []string {
+	names := append([]string(nil), defaultCardFields...)
+	return append(names, "margin_ratio", "settle", "notional")
+}
+
+// renderCardFields renders the configured fields of a position, one per line
+func (m Model) renderCardFields(pos core.PositionData) string {
+	lines := make([]string, 0, len(m.cardFields))
+	for _, name := range m.cardFields {
+		field := cardFields[name]
+		lines = append(lines, fmt.Sprintf("%s %s", labelStyle.Render(field.label), field.render(m, pos)))
+	}
+	return strings.Join(lines, "\n")
+}
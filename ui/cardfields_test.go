@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestParseCardFields(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr string
+	}{
+		{"", defaultCardFields, ""},
+		{"  ", defaultCardFields, ""},
+		{"side,size,pnl", []string{"side", "size", "pnl"}, ""},
+		{" PnL , Margin_Ratio ", []string{"pnl", "margin_ratio"}, ""},
+		{"side,levrage", nil, `unknown card field "levrage"`},
+		{"side,,pnl", nil, `unknown card field ""`},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCardFields(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseCardFields(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCardFields(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestCardFieldNamesAllRender(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	pos := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500)
+	for _, name := range cardFieldNames() {
+		if _, ok := cardFields[name]; !ok {
+			t.Errorf("listed field %q has no renderer", name)
+		}
+	}
+	if len(cardFieldNames()) != len(cardFields) {
+		t.Errorf("%d names listed for %d fields", len(cardFieldNames()), len(cardFields))
+	}
+
+	m.cardFields = cardFieldNames()
+	if lines := strings.Count(m.renderCardFields(core.PositionData(pos)), "\n") + 1; lines != len(m.cardFields) {
+		t.Errorf("rendered %d lines for %d fields", lines, len(m.cardFields))
+	}
+}
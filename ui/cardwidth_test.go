@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestCardsPerRow(t *testing.T) {
+	tests := []struct {
+		width, minWidth, want int
+	}{
+		{120, 0, 4},   // (120-8)/26
+		{120, 24, 4},  // The default minimum changes nothing
+		{120, 30, 3},  // (120-8)/32
+		{120, 60, 1},  // (120-8)/62
+		{120, 200, 1}, // Wider than the terminal still shows one card
+		{30, 0, 1},    // Tiny terminals get the 40 column floor
+		{400, 0, 8},   // At most 8 per row
+		{400, 40, 8},
+		{400, 60, 6}, // (400-8)/62
+	}
+	for _, tt := range tests {
+		m := newModelWithOptions(nil, nil, nil, Options{MinCardWidth: tt.minWidth})
+		m.width = tt.width
+		if got := m.cardsPerRow(); got != tt.want {
+			t.Errorf("width %d, min %d: %d cards per row, want %d", tt.width, tt.minWidth, got, tt.want)
+		}
+	}
+}
+
+func TestCardMinWidthRendersWiderCards(t *testing.T) {
+	for _, minWidth := range []int{0, 40} {
+		m := newModelWithOptions(nil, nil, nil, Options{MinCardWidth: minWidth})
+		m.width, m.height = 200, 60
+		pos := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500)
+		m = updateModel(m, pos)
+
+		want := defaultCardWidth
+		if minWidth > want {
+			want = minWidth
+		}
+		card := m.renderPositionCard(m.positions["BTC-USDT-SWAP-long"], false)
+		// The style width includes padding, the border and right margin come on top
+		if got := lipgloss.Width(strings.Split(card, "\n")[0]); got != want+3 {
+			t.Errorf("min %d: card is %d columns wide, want %d", minWidth, got, want+3)
+		}
+	}
+}
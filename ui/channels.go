@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// renderChannelStats renders how full the client channels are and, when they
+// are counted, how often a send found each full, for the debug pane. Debug
+// traces share the error channel, so they only have counts.
+func (m Model) renderChannelStats() string {
+	fill := map[string]string{
+		core.ChannelPosition: fmt.Sprintf("%d/%d", len(m.positionCh), cap(m.positionCh)),
+		core.ChannelBalance:  fmt.Sprintf("%d/%d", len(m.balanceCh), cap(m.balanceCh)),
+		core.ChannelError:    fmt.Sprintf("%d/%d", len(m.errorCh), cap(m.errorCh)),
+	}
+
+	counts := m.backpressure.Counts()
+	if counts == nil {
+		return fmt.Sprintf("Channels: position %s · balance %s · error %s",
+			fill[core.ChannelPosition], fill[core.ChannelBalance], fill[core.ChannelError])
+	}
+
+	var parts []string
+	for _, c := range counts {
+		part := c.Channel
+		if f, ok := fill[c.Channel]; ok {
+			part += " " + f
+		}
+		part += fmt.Sprintf(" full %d", c.Full)
+		if c.Policy == core.ChannelDrop {
+			part += fmt.Sprintf(" dropped %d", c.Dropped)
+		}
+		parts = append(parts, part)
+	}
+	return "Channels: " + strings.Join(parts, " · ")
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestRenderChannelStats(t *testing.T) {
+	positionCh := make(chan core.PositionData, 100)
+	positionCh <- core.PositionData{}
+	m := NewModel(positionCh, make(chan core.BalanceData, 10), make(chan string, 10))
+	if got := m.renderChannelStats(); got != "Channels: position 1/100 · balance 0/10 · error 0/10" {
+		t.Errorf("uncounted stats = %q, want the fill levels only", got)
+	}
+
+	m.backpressure = core.NewBackpressure(map[string]core.ChannelPolicy{core.ChannelError: core.ChannelDrop})
+	got := m.renderChannelStats()
+	for _, want := range []string{"position 1/100 full 0 ·", "error 0/10 full 0 dropped 0", "debug full 0 dropped 0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("counted stats = %q, want %q", got, want)
+		}
+	}
+	if strings.Contains(got, "balance 0/10 full 0 dropped") {
+		t.Errorf("counted stats = %q, want no dropped count for a blocking channel", got)
+	}
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// closedFlashDuration is how long a closed position's card stays on screen
+// before it is removed
+const closedFlashDuration = 2 * time.Second
+
+// closedBadgeStyle marks a card whose position has just closed
+var closedBadgeStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("214")).
+	Bold(true)
+
+// closedPosition is a position that has closed, shown until its flash expires
+type closedPosition struct {
+	pos      core.PositionData
+	closedAt time.Time
+}
+
+// closedExpiredMsg removes a closed position's card once its flash is over
+type closedExpiredMsg struct {
+	account  string
+	key      string
+	closedAt time.Time
+}
+
+// flashClosed keeps a closed position's last state on screen for a moment,
+// returning the command that removes it again
+func (m *Model) flashClosed(key string, pos core.PositionData) tea.Cmd {
+	if m.closing == nil {
+		m.closing = make(map[string]closedPosition)
+	}
+	closedAt := time.Now()
+	m.closing[key] = closedPosition{pos: pos, closedAt: closedAt}
+	account := m.account
+	return tea.Tick(closedFlashDuration, func(time.Time) tea.Msg {
+		return closedExpiredMsg{account: account, key: key, closedAt: closedAt}
+	})
+}
+
+// expireClosed removes a closed position's card, unless the position has
+// reopened or closed again since the flash was scheduled
+func (m *Model) expireClosed(msg closedExpiredMsg) {
+	closing := m.closing
+	if msg.account != m.account {
+		state, ok := m.accountStates[msg.account]
+		if !ok {
+			return
+		}
+		closing = state.closing
+	}
+	if closed, ok := closing[msg.key]; ok && closed.closedAt.Equal(msg.closedAt) {
+		delete(closing, msg.key)
+	}
+}
+
+// renderClosedCards renders the cards of positions that have just closed,
+// after the open positions and subject to the same filters
+func (m Model) renderClosedCards() []string {
+	var keys []string
+	for key, closed := range m.closing {
+		if m.matchesFilter(closed.pos) && !m.staleOnly {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var cards []string
+	for _, key := range keys {
+		pos := m.closing[key].pos
+		var content strings.Builder
+		content.WriteString(cardHeaderStyle.Render(fmt.Sprintf("▶ %s ◀", pos.InstrumentID)))
+		content.WriteString("\n\n")
+		content.WriteString(closedBadgeStyle.Render("CLOSED"))
+		content.WriteString("\n")
+		content.WriteString(labelStyle.Render("Side: ") + valueStyle.Render(strings.ToUpper(pos.PositionSide)))
+		content.WriteString("\n")
+		content.WriteString(labelStyle.Render("PnL: ") + m.stylePnL(pos.PnL, pnlPrecision(pos)) + " " + labelStyle.Render(pos.SettleCurrency()))
+		cards = append(cards, m.sizedCard(cardStyle).Copy().Faint(true).Render(content.String()))
+	}
+	return cards
+}
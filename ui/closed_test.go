@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestClosedPositionFlashesThenExpires(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.showDebug = true
+	m = updateModel(m,
+		tea.WindowSizeMsg{Width: 120, Height: 40},
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500),
+		testPosition("ETH-USDT-SWAP", "long", 1, 3000, 3100, 100),
+	)
+
+	m = updateModel(m, testPosition("BTC-USDT-SWAP", "long", 0, 50000, 50500, 0))
+	const key = "BTC-USDT-SWAP-long"
+	if _, open := m.positions[key]; open {
+		t.Fatal("closed position still open")
+	}
+	closed, ok := m.closing[key]
+	if !ok {
+		t.Fatal("closed position not flashed")
+	}
+	if closed.pos.PnL != 500 {
+		t.Errorf("closed card PnL = %v, want the last open PnL 500", closed.pos.PnL)
+	}
+	if view := m.View(); !strings.Contains(view, "CLOSED") || !strings.Contains(view, "BTC-USDT-SWAP") {
+		t.Errorf("no CLOSED card for BTC in the view:\n%s", view)
+	}
+	if !strings.Contains(strings.Join(m.debugMessages, "\n"), "Position closed: BTC-USDT-SWAP long") {
+		t.Errorf("debug messages = %q, want the closed instrument named", m.debugMessages)
+	}
+
+	// An expiry scheduled for an earlier close leaves the card
+	m = updateModel(m, closedExpiredMsg{key: key, closedAt: closed.closedAt.Add(-time.Second)})
+	if _, ok := m.closing[key]; !ok {
+		t.Fatal("stale expiry removed the closed card")
+	}
+
+	m = updateModel(m, closedExpiredMsg{key: key, closedAt: closed.closedAt})
+	if _, ok := m.closing[key]; ok {
+		t.Error("closed card still shown after its flash")
+	}
+	if view := m.View(); strings.Contains(view, "CLOSED") || !strings.Contains(view, "ETH-USDT-SWAP") {
+		t.Errorf("view after the flash should only show ETH:\n%s", view)
+	}
+}
+
+func TestReopenedPositionReplacesClosedCard(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m = updateModel(m,
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500),
+		testPosition("BTC-USDT-SWAP", "long", 0, 50000, 50500, 0),
+		testPosition("BTC-USDT-SWAP", "long", 2, 50600, 50600, 0),
+	)
+	if _, ok := m.closing["BTC-USDT-SWAP-long"]; ok {
+		t.Error("closed card kept after the position reopened")
+	}
+	if pos, ok := m.positions["BTC-USDT-SWAP-long"]; !ok || pos.Size != 2 {
+		t.Errorf("reopened position = %+v (%v), want size 2", pos, ok)
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// statusUpdateMsg carries a connection state change from the client
+type statusUpdateMsg core.ConnState
+
+// staleCardStyle marks cards whose data predates a reconnect
+var staleCardStyle = cardStyle.Copy().
+	BorderForeground(lipgloss.Color("240"))
+
+// handleConnState records a connection state change. Positions are kept across
+// a reconnect and marked as awaiting refresh until fresh data arrives for them.
+func (m *Model) handleConnState(state core.ConnState) {
+	previous := m.connState
+	m.connState = state
+
+	switch state {
+	case core.ConnReconnecting:
+		if previous != core.ConnReconnecting {
+			m.reconnectStart = time.Now()
+		}
+		for key := range m.positions {
+			m.refreshPending[key] = true
+		}
+		m.AddDebugMessage("Connection lost, keeping positions until refreshed")
+	case core.ConnConnected:
+		if previous == core.ConnReconnecting {
+			m.ClearError()
+			m.AddDebugMessage("Reconnected, waiting for fresh position data")
+		}
+	}
+}
+
+// awaitingRefresh reports whether a position's data predates a reconnect and
+// has stayed unrefreshed past the stale grace period, so a quick reconnect
+// doesn't flash every card
+func (m Model) awaitingRefresh(pos core.PositionData) bool {
+	return m.refreshPending[fmt.Sprintf("%s-%s", pos.InstrumentID, pos.PositionSide)] &&
+		time.Since(m.reconnectStart) >= m.staleGrace
+}
+
+// connIndicators are the header dot colors and labels per connection state
+var connIndicators = map[core.ConnState]struct {
+	color lipgloss.Color
+	label string
+}{
+	core.ConnConnecting:   {lipgloss.Color("245"), "Connecting"},
+	core.ConnConnected:    {lipgloss.Color("46"), "Live"},
+	core.ConnReconnecting: {lipgloss.Color("226"), "Reconnecting"},
+	core.ConnStopped:      {lipgloss.Color("196"), "Disconnected"},
+}
+
+// renderConnIndicator renders a colored dot and the connection state for the
+// header title, or "" when there is no live connection, e.g. during playback
+func (m Model) renderConnIndicator() string {
+	if m.statusCh == nil {
+		return ""
+	}
+	indicator, ok := connIndicators[m.connState]
+	if !ok {
+		return ""
+	}
+	style := lipgloss.NewStyle().Foreground(indicator.color)
+	return "  " + style.Render("●") + " " + style.Render(indicator.label)
+}
+
+// connectionStatus describes a reconnect in progress, a stopped connection or a
+// paused feed for the footer
+func (m Model) connectionStatus() string {
+	status := ""
+	switch m.connState {
+	case core.ConnReconnecting:
+		status += " | Reconnecting..."
+	case core.ConnStopped:
+		status += " | ✖ Disconnected, not retrying"
+	}
+	if m.feedPaused {
+		status += " | ⏸ Feed paused (p to resume)"
+	}
+	return status
+}
+
+// toggleFeedPause asks the client to pause or resume its subscriptions
+func (m *Model) toggleFeedPause() tea.Cmd {
+	if m.pauseReqCh == nil {
+		return nil
+	}
+
+	m.feedPaused = !m.feedPaused
+	if m.feedPaused {
+		m.AddDebugMessage("Pausing feed, connections stay alive")
+	} else {
+		m.AddDebugMessage("Resuming feed")
+	}
+
+	ch, pause := m.pauseReqCh, m.feedPaused
+	return func() tea.Msg {
+		ch <- pause
+		return nil
+	}
+}
+
+// waitForStatusUpdate waits for connection state changes from the channel
+func waitForStatusUpdate(ch <-chan core.ConnState) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		state, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return statusUpdateMsg(state)
+	}
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestReconnectKeepsPositions(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.statusCh = make(chan core.ConnState)
+	m.width, m.height = 160, 60
+	btc := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500)
+	eth := testPosition("ETH-USDT-SWAP", "short", 2, 3000, 2990, 20)
+	m = updateModel(m, statusUpdateMsg(core.ConnConnected), btc, eth)
+
+	// The drop and its error leave the cards in place, awaiting refresh
+	m = updateModel(m, statusUpdateMsg(core.ConnReconnecting), errorMsg("Connection to OKX lost, reconnecting in 1s"))
+	if len(m.positions) != 2 {
+		t.Fatalf("%d positions while reconnecting, want both kept", len(m.positions))
+	}
+	view := m.View()
+	for _, want := range []string{"Reconnecting", "BTC-USDT-SWAP", "ETH-USDT-SWAP"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view while reconnecting is missing %q", want)
+		}
+	}
+	for _, pos := range m.positions {
+		if !m.awaitingRefresh(pos) {
+			t.Errorf("%s not marked as awaiting refresh", pos.InstrumentID)
+		}
+	}
+
+	// Fresh data after reconnecting refreshes only the positions it covers
+	btc.CurrentPrice, btc.PnL = 51000, 1000
+	m = updateModel(m, statusUpdateMsg(core.ConnConnected), btc)
+	if len(m.positions) != 2 {
+		t.Fatalf("%d positions after reconnecting, want both kept", len(m.positions))
+	}
+	if pos := m.positions["BTC-USDT-SWAP-long"]; m.awaitingRefresh(pos) || pos.CurrentPrice != 51000 {
+		t.Errorf("BTC = %+v (awaiting %v), want the fresh update merged", pos, m.awaitingRefresh(pos))
+	}
+	if !m.awaitingRefresh(m.positions["ETH-USDT-SWAP-short"]) {
+		t.Error("ETH refreshed without fresh data")
+	}
+	if view := m.View(); !strings.Contains(view, "Live") || strings.Contains(view, "Reconnecting") {
+		t.Error("header does not show the connection as live again")
+	}
+}
+
+func TestReconnectStaleGrace(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.staleGrace = time.Hour
+	m = updateModel(m, testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500), statusUpdateMsg(core.ConnReconnecting))
+	if m.awaitingRefresh(m.positions["BTC-USDT-SWAP-long"]) {
+		t.Error("card marked stale within the grace period")
+	}
+
+	// A failed attempt keeps the time the reconnect began
+	start := m.reconnectStart
+	m = updateModel(m, statusUpdateMsg(core.ConnReconnecting))
+	if !m.reconnectStart.Equal(start) {
+		t.Error("repeated reconnecting state restarted the grace period")
+	}
+}
+
+func TestConnIndicator(t *testing.T) {
+	tests := []struct {
+		state     core.ConnState
+		wantLabel string
+		wantColor string
+	}{
+		{core.ConnConnecting, "Connecting", "245"},
+		{core.ConnConnected, "Live", "46"},             // Green
+		{core.ConnReconnecting, "Reconnecting", "226"}, // Yellow
+		{core.ConnStopped, "Disconnected", "196"},      // Red
+	}
+
+	for _, tt := range tests {
+		m := NewModel(nil, nil, nil)
+		m.statusCh = make(chan core.ConnState)
+		m = updateModel(m, statusUpdateMsg(tt.state))
+		if got := m.renderConnIndicator(); !strings.Contains(got, "● "+tt.wantLabel) {
+			t.Errorf("state %v renders %q, want a dot and %q", tt.state, got, tt.wantLabel)
+		}
+		if got := string(connIndicators[tt.state].color); got != tt.wantColor {
+			t.Errorf("state %v is colored %s, want %s", tt.state, got, tt.wantColor)
+		}
+		if view := m.View(); !strings.Contains(view, tt.wantLabel) {
+			t.Errorf("header missing %q:\n%s", tt.wantLabel, view)
+		}
+	}
+}
+
+func TestConnIndicatorHiddenWithoutConnection(t *testing.T) {
+	// Playback has no status channel, so no live connection to show
+	m := NewModel(nil, nil, nil)
+	m = updateModel(m, statusUpdateMsg(core.ConnConnected))
+	if got := m.renderConnIndicator(); got != "" {
+		t.Errorf("indicator without a status channel = %q, want none", got)
+	}
+}
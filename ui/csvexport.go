@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// csvExportHeader is the header row of a positions CSV export
+var csvExportHeader = []string{"instId", "side", "size", "entry", "current", "pnl", "pnl%", "leverage"}
+
+// csvExportMsg reports the outcome of a CSV export
+type csvExportMsg struct {
+	path string
+	err  error
+}
+
+// ExportPositionsCSV writes positions to a CSV file at path with the columns
+// instId, side, size, entry, current, pnl, pnl% and leverage. Numbers keep
+// full precision; pnl% is in percent.
+func ExportPositionsCSV(positions []core.PositionData, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(file)
+	w.Write(csvExportHeader)
+	for _, pos := range positions {
+		w.Write([]string{
+			pos.InstrumentID,
+			pos.PositionSide,
+			strconv.FormatFloat(pos.Size, 'f', -1, 64),
+			strconv.FormatFloat(pos.AvgPrice, 'f', -1, 64),
+			strconv.FormatFloat(pos.CurrentPrice, 'f', -1, 64),
+			strconv.FormatFloat(pos.PnL, 'f', -1, 64),
+			strconv.FormatFloat(pos.PnLRatio, 'f', -1, 64),
+			strconv.FormatFloat(pos.Leverage, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// exportCSV writes every current position, including ones hidden by the
+// filters, to a timestamped CSV in the working directory, off the UI goroutine
+func (m Model) exportCSV() tea.Cmd {
+	positions := make([]core.PositionData, 0, len(m.positions))
+	for _, pos := range m.positions {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].InstrumentID == positions[j].InstrumentID {
+			return positions[i].PositionSide < positions[j].PositionSide
+		}
+		return positions[i].InstrumentID < positions[j].InstrumentID
+	})
+
+	return func() tea.Msg {
+		path := fmt.Sprintf("positions-%s.csv", time.Now().Format("20060102-150405"))
+		if err := ExportPositionsCSV(positions, path); err != nil {
+			return csvExportMsg{err: err}
+		}
+		return csvExportMsg{path: path}
+	}
+}
+
+// handleCSVExport confirms a finished CSV export in a toast, or shows why it
+// failed as an error
+func (m *Model) handleCSVExport(msg csvExportMsg) {
+	if msg.err != nil {
+		m.SetError(fmt.Sprintf("CSV export failed: %v", msg.err))
+		return
+	}
+	m.showToast("Saved positions to " + msg.path)
+}
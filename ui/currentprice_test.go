@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestCurrentPriceStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		pos   core.PositionData
+		color lipgloss.TerminalColor
+	}{
+		{"long above entry", core.PositionData{PositionSide: "long", AvgPrice: 100, CurrentPrice: 110}, positiveStyle.GetForeground()},
+		{"long below entry", core.PositionData{PositionSide: "long", AvgPrice: 100, CurrentPrice: 90}, negativeStyle.GetForeground()},
+		{"short above entry", core.PositionData{PositionSide: "short", AvgPrice: 100, CurrentPrice: 110}, negativeStyle.GetForeground()},
+		{"short below entry", core.PositionData{PositionSide: "short", AvgPrice: 100, CurrentPrice: 90}, positiveStyle.GetForeground()},
+		{"net short below entry", core.PositionData{PositionSide: "net", Size: -1, AvgPrice: 100, CurrentPrice: 90}, positiveStyle.GetForeground()},
+		{"at entry", core.PositionData{PositionSide: "long", AvgPrice: 100, CurrentPrice: 100}, neutralStyle.GetForeground()},
+		{"no price yet", core.PositionData{PositionSide: "short", AvgPrice: 100}, neutralStyle.GetForeground()},
+	}
+	for _, tt := range tests {
+		if got := currentPriceStyle(tt.pos).GetForeground(); got != tt.color {
+			t.Errorf("%s: color %v, want %v", tt.name, got, tt.color)
+		}
+	}
+}
+
+func TestColorCurrentToggle(t *testing.T) {
+	// Tests render without colors, so mark the red style's output instead
+	saved := negativeStyle
+	defer func() { negativeStyle = saved }()
+	negativeStyle = negativeStyle.Copy().Transform(func(s string) string { return "red:" + s })
+
+	m := NewModel(nil, nil, nil)
+	pos := core.PositionData{PositionSide: "long", AvgPrice: 100, CurrentPrice: 90}
+	if got := cardFields["current"].render(m, pos); got != m.formatPrice(90) {
+		t.Errorf("current = %q with coloring off, want it plain", got)
+	}
+
+	m.colorCurrent = true
+	if got := cardFields["current"].render(m, pos); got != "red:"+m.formatPrice(90) {
+		t.Errorf("current = %q with coloring on, want it red", got)
+	}
+}
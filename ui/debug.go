@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minDebugLineWidth is the narrowest debug line rendered before truncation
+const minDebugLineWidth = 20
+
+// debugCursorStyle marks the debug line selected for expansion
+var debugCursorStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("214")).
+	Bold(true)
+
+// debugLineWidth returns the width debug lines are truncated to, derived from
+// the terminal width unless a fixed width was configured
+func (m Model) debugLineWidth() int {
+	width := m.debugWidth
+	if width <= 0 {
+		// Account for base padding, pane border and padding, and the cursor column
+		width = m.width - 10
+	}
+	if width < minDebugLineWidth {
+		width = minDebugLineWidth
+	}
+	return width
+}
+
+// addInstrumentDebug adds a debug message about one instrument unless the
+// instrument filter excludes it
+func (m *Model) addInstrumentDebug(instId, msg string) {
+	if m.debugInstruments != nil && !m.debugInstruments[instId] {
+		return
+	}
+	m.AddDebugMessage(msg)
+}
+
+// truncateLine shortens s to at most width runes, ending in an ellipsis when cut
+func truncateLine(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// selectedDebugIndex returns the index of the selected debug line. The cursor
+// counts back from the newest message so it stays put as new lines arrive.
+func (m Model) selectedDebugIndex() int {
+	return len(m.debugMessages) - 1 - m.debugCursor
+}
+
+// moveDebugCursor moves the debug line selection towards older (delta > 0) or newer lines
+func (m *Model) moveDebugCursor(delta int) {
+	m.debugCursor += delta
+	if m.debugCursor > len(m.debugMessages)-1 {
+		m.debugCursor = len(m.debugMessages) - 1
+	}
+	if m.debugCursor < 0 {
+		m.debugCursor = 0
+	}
+}
+
+// renderDebugLine renders one stored debug message, truncated unless it is
+// the selected line and expansion is on
+func (m Model) renderDebugLine(i int, msg string) string {
+	width := m.debugLineWidth()
+	if i != m.selectedDebugIndex() {
+		return "  " + truncateLine(msg, width)
+	}
+	if m.debugExpanded {
+		// Wrap the full message and keep continuation lines aligned past the cursor
+		wrapped := lipgloss.NewStyle().Width(width).Render(msg)
+		return lipgloss.JoinHorizontal(lipgloss.Top, debugCursorStyle.Render("› "), wrapped)
+	}
+	return debugCursorStyle.Render("› ") + truncateLine(msg, width)
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultEquityBuckets bounds the equity history, older buckets are merged beyond it
+const defaultEquityBuckets = 720
+
+// equityBucket aggregates equity samples over a time bucket
+type equityBucket struct {
+	Start time.Time
+	Min   float64
+	Max   float64
+	Last  float64
+}
+
+// equityHistory is a bounded, downsampled series of total equity samples
+type equityHistory struct {
+	bucket  time.Duration // Width of a fresh bucket
+	limit   int           // Most buckets kept before older ones are merged
+	buckets []equityBucket
+}
+
+// newEquityHistory creates an equity history aggregating samples per bucket,
+// keeping up to limit buckets
+func newEquityHistory(bucket time.Duration, limit int) *equityHistory {
+	if bucket <= 0 {
+		bucket = 10 * time.Second
+	}
+	if limit <= 0 {
+		limit = defaultEquityBuckets
+	}
+	return &equityHistory{bucket: bucket, limit: limit}
+}
+
+// Add records an equity sample, merging it into the current bucket
+func (h *equityHistory) Add(at time.Time, equity float64) {
+	start := at.Truncate(h.bucket)
+	if n := len(h.buckets); n > 0 && !start.After(h.buckets[n-1].Start) {
+		b := &h.buckets[n-1]
+		if equity < b.Min {
+			b.Min = equity
+		}
+		if equity > b.Max {
+			b.Max = equity
+		}
+		b.Last = equity
+		return
+	}
+
+	h.buckets = append(h.buckets, equityBucket{Start: start, Min: equity, Max: equity, Last: equity})
+	if len(h.buckets) > h.limit {
+		h.compact()
+	}
+}
+
+// compact halves the older half of the history by merging adjacent buckets,
+// keeping the whole session in bounded memory at reduced resolution
+func (h *equityHistory) compact() {
+	half := len(h.buckets) / 2
+	var merged []equityBucket
+	for i := 0; i+1 < half; i += 2 {
+		a, b := h.buckets[i], h.buckets[i+1]
+		if b.Min < a.Min {
+			a.Min = b.Min
+		}
+		if b.Max > a.Max {
+			a.Max = b.Max
+		}
+		a.Last = b.Last
+		merged = append(merged, a)
+	}
+	if half%2 == 1 {
+		merged = append(merged, h.buckets[half-1])
+	}
+	h.buckets = append(merged, h.buckets[half:]...)
+}
+
+// Window returns the buckets within the last window, or the whole session when window is 0
+func (h *equityHistory) Window(window time.Duration, now time.Time) []equityBucket {
+	if window <= 0 {
+		return h.buckets
+	}
+
+	cutoff := now.Add(-window)
+	for i, b := range h.buckets {
+		if !b.Start.Before(cutoff) {
+			return h.buckets[i:]
+		}
+	}
+	return nil
+}
+
+// parseEquityWindow parses an equity window setting such as "5m", "1h" or "session"
+func parseEquityWindow(value string) (time.Duration, error) {
+	if value == "" || value == "session" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return 0, fmt.Errorf("invalid equity window %q, use a duration like 5m or \"session\"", value)
+	}
+	return window, nil
+}
+
+// equityWindowLabel describes the equity window for display
+func equityWindowLabel(window time.Duration) string {
+	if window <= 0 {
+		return "session"
+	}
+	return window.String()
+}
+
+// renderEquityLine renders a one-line sparkline of equity over the configured window
+func (m Model) renderEquityLine() string {
+	buckets := m.equity.Window(m.equityWindow, time.Now())
+	if len(buckets) < 2 {
+		return ""
+	}
+
+	values := make([]float64, len(buckets))
+	low, high := buckets[0].Min, buckets[0].Max
+	for i, b := range buckets {
+		values[i] = b.Last
+		if b.Min < low {
+			low = b.Min
+		}
+		if b.Max > high {
+			high = b.Max
+		}
+	}
+
+	return fmt.Sprintf("%s %s %s",
+		labelStyle.Render(fmt.Sprintf("Equity (%s):", equityWindowLabel(m.equityWindow))),
+		valueStyle.Render(sparkline(values, 40)),
+		labelStyle.Render(fmt.Sprintf("min %s max %s", m.formatFixed(low, 2), m.formatFixed(high, 2))))
+}
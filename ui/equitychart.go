@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// equityChartMinHeight is the fewest rows the equity chart is drawn with
+const equityChartMinHeight = 5
+
+// toggleEquityChart shows or hides the full-screen equity chart
+func (m *Model) toggleEquityChart() {
+	m.showEquityChart = !m.showEquityChart
+	m.scrollOffset = 0
+}
+
+// equityChartRows draws values as columns of unicode blocks, each row height
+// eighths of a cell, scaled between low and high. A flat series is drawn at
+// half height.
+func equityChartRows(values []float64, height int, low, high float64) []string {
+	levels := height * len(sparkBlocks)
+	filled := make([]int, len(values))
+	for i, v := range values {
+		if high > low {
+			filled[i] = 1 + int((v-low)/(high-low)*float64(levels-1))
+		} else {
+			filled[i] = levels / 2
+		}
+	}
+
+	rows := make([]string, height)
+	for r := range rows {
+		base := (height - 1 - r) * len(sparkBlocks)
+		var row strings.Builder
+		for _, f := range filled {
+			switch eighths := f - base; {
+			case eighths >= len(sparkBlocks):
+				row.WriteRune(sparkBlocks[len(sparkBlocks)-1])
+			case eighths <= 0:
+				row.WriteRune(' ')
+			default:
+				row.WriteRune(sparkBlocks[eighths-1])
+			}
+		}
+		rows[r] = row.String()
+	}
+	return rows
+}
+
+// renderEquityChart renders total equity over the equity window as a
+// full-screen chart, with the extremes and current equity annotated. Bars are
+// green while equity is above where the window started and red below it.
+func (m Model) renderEquityChart() string {
+	var content strings.Builder
+	title := titleStyle.Render("Equity ("+equityWindowLabel(m.equityWindow)+")") + m.renderAccountName()
+	content.WriteString(title)
+	content.WriteString("\n\n")
+
+	buckets := m.equity.Window(m.equityWindow, time.Now())
+	if len(buckets) < 2 {
+		content.WriteString(neutralStyle.Render("Collecting equity samples, the chart appears after the second balance update..."))
+		content.WriteString("\n\n")
+		content.WriteString(labelStyle.Render("Press c or Esc to return to the positions"))
+		return baseStyle.Render(content.String())
+	}
+
+	values := make([]float64, len(buckets))
+	low, high := buckets[0], buckets[0]
+	for i, b := range buckets {
+		values[i] = b.Last
+		if b.Min < low.Min {
+			low = b
+		}
+		if b.Max > high.Max {
+			high = b
+		}
+	}
+	first, current := values[0], values[len(values)-1]
+
+	// Y-axis labels take a fixed width so the chart columns line up
+	top, bottom := m.formatFixed(high.Max, 2), m.formatFixed(low.Min, 2)
+	axisWidth := lipgloss.Width(top)
+	if w := lipgloss.Width(bottom); w > axisWidth {
+		axisWidth = w
+	}
+	width := m.width - 8 - axisWidth - 2 // Base style padding and borders, then the axis
+	if width < 20 {
+		width = 20
+	}
+	height := m.height - 12
+	if height < equityChartMinHeight {
+		height = equityChartMinHeight
+	}
+
+	barStyle := positiveStyle
+	if current < first {
+		barStyle = negativeStyle
+	}
+	// Long sessions are averaged down to the width, short ones stretched to it
+	columns := downsample(values, width)
+	if repeat := width / len(columns); repeat > 1 {
+		stretched := make([]float64, 0, len(columns)*repeat)
+		for _, v := range columns {
+			for i := 0; i < repeat; i++ {
+				stretched = append(stretched, v)
+			}
+		}
+		columns = stretched
+	}
+	rows := equityChartRows(columns, height, low.Min, high.Max)
+	for r, row := range rows {
+		label := ""
+		switch r {
+		case 0:
+			label = top
+		case len(rows) - 1:
+			label = bottom
+		case len(rows) / 2:
+			label = m.formatFixed((high.Max+low.Min)/2, 2)
+		}
+		content.WriteString(labelStyle.Render(fmt.Sprintf("%*s ┤", axisWidth, label)))
+		content.WriteString(barStyle.Render(row))
+		content.WriteString("\n")
+	}
+
+	// Time axis under the chart, first and last bucket
+	start := buckets[0].Start.Format("15:04:05")
+	end := buckets[len(buckets)-1].Start.Format("15:04:05")
+	gap := lipgloss.Width(rows[0]) - len(start) - len(end)
+	if gap < 1 {
+		gap = 1
+	}
+	content.WriteString(labelStyle.Render(strings.Repeat(" ", axisWidth+2) + start + strings.Repeat(" ", gap) + end))
+	content.WriteString("\n\n")
+
+	change := current - first
+	changeText := m.formatSigned(change, 2)
+	if first != 0 {
+		changeText += " (" + m.formatSigned(change/first*100, 2) + "%)"
+	}
+	content.WriteString(labelStyle.Render("Current: ") + valueStyle.Render(m.formatFixed(current, 2)) + " " + signStyle(change).Render(changeText))
+	content.WriteString("\n")
+	content.WriteString(labelStyle.Render("Max: ") + valueStyle.Render(top) + labelStyle.Render(" at "+high.Start.Format("15:04:05")))
+	content.WriteString(labelStyle.Render("   Min: ") + valueStyle.Render(bottom) + labelStyle.Render(" at "+low.Start.Format("15:04:05")))
+	content.WriteString("\n\n")
+	content.WriteString(labelStyle.Render("Press c or Esc to return to the positions"))
+	return baseStyle.Render(content.String())
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// startFilter opens the instrument filter prompt, keeping any current filter
+func (m *Model) startFilter() {
+	m.filtering = true
+}
+
+// handleFilterKey edits the instrument filter as it is typed: enter keeps the
+// filter and closes the prompt, esc clears it and backspace deletes
+func (m *Model) handleFilterKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+		return
+	case tea.KeyEsc:
+		m.clearFilter()
+		return
+	case tea.KeyBackspace:
+		if m.filterText == "" {
+			return
+		}
+		_, size := utf8.DecodeLastRuneInString(m.filterText)
+		m.filterText = m.filterText[:len(m.filterText)-size]
+	case tea.KeyRunes, tea.KeySpace:
+		m.filterText += string(msg.Runes)
+	default:
+		return
+	}
+
+	// The grid changes under the cursor, start over at the first card
+	m.selected = 0
+	m.scrollOffset = 0
+}
+
+// clearFilter removes the instrument filter and closes the prompt
+func (m *Model) clearFilter() {
+	m.filtering = false
+	m.filterText = ""
+	m.selected = 0
+	m.scrollOffset = 0
+}
+
+// matchesFilter reports whether a position's instrument contains the filter
+// text, ignoring case
+func (m Model) matchesFilter(pos core.PositionData) bool {
+	return strings.Contains(strings.ToLower(pos.InstrumentID), strings.ToLower(m.filterText))
+}
+
+// renderFilterPrompt renders the filter being typed for the footer
+func (m Model) renderFilterPrompt() string {
+	return notePromptStyle.Render(fmt.Sprintf("Filter: %s█", m.filterText)) +
+		labelStyle.Render(" enter keep, esc clear")
+}
+
+// filterStatus describes the active filter and how many positions it matches
+// for the footer
+func (m Model) filterStatus() string {
+	if m.filterText == "" {
+		return ""
+	}
+
+	matched := 0
+	for _, pos := range m.positions {
+		if m.matchesFilter(pos) {
+			matched++
+		}
+	}
+	return fmt.Sprintf(" | Filter %q: %d/%d", m.filterText, matched, len(m.positions))
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// defaultTickInterval is how often the clock ticks and the view redraws
+	defaultTickInterval = time.Second
+
+	// unfocusedTickInterval slows the clock down to at most this rate while the
+	// terminal is unfocused
+	unfocusedTickInterval = 5 * time.Second
+
+	// unfocusedRenderInterval is the minimum time between full renders while unfocused
+	unfocusedRenderInterval = 2 * time.Second
+)
+
+// viewCache holds the last rendered frame so unfocused renders can be throttled.
+// It is shared by pointer because Bubble Tea copies the model on every update.
+type viewCache struct {
+	output string
+	at     time.Time
+}
+
+// handleFocusMsg tracks terminal focus, reporting whether msg was a focus event.
+// Terminals that don't report focus never send these, so the UI stays focused.
+func (m *Model) handleFocusMsg(msg tea.Msg) bool {
+	switch msg.(type) {
+	case tea.FocusMsg:
+		// Drop the throttled frame so the first focused render is current
+		m.focused = true
+		m.viewCache.output = ""
+		m.AddDebugMessage(fmt.Sprintf("Terminal focused, resuming %s clock", m.tickInterval))
+		return true
+	case tea.BlurMsg:
+		if m.pauseUnfocused {
+			m.focused = false
+			m.AddDebugMessage("Terminal unfocused, throttling clock and renders")
+		}
+		return true
+	}
+	return false
+}
+
+// nextTick schedules the next clock tick, slowing it down while unfocused
+func (m Model) nextTick() tea.Cmd {
+	if !m.focused && m.tickInterval < unfocusedTickInterval {
+		return tickEvery(unfocusedTickInterval)
+	}
+	return tickEvery(m.tickInterval)
+}
+
+// View renders the UI, reusing the last frame while unfocused to reduce redraws
+func (m Model) View() string {
+	if !m.focused && m.viewCache.output != "" && time.Since(m.viewCache.at) < unfocusedRenderInterval {
+		return m.viewCache.output
+	}
+
+	output := m.render()
+	m.viewCache.output = output
+	m.viewCache.at = time.Now()
+	return output
+}
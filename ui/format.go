@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// roundingMode controls how displayed values are rounded to their precision
+type roundingMode int
+
+const (
+	roundDefault  roundingMode = iota // fmt's rounding of the exact binary value
+	roundHalfUp                       // Decimal half away from zero, as OKX displays PnL
+	roundTruncate                     // Drop extra digits, rounding toward zero
+)
+
+// roundingModeNames maps rounding modes to their flag names
+var roundingModeNames = []string{"default", "half-up", "truncate"}
+
+// parseRoundingMode parses a rounding mode name such as "half-up"
+func parseRoundingMode(value string) (roundingMode, error) {
+	if value == "" {
+		return roundDefault, nil
+	}
+	for i, name := range roundingModeNames {
+		if value == name {
+			return roundingMode(i), nil
+		}
+	}
+	return roundDefault, fmt.Errorf("invalid rounding mode %q, use one of: %s", value, strings.Join(roundingModeNames, ", "))
+}
+
+// roundDisplay rounds v to prec decimals using the model's rounding mode. It
+// works on the shortest decimal form of v so 1.005 rounds half-up to 1.01.
+func (m Model) roundDisplay(v float64, prec int) float64 {
+	if m.rounding == roundDefault || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+
+	digits := strconv.FormatFloat(math.Abs(v), 'f', -1, 64)
+	intPart, frac := digits, ""
+	if dot := strings.IndexByte(digits, '.'); dot >= 0 {
+		intPart, frac = digits[:dot], digits[dot+1:]
+	}
+	if len(frac) <= prec {
+		return v
+	}
+
+	rounded, _ := strconv.ParseFloat(intPart+"."+frac[:prec], 64)
+	if m.rounding == roundHalfUp && frac[prec] >= '5' {
+		rounded += math.Pow10(-prec)
+	}
+	if rounded == 0 {
+		return 0
+	}
+	return math.Copysign(rounded, v)
+}
+
+// formatFixed formats v with prec decimals using the model's rounding mode
+func (m Model) formatFixed(v float64, prec int) string {
+	return strconv.FormatFloat(m.roundDisplay(v, prec), 'f', prec, 64)
+}
+
+// compactUnits are the suffixes formatCompact abbreviates thousands with
+var compactUnits = []string{"", "K", "M", "B"}
+
+// formatCompact formats v like formatFixed, but abbreviates magnitudes of a
+// thousand and up to two decimals with a K, M or B suffix, e.g. 1234.5 as
+// "1.23K". Values that round up to the next unit move to it, so 999999 is
+// "1.00M" rather than "1000.00K".
+func (m Model) formatCompact(v float64, prec int) string {
+	scaled, unit := v, 0
+	for unit < len(compactUnits)-1 {
+		p := prec
+		if unit > 0 {
+			p = 2
+		}
+		rounded, _ := strconv.ParseFloat(m.formatFixed(scaled, p), 64)
+		if math.Abs(rounded) < 1000 {
+			break
+		}
+		scaled /= 1000
+		unit++
+	}
+
+	if unit == 0 {
+		return m.formatFixed(v, prec)
+	}
+	return m.formatFixed(scaled, 2) + compactUnits[unit]
+}
+
+// formatPrice formats a price with precision appropriate to its magnitude
+func (m Model) formatPrice(price float64) string {
+	return m.formatFixed(price, priceDecimals(price))
+}
+
+// priceDecimals returns the decimals formatPrice shows for a price, more for
+// smaller prices
+func priceDecimals(price float64) int {
+	if price < 0.001 {
+		return 6
+	} else if price < 0.1 {
+		return 5
+	} else if price < 1.0 {
+		return 4
+	}
+	return 2
+}
+
+// formatSigned formats v with prec decimals and an explicit plus sign when positive
+func (m Model) formatSigned(v float64, prec int) string {
+	str := m.formatFixed(v, prec)
+	if v > 0 {
+		return "+" + str
+	}
+	return str
+}
+
+// styleSigned formats a value with prec decimals and the given suffix, with an
+// explicit plus sign, and colors it by sign
+func (m Model) styleSigned(value float64, prec int, suffix string) string {
+	return signStyle(value).Render(m.formatSigned(value, prec) + suffix)
+}
+
+// signStyle returns the style for a value colored by its sign
+func signStyle(value float64) lipgloss.Style {
+	if value > 0 {
+		return positiveStyle
+	} else if value < 0 {
+		return negativeStyle
+	}
+	return neutralStyle
+}
+
+// stylePnL renders a PnL amount like styleSigned, abbreviated with K/M
+// suffixes when compact PnL is on. PnL percentages keep full precision.
+func (m Model) stylePnL(value float64, prec int) string {
+	if !m.compactPnL {
+		return m.styleSigned(value, prec, "")
+	}
+	str := m.formatCompact(value, prec)
+	if value > 0 {
+		str = "+" + str
+	}
+	return signStyle(value).Render(str)
+}
+
+// formatAmount formats a notional amount, abbreviated when compact PnL is on
+func (m Model) formatAmount(value float64, prec int) string {
+	if m.compactPnL {
+		return m.formatCompact(value, prec)
+	}
+	return m.formatFixed(value, prec)
+}
@@ -0,0 +1,97 @@
+package ui
+
+import "testing"
+
+func TestFormatCompact(t *testing.T) {
+	tests := []struct {
+		value float64
+		prec  int
+		want  string
+	}{
+		{0, 2, "0.00"},
+		{999.99, 2, "999.99"},
+		{999.994, 2, "999.99"},
+		{999.995, 2, "1.00K"}, // Rounds up to a thousand, so moves to K
+		{1000, 2, "1.00K"},
+		{1234.5, 2, "1.23K"},
+		{-1234.5, 2, "-1.23K"},
+		{999994, 2, "999.99K"},
+		{999995, 2, "1.00M"},
+		{-2500000, 2, "-2.50M"},
+		{1.5e9, 2, "1.50B"},
+		{2.5e12, 2, "2500.00B"}, // B is the largest unit
+		{999.4, 0, "999"},
+		{999.6, 0, "1.00K"},
+		{12.3456, 4, "12.3456"}, // Below a thousand keeps the requested precision
+	}
+	m := NewModel(nil, nil, nil)
+	for _, tt := range tests {
+		if got := m.formatCompact(tt.value, tt.prec); got != tt.want {
+			t.Errorf("formatCompact(%v, %d) = %q, want %q", tt.value, tt.prec, got, tt.want)
+		}
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		value    float64
+		prec     int
+		rounding string
+		want     string
+	}{
+		{1.005, 2, "default", "1.00"}, // Binary value is just below 1.005
+		{1.005, 2, "half-up", "1.01"},
+		{-1.005, 2, "half-up", "-1.01"},
+		{1.009, 2, "truncate", "1.00"},
+		{-1.009, 2, "truncate", "-1.00"},
+		{-0.001, 2, "truncate", "0.00"}, // No negative zero
+		{1.5, 2, "half-up", "1.50"},
+	}
+	for _, tt := range tests {
+		m := newModelWithOptions(nil, nil, nil, Options{Rounding: tt.rounding})
+		if got := m.formatFixed(tt.value, tt.prec); got != tt.want {
+			t.Errorf("formatFixed(%v, %d) = %q with %s rounding, want %q", tt.value, tt.prec, got, tt.rounding, tt.want)
+		}
+	}
+
+	// Each model keeps its own mode
+	truncating := newModelWithOptions(nil, nil, nil, Options{Rounding: "truncate"})
+	plain := NewModel(nil, nil, nil)
+	if got := plain.formatFixed(1.019, 2); got != "1.02" {
+		t.Errorf("default model formats %q after another truncates, want 1.02", got)
+	}
+	if got := truncating.formatFixed(1.019, 2); got != "1.01" {
+		t.Errorf("truncating model formats %q, want 1.01", got)
+	}
+}
+
+func TestCompactPnLToggle(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	tests := []struct {
+		value            float64
+		compact, regular string
+	}{
+		{1234.5, "+1.23K", "+1234.50"},
+		{-1234.5, "-1.23K", "-1234.50"},
+		{12.5, "+12.50", "+12.50"},
+		{0, "0.00", "0.00"},
+	}
+	for _, tt := range tests {
+		m.compactPnL = false
+		if got := m.stylePnL(tt.value, 2); got != tt.regular {
+			t.Errorf("stylePnL(%v) = %q with compact PnL off, want %q", tt.value, got, tt.regular)
+		}
+		m.compactPnL = true
+		if got := m.stylePnL(tt.value, 2); got != tt.compact {
+			t.Errorf("stylePnL(%v) = %q with compact PnL on, want %q", tt.value, got, tt.compact)
+		}
+	}
+
+	if got := m.formatAmount(98765.4, 2); got != "98.77K" {
+		t.Errorf("compact notional = %q, want 98.77K", got)
+	}
+	m.compactPnL = false
+	if got := m.formatAmount(98765.4, 2); got != "98765.40" {
+		t.Errorf("notional = %q, want 98765.40", got)
+	}
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fxRate converts amounts in one currency to dollars, either at a fixed rate
+// or at the live price of an instrument quoted in USDT
+type fxRate struct {
+	rate   float64 // Fixed dollars per unit, unused when live
+	instId string  // Ticker the live rate follows, "" for a fixed rate
+}
+
+// defaultFXRates treat the dollar stablecoins as 1:1 with each other
+var defaultFXRates = map[string]fxRate{
+	"USDT": {rate: 1},
+	"USDC": {rate: 1},
+	"USD":  {rate: 1},
+}
+
+// parseFXRates parses comma-separated currency rates like "USDC=0.9998" or
+// "USDC=live" on top of the defaults. A live rate follows the currency's USDT
+// ticker; USDT itself is the base and can't be live.
+func parseFXRates(value string) (map[string]fxRate, error) {
+	rates := make(map[string]fxRate, len(defaultFXRates))
+	for ccy, rate := range defaultFXRates {
+		rates[ccy] = rate
+	}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		ccy, raw, ok := strings.Cut(part, "=")
+		ccy, raw = strings.ToUpper(strings.TrimSpace(ccy)), strings.ToLower(strings.TrimSpace(raw))
+		if !ok || ccy == "" {
+			return nil, fmt.Errorf("invalid FX rate %q, use CCY=rate or CCY=live", part)
+		}
+		if raw == "live" {
+			if ccy == "USDT" {
+				return nil, fmt.Errorf("invalid FX rate %q, USDT is the base currency", part)
+			}
+			rates[ccy] = fxRate{instId: ccy + "-USDT"}
+			continue
+		}
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid FX rate %q, use a positive number or live", part)
+		}
+		rates[ccy] = fxRate{rate: rate}
+	}
+	return rates, nil
+}
+
+// FXInstruments returns the tickers the live rates in an FX rate spec follow,
+// for the client to keep subscribed
+func FXInstruments(value string) ([]string, error) {
+	rates, err := parseFXRates(value)
+	if err != nil {
+		return nil, err
+	}
+	var instIds []string
+	for _, rate := range rates {
+		if rate.instId != "" {
+			instIds = append(instIds, rate.instId)
+		}
+	}
+	sort.Strings(instIds)
+	return instIds, nil
+}
+
+// recordFXPrice keeps the latest price of an instrument a live rate follows
+func (m *Model) recordFXPrice(instId string, price float64) {
+	for _, rate := range m.fxRates {
+		if rate.instId == instId && price > 0 {
+			m.fxPrices[instId] = price
+			return
+		}
+	}
+}
+
+// dollarRate returns the dollars per unit of a settlement currency: its FX
+// table rate, or for a coin missing from the table the price streaming for a
+// position in it. It reports false while a live rate or price hasn't arrived.
+func (m Model) dollarRate(ccy string) (float64, bool) {
+	if ccy == "" {
+		return 1, true
+	}
+	if rate, ok := m.fxRates[ccy]; ok {
+		if rate.instId == "" {
+			return rate.rate, true
+		}
+		price, ok := m.fxPrices[rate.instId]
+		return price, ok
+	}
+	return m.usdPrice(ccy)
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestParseFXRates(t *testing.T) {
+	rates, err := parseFXRates(" usdc=0.9998, EUR=live ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]fxRate{
+		"USDT": {rate: 1},
+		"USDC": {rate: 0.9998},
+		"USD":  {rate: 1},
+		"EUR":  {instId: "EUR-USDT"},
+	}
+	if !reflect.DeepEqual(rates, want) {
+		t.Errorf("parseFXRates() = %v, want %v", rates, want)
+	}
+
+	for _, value := range []string{"USDT=live", "USDC", "=1", "USDC=0", "USDC=par"} {
+		if _, err := parseFXRates(value); err == nil {
+			t.Errorf("parseFXRates(%q) accepted", value)
+		}
+	}
+
+	if instIds, err := FXInstruments("USDC=live,EUR=live,DAI=1"); err != nil || !reflect.DeepEqual(instIds, []string{"EUR-USDT", "USDC-USDT"}) {
+		t.Errorf("FXInstruments() = %v, %v; want the live rates' tickers", instIds, err)
+	}
+}
+
+func TestTotalPnLMixesUSDTAndUSDC(t *testing.T) {
+	usdt := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50100, 100)
+	usdc := testPosition("ETH-USDC-SWAP", "long", 10, 3000, 3005, 50)
+
+	tests := []struct {
+		name      string
+		fx        string
+		rate      float64 // Streamed USDC-USDT price, 0 sends none
+		wantTotal float64
+		wantPart  bool
+		wantText  string
+	}{
+		{"stablecoins 1:1 by default", "", 0, 150, false, "+150.00"},
+		{"fixed rate", "USDC=0.5", 0, 125, false, "+125.00"},
+		{"live rate not yet streamed", "USDC=live", 0, 100, true, "~+100.00"},
+		{"live rate", "USDC=live", 0.99, 149.5, false, "+149.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModelWithOptions(nil, nil, nil, Options{FXRates: tt.fx})
+			m = updateModel(m, usdt, usdc)
+			if tt.rate > 0 {
+				m = updateModel(m, positionUpdateMsg{InstrumentID: "USDC-USDT", CurrentPrice: tt.rate})
+			}
+
+			total, _, partial := m.usdSum([]core.PositionData{core.PositionData(usdt), core.PositionData(usdc)},
+				func(pos core.PositionData) float64 { return pos.PnL })
+			if !approxEqual(total, tt.wantTotal) || partial != tt.wantPart {
+				t.Errorf("total = %v (partial %v), want %v (partial %v)", total, partial, tt.wantTotal, tt.wantPart)
+			}
+			if got := m.renderTotalPnL(); !strings.Contains(got, "uPnL: "+tt.wantText) {
+				t.Errorf("renderTotalPnL() = %q, want %s", got, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// RunHeadless runs the monitor without a terminal UI until ctx is done: the
+// model tracks positions and fires its alerts as usual, but nothing is drawn
+// and no terminal is needed. Alerts are written to out one per line, and errors
+// and warnings to errOut; webhooks, sounds and desktop notifications follow
+// opts. Debug messages are dropped unless opts.DebugWriter is set.
+func RunHeadless(ctx context.Context, positionCh <-chan core.PositionData, balanceCh <-chan core.BalanceData, errorCh <-chan string, opts Options, out, errOut io.Writer) {
+	model := newModelWithOptions(positionCh, balanceCh, errorCh, opts)
+	model.headless = true
+	model.alertLog = out
+	model.alertSelect = false // Nothing to select without cards
+
+	// Commands run in the background and hand their messages back to the
+	// loop, like a Bubble Tea program without the renderer or input
+	msgs := make(chan tea.Msg, 64)
+	run := func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		go func() {
+			if msg := cmd(); msg != nil {
+				select {
+				case msgs <- msg:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	run(model.Init())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-msgs:
+			switch msg := msg.(type) {
+			case tea.BatchMsg:
+				for _, cmd := range msg {
+					run(cmd)
+				}
+				continue
+			case tea.QuitMsg:
+				return
+			case errorMsg:
+				// Errors and warnings would otherwise only show on screen
+				if text := string(msg); !strings.HasPrefix(text, "DEBUG:") {
+					fmt.Fprintf(errOut, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), text)
+				}
+			}
+
+			next, cmd := model.Update(msg)
+			model = next.(Model)
+			run(cmd)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// renderGroupCard renders a card for a single position, a paired hedge or the
+// collapsed small positions
+func (m Model) renderGroupCard(group []core.PositionData, selected bool) string {
+	if m.isOthersGroup(group) {
+		return m.renderOthersCard(group, selected)
+	}
+	if len(group) == 2 {
+		return m.renderHedgeCard(group[0], group[1], selected)
+	}
+	return m.renderPositionCard(group[0], selected)
+}
+
+// renderHedgeCard renders both legs of a hedged instrument with net exposure and combined PnL
+func (m Model) renderHedgeCard(a, b core.PositionData, selected bool) string {
+	long, short := a, b
+	if long.PositionSide == "short" {
+		long, short = short, long
+	}
+
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render(fmt.Sprintf("▶ %s ◀", long.InstrumentID) + m.pinLabel(long.InstrumentID)))
+	content.WriteString("\n")
+	content.WriteString(m.renderNote(long))
+	content.WriteString(m.renderNote(short))
+
+	content.WriteString(fmt.Sprintf("%s %s\n",
+		labelStyle.Render("L:"),
+		valueStyle.Render(m.formatFixed(long.Size, 4)+" @ "+m.formatPrice(long.AvgPrice))))
+	content.WriteString(fmt.Sprintf("%s %s\n",
+		labelStyle.Render("S:"),
+		valueStyle.Render(m.formatFixed(short.Size, 4)+" @ "+m.formatPrice(short.AvgPrice))))
+
+	// Net exposure is long size minus short size
+	content.WriteString(fmt.Sprintf("%s %s\n",
+		labelStyle.Render("Net:"),
+		valueStyle.Render(m.formatSigned(long.Size-short.Size, 4))))
+
+	content.WriteString(fmt.Sprintf("%s %s\n",
+		labelStyle.Render("Current:"),
+		valueStyle.Render(m.formatPrice(long.CurrentPrice))))
+
+	content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("L PnL:"), m.stylePnL(long.PnL, pnlPrecision(long))))
+	content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("S PnL:"), m.stylePnL(short.PnL, pnlPrecision(short))))
+
+	combined := long.PnL + short.PnL
+	content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("PnL:"), m.stylePnL(combined, pnlPrecision(long))))
+
+	// Combined ratio against the total entry notional of both legs
+	combinedRatio := 0.0
+	if notional := long.AvgPrice*long.Size + short.AvgPrice*short.Size; notional > 0 {
+		combinedRatio = combined / notional * 100
+	}
+	content.WriteString(fmt.Sprintf("%s %s", labelStyle.Render("PnL %:"), m.styleSigned(combinedRatio, 2, "%")))
+
+	if m.showLatency() {
+		content.WriteString(m.renderLatencyLines(long, short))
+	}
+	if m.showFeedCountdown() {
+		content.WriteString(m.renderFeedCountdown(long.InstrumentID))
+	}
+	content.WriteString(m.renderTickerStats(long.InstrumentID))
+
+	for _, leg := range []core.PositionData{long, short} {
+		if style, ok := m.alertCardStyle(leg); ok {
+			return m.sizedCard(style).Render(content.String())
+		}
+	}
+	if selected {
+		return m.sizedCard(selectedCardStyle).Render(content.String())
+	}
+	if m.awaitingRefresh(long) {
+		return m.sizedCard(staleCardStyle).Render(content.String())
+	}
+	return m.sizedCard(cardStyle).Render(content.String())
+}
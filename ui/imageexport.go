@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Character cell size of exported images, matching the 7x13 bitmap font
+const (
+	imageCellWidth  = 7
+	imageCellHeight = 13
+)
+
+var (
+	// imageDefaultFg and imageDefaultBg stand in for the terminal's default colors
+	imageDefaultFg = color.RGBA{208, 208, 208, 255}
+	imageDefaultBg = color.RGBA{30, 30, 30, 255}
+
+	// errImageExportUnavailable is returned when no glyph renderer is built in
+	errImageExportUnavailable = errors.New("PNG export not built in, rebuild with -tags pngexport")
+)
+
+// drawGlyph draws a character into the cell whose top-left corner is x, y. It
+// is set by the optional pngexport build so the default binary carries no font
+// dependency; PNG export is unavailable while it is nil.
+var drawGlyph func(img *image.RGBA, x, y int, r rune, fg color.Color)
+
+// imageExportMsg reports the outcome of a PNG export
+type imageExportMsg struct {
+	path string
+	err  error
+}
+
+// exportImage renders a view to a timestamped PNG in the working directory,
+// off the UI goroutine
+func exportImage(view string) tea.Cmd {
+	return func() tea.Msg {
+		if drawGlyph == nil {
+			return imageExportMsg{err: errImageExportUnavailable}
+		}
+
+		path := fmt.Sprintf("okx-monitor-%s.png", time.Now().Format("20060102-150405"))
+		file, err := os.Create(path)
+		if err != nil {
+			return imageExportMsg{err: err}
+		}
+		defer file.Close()
+
+		if err := png.Encode(file, rasterizeANSI(view)); err != nil {
+			return imageExportMsg{err: err}
+		}
+		return imageExportMsg{path: path}
+	}
+}
+
+// handleImageExport reports a finished PNG export in a toast
+func (m *Model) handleImageExport(msg imageExportMsg) {
+	if msg.err != nil {
+		m.showToast(fmt.Sprintf("PNG export failed: %v", msg.err))
+		return
+	}
+	m.showToast("Saved view to " + msg.path)
+}
+
+// imageCell is one character of a rasterized view with its colors
+type imageCell struct {
+	r      rune
+	fg, bg color.RGBA
+}
+
+// parseANSI splits rendered output into rows of colored cells, following SGR
+// color sequences and skipping any other escape sequence
+func parseANSI(view string) [][]imageCell {
+	fg, bg := imageDefaultFg, imageDefaultBg
+	rows := [][]imageCell{nil}
+
+	runes := []rune(view)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\x1b' && i+1 < len(runes) && runes[i+1] == '[':
+			// Control sequence: parameters up to the final byte
+			j := i + 2
+			for j < len(runes) && (runes[j] < 0x40 || runes[j] > 0x7e) {
+				j++
+			}
+			if j < len(runes) && runes[j] == 'm' {
+				fg, bg = applySGR(string(runes[i+2:j]), fg, bg)
+			}
+			i = j
+		case r == '\x1b':
+			// Other escapes, e.g. OSC, are not part of the picture
+			continue
+		case r == '\n':
+			rows = append(rows, nil)
+		case r == '\r':
+			continue
+		default:
+			rows[len(rows)-1] = append(rows[len(rows)-1], imageCell{r: r, fg: fg, bg: bg})
+		}
+	}
+	return rows
+}
+
+// applySGR applies an SGR parameter list such as "1;38;5;86" to the colors
+func applySGR(params string, fg, bg color.RGBA) (color.RGBA, color.RGBA) {
+	if params == "" {
+		return imageDefaultFg, imageDefaultBg
+	}
+
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, _ := strconv.Atoi(codes[i])
+		switch {
+		case code == 0:
+			fg, bg = imageDefaultFg, imageDefaultBg
+		case code >= 30 && code <= 37:
+			fg = xtermColor(code - 30)
+		case code >= 90 && code <= 97:
+			fg = xtermColor(code - 90 + 8)
+		case code == 39:
+			fg = imageDefaultFg
+		case code >= 40 && code <= 47:
+			bg = xtermColor(code - 40)
+		case code >= 100 && code <= 107:
+			bg = xtermColor(code - 100 + 8)
+		case code == 49:
+			bg = imageDefaultBg
+		case code == 38 || code == 48:
+			var c color.RGBA
+			var ok bool
+			c, i, ok = extendedColor(codes, i)
+			if ok && code == 38 {
+				fg = c
+			} else if ok {
+				bg = c
+			}
+		}
+	}
+	return fg, bg
+}
+
+// extendedColor parses a 256-color (5;n) or truecolor (2;r;g;b) argument that
+// follows codes[i], returning the index of its last parameter
+func extendedColor(codes []string, i int) (color.RGBA, int, bool) {
+	arg := func(k int) int {
+		if k >= len(codes) {
+			return 0
+		}
+		v, _ := strconv.Atoi(codes[k])
+		return v
+	}
+
+	switch arg(i + 1) {
+	case 5:
+		return xtermColor(arg(i + 2)), i + 2, true
+	case 2:
+		return color.RGBA{uint8(arg(i + 2)), uint8(arg(i + 3)), uint8(arg(i + 4)), 255}, i + 4, true
+	}
+	return color.RGBA{}, i, false
+}
+
+// xtermColor converts an xterm 256-color palette index to RGB
+func xtermColor(n int) color.RGBA {
+	base := [16]color.RGBA{
+		{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+		{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+		{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+		{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+	}
+
+	switch {
+	case n < 0 || n > 255:
+		return imageDefaultFg
+	case n < 16:
+		return base[n]
+	case n < 232:
+		levels := [6]uint8{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return color.RGBA{levels[n/36], levels[(n/6)%6], levels[n%6], 255}
+	}
+	v := uint8(8 + (n-232)*10)
+	return color.RGBA{v, v, v, 255}
+}
+
+// rasterizeANSI draws a rendered view as an image, one cell per character.
+// Box-drawing and block characters are drawn as shapes, everything else with
+// drawGlyph.
+func rasterizeANSI(view string) *image.RGBA {
+	rows := parseANSI(view)
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cols*imageCellWidth, len(rows)*imageCellHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(imageDefaultBg), image.Point{}, draw.Src)
+
+	for y, row := range rows {
+		for x, cell := range row {
+			px, py := x*imageCellWidth, y*imageCellHeight
+			cellRect := image.Rect(px, py, px+imageCellWidth, py+imageCellHeight)
+			draw.Draw(img, cellRect, image.NewUniform(cell.bg), image.Point{}, draw.Src)
+
+			if !drawShape(img, cellRect, cell.r, cell.fg) && cell.r != ' ' {
+				drawGlyph(img, px, py, cell.r, cell.fg)
+			}
+		}
+	}
+	return img
+}
+
+// drawShape draws box-drawing and block characters, which bitmap fonts rarely
+// cover, reporting false for any other character
+func drawShape(img *image.RGBA, cell image.Rectangle, r rune, fg color.RGBA) bool {
+	src := image.NewUniform(fg)
+	midX := cell.Min.X + imageCellWidth/2
+	midY := cell.Min.Y + imageCellHeight/2
+	horizontal := func(x0, x1 int) {
+		draw.Draw(img, image.Rect(x0, midY, x1, midY+1), src, image.Point{}, draw.Src)
+	}
+	vertical := func(y0, y1 int) {
+		draw.Draw(img, image.Rect(midX, y0, midX+1, y1), src, image.Point{}, draw.Src)
+	}
+
+	switch r {
+	case '─', '━':
+		horizontal(cell.Min.X, cell.Max.X)
+	case '│', '┃':
+		vertical(cell.Min.Y, cell.Max.Y)
+	case '╭', '┌':
+		horizontal(midX, cell.Max.X)
+		vertical(midY, cell.Max.Y)
+	case '╮', '┐':
+		horizontal(cell.Min.X, midX+1)
+		vertical(midY, cell.Max.Y)
+	case '╰', '└':
+		horizontal(midX, cell.Max.X)
+		vertical(cell.Min.Y, midY+1)
+	case '╯', '┘':
+		horizontal(cell.Min.X, midX+1)
+		vertical(cell.Min.Y, midY+1)
+	default:
+		// Lower block elements ▁ to █ fill an eighth of the cell per step
+		if r < '▁' || r > '█' {
+			return false
+		}
+		height := imageCellHeight * int(r-'▁'+1) / 8
+		draw.Draw(img, image.Rect(cell.Min.X, cell.Max.Y-height, cell.Max.X, cell.Max.Y), src, image.Point{}, draw.Src)
+	}
+	return true
+}
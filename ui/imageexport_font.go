@@ -0,0 +1,26 @@
+//go:build pngexport
+
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Built with -tags pngexport, glyphs come from the 7x13 bitmap font
+func init() {
+	face := basicfont.Face7x13
+	drawGlyph = func(img *image.RGBA, x, y int, r rune, fg color.Color) {
+		drawer := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(fg),
+			Face: face,
+			Dot:  fixed.P(x, y+face.Ascent),
+		}
+		drawer.DrawString(string(r))
+	}
+}
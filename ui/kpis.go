@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// kpiChipStyle frames one account KPI in the summary row
+var kpiChipStyle = lipgloss.NewStyle().
+	Background(lipgloss.Color("236")).
+	Padding(0, 1)
+
+// accountKPI renders the value of one account-level KPI
+type accountKPI struct {
+	label  string
+	render func(m Model) string
+}
+
+// defaultKPIs are the account KPIs shown when none are configured
+var defaultKPIs = []string{"equity", "upnl", "positions"}
+
+// kpiNames lists every account KPI in a sensible display order
+var kpiNames = []string{"equity", "available", "upnl", "rpnl", "margin_ratio", "positions", "leverage"}
+
+// accountKPIs lists every KPI that can be shown in the summary row
+var accountKPIs = map[string]accountKPI{
+	"equity": {"Equity", func(m Model) string {
+		if equity := m.totalEquity(); equity > 0 {
+			return valueStyle.Render(m.formatFixed(equity, 2))
+		}
+		return neutralStyle.Render("--")
+	}},
+	"available": {"Avail", func(m Model) string {
+		if len(m.balances) == 0 {
+			return neutralStyle.Render("--")
+		}
+		avail := m.availableBalance()
+		if avail >= 0 {
+			return valueStyle.Render(m.formatFixed(avail, 2))
+		}
+		if m.negativeAvail == negativeAvailClamp {
+			avail = 0
+		}
+		return negativeStyle.Render(m.formatFixed(avail, 2) + " ⚠")
+	}},
+	"upnl": {"uPnL", func(m Model) string {
+		return m.renderUSDTotal(func(pos core.PositionData) float64 { return pos.PnL })
+	}},
+	"rpnl": {"rPnL", func(m Model) string {
+		return m.renderUSDTotal(func(pos core.PositionData) float64 { return pos.RealizedPnL })
+	}},
+	"margin_ratio": {"Mgn Ratio", func(m Model) string {
+		// Lowest reported ratio is the one closest to liquidation
+		var ratio float64
+		for _, balance := range m.balances {
+			if balance.MarginRatio > 0 && (ratio == 0 || balance.MarginRatio < ratio) {
+				ratio = balance.MarginRatio
+			}
+		}
+		if ratio == 0 {
+			return neutralStyle.Render("n/a")
+		}
+		return valueStyle.Render(m.formatFixed(ratio, 0) + "%")
+	}},
+	"positions": {"Positions", func(m Model) string {
+		return valueStyle.Render(fmt.Sprintf("%d", len(m.positions)))
+	}},
+	"leverage": {"Leverage", func(m Model) string {
+		equity := m.totalEquity()
+		if equity <= 0 {
+			return neutralStyle.Render("n/a")
+		}
+		var notional float64
+		for _, pos := range m.positions {
+			notional += pos.CurrentPrice * math.Abs(pos.Size)
+		}
+		return valueStyle.Render(m.formatFixed(notional/equity, 2) + "x")
+	}},
+}
+
+// parseKPIs parses a comma-separated list of KPI names, returning the default
+// set for an empty list and no KPIs for "none"
+func parseKPIs(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultKPIs, nil
+	}
+	if value == "none" {
+		return nil, nil
+	}
+
+	var kpis []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := accountKPIs[name]; !ok {
+			return nil, fmt.Errorf("unknown KPI %q, use any of: %s", name, strings.Join(kpiNames, ", "))
+		}
+		kpis = append(kpis, name)
+	}
+	return kpis, nil
+}
+
+// renderUSDTotal sums a per-position amount in dollars. Coin-settled amounts
+// are converted with streamed prices; any that can't be are left out and the
+// total is marked "~".
+func (m Model) renderUSDTotal(amount func(pos core.PositionData) float64) string {
+	positions := make([]core.PositionData, 0, len(m.positions))
+	for _, pos := range m.positions {
+		positions = append(positions, pos)
+	}
+	return m.renderUSDSum(positions, amount)
+}
+
+// renderUSDSum is renderUSDTotal over the given positions
+func (m Model) renderUSDSum(positions []core.PositionData, amount func(pos core.PositionData) float64) string {
+	if len(positions) == 0 {
+		return neutralStyle.Render("--")
+	}
+
+	total, _, partial := m.usdSum(positions, amount)
+	rendered := m.stylePnL(total, 2)
+	if partial {
+		rendered = labelStyle.Render("~") + rendered
+	}
+	return rendered
+}
+
+// usdSum sums a per-position amount in dollars along with the entry notional
+// (average price × size) of the positions it covers, converting each from its
+// settlement currency at the FX table's rate. Amounts that can't be converted
+// yet are left out and reported as partial.
+func (m Model) usdSum(positions []core.PositionData, amount func(pos core.PositionData) float64) (total, notional float64, partial bool) {
+	for _, pos := range positions {
+		rate, ok := m.dollarRate(pos.SettleCurrency())
+		if !ok {
+			partial = true
+			continue
+		}
+		total += amount(pos) * rate
+		notional += pos.AvgPrice * math.Abs(pos.Size)
+	}
+	return total, notional, partial
+}
+
+// renderTotalPnL renders the unrealized PnL of all positions for the header,
+// with its percentage of their combined entry notional and of the account
+// equity, or "" without positions. The equity share is left out until a
+// balance with equity has arrived.
+// It is summed from the positions on every render, so it follows ticker-driven
+// PnL updates.
+func (m Model) renderTotalPnL() string {
+	if len(m.positions) == 0 {
+		return ""
+	}
+
+	positions := make([]core.PositionData, 0, len(m.positions))
+	for _, pos := range m.positions {
+		positions = append(positions, pos)
+	}
+	total, notional, partial := m.usdSum(positions, func(pos core.PositionData) float64 { return pos.PnL })
+
+	rendered := m.stylePnL(total, 2)
+	if partial {
+		rendered = labelStyle.Render("~") + rendered
+	}
+	if notional > 0 {
+		rendered += labelStyle.Render(" (") + m.styleSigned(total/notional*100, 2, "%") + labelStyle.Render(")")
+	}
+	if pct, ok := equityPct(total, m.totalEquity()); ok {
+		rendered += labelStyle.Render(" · ") + m.styleSigned(pct, 2, "%") + labelStyle.Render(" of equity")
+	}
+	return labelStyle.Render("uPnL: ") + rendered
+}
+
+// equityPct returns pnl as a percentage of equity, reporting false when the
+// equity is unknown or not positive
+func equityPct(pnl, equity float64) (float64, bool) {
+	if equity <= 0 {
+		return 0, false
+	}
+	return pnl / equity * 100, true
+}
+
+// renderKPIRow renders the configured KPIs as a row of chips, wrapping onto
+// further lines when the terminal is too narrow
+func (m Model) renderKPIRow(width int) string {
+	if len(m.kpis) == 0 {
+		return ""
+	}
+
+	var lines []string
+	var line string
+	for _, name := range m.kpis {
+		kpi := accountKPIs[name]
+		chip := kpiChipStyle.MaxWidth(width).Render(labelStyle.Render(kpi.label+":") + " " + kpi.render(m))
+
+		switch {
+		case line == "":
+			line = chip
+		case lipgloss.Width(line)+1+lipgloss.Width(chip) <= width:
+			line += " " + chip
+		default:
+			lines = append(lines, line)
+			line = chip
+		}
+	}
+	lines = append(lines, line)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEquityPct(t *testing.T) {
+	tests := []struct {
+		pnl, equity float64
+		want        float64
+		wantOK      bool
+	}{
+		{400, 10000, 4, true},
+		{-250, 5000, -5, true},
+		{0, 1000, 0, true},
+		{100, 0, 0, false},   // No balance yet
+		{100, -50, 0, false}, // Negative equity has no meaningful share
+	}
+	for _, tt := range tests {
+		if got, ok := equityPct(tt.pnl, tt.equity); !approxEqual(got, tt.want) || ok != tt.wantOK {
+			t.Errorf("equityPct(%v, %v) = %v, %v; want %v, %v", tt.pnl, tt.equity, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestTotalPnLShareOfEquity(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m = updateModel(m,
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500),
+		testPosition("ETH-USDT-SWAP", "short", 10, 3000, 3010, -100),
+	)
+	if got := m.renderTotalPnL(); strings.Contains(got, "of equity") {
+		t.Errorf("uPnL %q shows an equity share before any balance", got)
+	}
+
+	m = updateModel(m,
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 8000},
+		balanceUpdateMsg{Currency: "USDC", TotalEquity: 2000},
+	)
+	if got := m.renderTotalPnL(); !strings.Contains(got, "+400.00") || !strings.Contains(got, "+4.00% of equity") {
+		t.Errorf("uPnL = %q, want +400.00 at +4.00%% of the 10000 equity", got)
+	}
+
+	m = updateModel(m, testPosition("BTC-USDT-SWAP", "long", 1, 50000, 49500, -500))
+	if got := m.renderTotalPnL(); !strings.Contains(got, "-6.00% of equity") {
+		t.Errorf("uPnL = %q, want -6.00%% of equity", got)
+	}
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// defaultStaleAfter is how long an instrument may go without updates before it counts as stale
+const defaultStaleAfter = 10 * time.Second
+
+// markSeen records the local time an update for the instrument arrived
+func (m *Model) markSeen(instId string) {
+	m.lastSeen[instId] = time.Now()
+}
+
+// instrumentAge returns how long ago the instrument last received an update
+func (m Model) instrumentAge(instId string) time.Duration {
+	seen, ok := m.lastSeen[instId]
+	if !ok {
+		return 0
+	}
+	return time.Since(seen)
+}
+
+// staleThreshold is how long an instrument may go without updates before it is
+// marked stale: the stale timeout plus the grace period that keeps a single
+// missed frame from flickering the marker on and off
+func (m Model) staleThreshold() time.Duration {
+	return m.staleAfter + m.staleGrace
+}
+
+// isStale reports whether the instrument has gone longer than the stale threshold without updates
+func (m Model) isStale(instId string) bool {
+	return m.instrumentAge(instId) > m.staleThreshold()
+}
+
+// showLatency reports whether cards should display data age and latency
+func (m Model) showLatency() bool {
+	return m.sortMode == sortByLatency || m.staleOnly
+}
+
+// renderLatencyLines renders the data age of the instrument and the exchange-to-receipt
+// latency of its most recent update for display at the bottom of a card
+func (m Model) renderLatencyLines(positions ...core.PositionData) string {
+	pos := positions[0]
+	for _, p := range positions[1:] {
+		if p.ReceivedAt > pos.ReceivedAt {
+			pos = p
+		}
+	}
+
+	age := m.instrumentAge(pos.InstrumentID)
+	ageStr := valueStyle.Render(fmt.Sprintf("%.1fs", age.Seconds()))
+	if age > m.staleThreshold() {
+		ageStr = negativeStyle.Render(fmt.Sprintf("%.1fs", age.Seconds()))
+	}
+
+	lagStr := neutralStyle.Render("n/a")
+	if pos.ReceivedAt > 0 {
+		lagStr = valueStyle.Render(fmt.Sprintf("%dms", pos.ReceivedAt-pos.Timestamp))
+	}
+	return fmt.Sprintf("\n%s %s\n%s %s", labelStyle.Render("Age:"), ageStr, labelStyle.Render("Lag:"), lagStr)
+}
+
+// latencyStatus describes the active sort mode and stale filter for the footer
+func (m Model) latencyStatus() string {
+	status := fmt.Sprintf(" | Sort: %s", m.sortMode)
+	if m.staleOnly {
+		status += fmt.Sprintf(" | Stale >%s only", m.staleAfter)
+	}
+	return status
+}
+
+// feedWarnStyle colors a feed countdown that is getting close to the stale threshold
+var feedWarnStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("214"))
+
+// renderFeedCountdown renders the time left before the instrument's feed counts
+// as stale, turning orange past half the threshold and red in the last fifth
+func (m Model) renderFeedCountdown(instId string) string {
+	if _, ok := m.lastSeen[instId]; !ok {
+		return fmt.Sprintf("\n%s %s", labelStyle.Render("Feed:"), neutralStyle.Render("no ticks yet"))
+	}
+
+	age := m.instrumentAge(instId)
+	remaining := m.staleThreshold() - age
+	if remaining <= 0 {
+		return fmt.Sprintf("\n%s %s", labelStyle.Render("Feed:"), negativeStyle.Render(fmt.Sprintf("stale %ds", int(age.Seconds()))))
+	}
+
+	style := positiveStyle
+	switch used := float64(age) / float64(m.staleThreshold()); {
+	case used >= 0.8:
+		style = negativeStyle
+	case used >= 0.5:
+		style = feedWarnStyle
+	}
+	return fmt.Sprintf("\n%s %s", labelStyle.Render("Feed:"), style.Render(fmt.Sprintf("%ds left", int(remaining.Seconds()+0.5))))
+}
+
+// showFeedCountdown reports whether cards should show the feed timeout countdown
+func (m Model) showFeedCountdown() bool {
+	return m.showDebug || m.feedDiagnostics
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleGraceAbsorbsBriefGaps(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{StaleGrace: 2 * time.Second})
+	m = updateModel(m, testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500))
+	m.staleOnly = true
+
+	// A gap just past the stale timeout but within the grace period
+	m.lastSeen["BTC-USDT-SWAP"] = time.Now().Add(-m.staleAfter - time.Second)
+	if m.isStale("BTC-USDT-SWAP") || len(m.sortedPositions()) != 0 {
+		t.Error("card marked stale within the grace period")
+	}
+
+	// The next frame arrives, so the card never flickered
+	m = updateModel(m, positionUpdateMsg{InstrumentID: "BTC-USDT-SWAP", CurrentPrice: 50600})
+	if age := m.instrumentAge("BTC-USDT-SWAP"); age > time.Second {
+		t.Fatalf("age %s after a fresh update", age)
+	}
+
+	// A gap outlasting the grace period is stale
+	m.lastSeen["BTC-USDT-SWAP"] = time.Now().Add(-m.staleAfter - 3*time.Second)
+	if !m.isStale("BTC-USDT-SWAP") || len(m.sortedPositions()) != 1 {
+		t.Error("card not marked stale after the grace period")
+	}
+}
+
+func TestStaleWithoutGrace(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.lastSeen["BTC-USDT-SWAP"] = time.Now().Add(-m.staleAfter - 100*time.Millisecond)
+	if !m.isStale("BTC-USDT-SWAP") {
+		t.Error("without a grace period the card should be stale right after the timeout")
+	}
+	if m.isStale("ETH-USDT-SWAP") {
+		t.Error("instrument never seen counted as stale")
+	}
+}
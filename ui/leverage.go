@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// maxLeverageTypes are the instrument types whose maximum leverage is fetched
+var maxLeverageTypes = []string{"SWAP", "FUTURES", "MARGIN"}
+
+// defaultMetadataRefresh is how often cached instrument metadata is fetched
+// again, picking up listings and leverage changes in long sessions
+const defaultMetadataRefresh = 4 * time.Hour
+
+// metadataRefreshMsg asks for the cached instrument metadata to be fetched again
+type metadataRefreshMsg struct{}
+
+// maxLeverageMsg carries the fetched maximum leverage per instrument
+type maxLeverageMsg struct {
+	levers map[string]float64
+	err    error
+}
+
+// fetchMaxLeverage loads the exchange's maximum leverage from instrument
+// metadata in the background, through client (nil for the default)
+func fetchMaxLeverage(client *http.Client) tea.Cmd {
+	return func() tea.Msg {
+		levers, err := core.FetchMaxLeverage(client, core.DefaultRESTURL, maxLeverageTypes...)
+		return maxLeverageMsg{levers: levers, err: err}
+	}
+}
+
+// initialMaxLeverage fetches maximum leverage at startup when it is shown
+func (m Model) initialMaxLeverage() tea.Cmd {
+	if !m.showMaxLeverage {
+		return nil
+	}
+	return fetchMaxLeverage(m.restClient)
+}
+
+// toggleMaxLeverage shows or hides the leverage headroom in the detail view,
+// fetching the instrument metadata the first time it is shown
+func (m *Model) toggleMaxLeverage() tea.Cmd {
+	m.showMaxLeverage = !m.showMaxLeverage
+	if !m.showMaxLeverage || m.maxLeverage != nil || m.maxLevLoading {
+		return nil
+	}
+	m.maxLevLoading = true
+	return fetchMaxLeverage(m.restClient)
+}
+
+// handleMaxLeverage stores fetched maximum leverage and schedules its next
+// refresh. A failed first fetch is noted in the debug pane so the detail view
+// omits the maximum until M retries it; a failed refresh keeps the cached data.
+func (m *Model) handleMaxLeverage(msg maxLeverageMsg) tea.Cmd {
+	m.maxLevLoading = false
+	if msg.err != nil {
+		if m.maxLeverage == nil {
+			m.AddDebugMessage(fmt.Sprintf("Max leverage unavailable: %v", msg.err))
+			return nil
+		}
+		m.AddDebugMessage(fmt.Sprintf("Warning: instrument metadata refresh failed, keeping cached data: %v", msg.err))
+	} else {
+		m.maxLeverage = msg.levers
+	}
+	return m.scheduleMetadataRefresh()
+}
+
+// scheduleMetadataRefresh fetches the cached instrument metadata again after
+// the refresh interval, nil when refreshing is off
+func (m Model) scheduleMetadataRefresh() tea.Cmd {
+	if m.metaRefresh <= 0 {
+		return nil
+	}
+	return tea.Tick(m.metaRefresh, func(time.Time) tea.Msg {
+		return metadataRefreshMsg{}
+	})
+}
+
+// refreshMetadata fetches the instrument metadata again, unless a fetch is
+// already in progress and will schedule the next refresh itself
+func (m *Model) refreshMetadata() tea.Cmd {
+	if m.maxLevLoading {
+		return nil
+	}
+	m.maxLevLoading = true
+	return fetchMaxLeverage(m.restClient)
+}
+
+// renderLeverageInfo renders each position's leverage against the exchange
+// maximum, e.g. "10x / 125x max", or "" when the display is off. The maximum is
+// omitted for instruments without metadata.
+func (m Model) renderLeverageInfo(group []core.PositionData) string {
+	if !m.showMaxLeverage {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render("Leverage"))
+	for _, pos := range group {
+		content.WriteString("\n")
+		if len(group) > 1 {
+			content.WriteString(labelStyle.Render(strings.ToUpper(pos.PositionSide) + ": "))
+		}
+		content.WriteString(valueStyle.Render(m.formatFixed(pos.Leverage, 0) + "x"))
+		if maxLever, ok := m.maxLeverage[pos.InstrumentID]; ok {
+			content.WriteString(labelStyle.Render(fmt.Sprintf(" / %sx max", m.formatFixed(maxLever, 0))))
+		} else if m.maxLevLoading {
+			content.WriteString(neutralStyle.Render(" / loading max..."))
+		}
+	}
+	return timingStyle.Render(content.String())
+}
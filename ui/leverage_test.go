@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadataRefreshScheduling(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.metaRefresh = 10 * time.Millisecond
+	m.maxLevLoading = true
+	m.showDebug = true
+
+	// A completed fetch schedules the next refresh after the interval
+	cmd := m.handleMaxLeverage(maxLeverageMsg{levers: map[string]float64{"BTC-USDT-SWAP": 100}})
+	if cmd == nil {
+		t.Fatal("no refresh scheduled after a fetch")
+	}
+	start := time.Now()
+	if _, ok := cmd().(metadataRefreshMsg); !ok {
+		t.Fatal("scheduled command doesn't ask for a refresh")
+	}
+	if elapsed := time.Since(start); elapsed < m.metaRefresh {
+		t.Errorf("refresh fired after %v, want the %v interval", elapsed, m.metaRefresh)
+	}
+
+	// The refresh fetches again, and a tick during that fetch is dropped
+	if cmd := m.refreshMetadata(); cmd == nil || !m.maxLevLoading {
+		t.Fatal("refresh didn't start a fetch")
+	}
+	if cmd := m.refreshMetadata(); cmd != nil {
+		t.Error("second fetch started while one is in flight")
+	}
+
+	// A failed refresh keeps the cached data and still schedules the next one
+	cmd = m.handleMaxLeverage(maxLeverageMsg{err: errors.New("timeout")})
+	if m.maxLeverage["BTC-USDT-SWAP"] != 100 {
+		t.Errorf("cached max leverage = %v after a failed refresh, want it kept", m.maxLeverage)
+	}
+	if cmd == nil {
+		t.Error("no refresh scheduled after a failed refresh")
+	}
+	if len(m.debugMessages) == 0 || !strings.Contains(m.debugMessages[len(m.debugMessages)-1], "keeping cached data") {
+		t.Errorf("debug messages = %q, want a refresh warning", m.debugMessages)
+	}
+}
+
+func TestMetadataRefreshNotScheduled(t *testing.T) {
+	t.Run("failed first fetch", func(t *testing.T) {
+		m := NewModel(nil, nil, nil)
+		if cmd := m.handleMaxLeverage(maxLeverageMsg{err: errors.New("timeout")}); cmd != nil {
+			t.Error("refresh scheduled without any cached data")
+		}
+	})
+	t.Run("refreshing off", func(t *testing.T) {
+		m := NewModel(nil, nil, nil)
+		m.metaRefresh = 0
+		if cmd := m.handleMaxLeverage(maxLeverageMsg{levers: map[string]float64{}}); cmd != nil {
+			t.Error("refresh scheduled with a 0 interval")
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+const (
+	// defaultPriceSamples bounds each instrument's recent price ring buffer
+	defaultPriceSamples = 120
+
+	// liqTrendWindow is how much recent price movement the liquidation ETA uses
+	liqTrendWindow = time.Minute
+
+	// minLiqTrendSpan is the shortest price history an ETA is estimated from
+	minLiqTrendSpan = 5 * time.Second
+)
+
+var (
+	// liqWarningStyle makes the liquidation warning stand out on at-risk cards
+	liqWarningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	// liqPriceStyle sets the liquidation price apart from the other card prices
+	liqPriceStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("208"))
+)
+
+// priceSample is one observed price of an instrument
+type priceSample struct {
+	at    time.Time
+	price float64
+}
+
+// priceRing is a fixed-size ring buffer of an instrument's recent prices
+type priceRing struct {
+	samples []priceSample
+	next    int
+	count   int
+}
+
+// newPriceRing creates a ring buffer holding up to size prices
+func newPriceRing(size int) *priceRing {
+	if size <= 0 {
+		size = defaultPriceSamples
+	}
+	return &priceRing{samples: make([]priceSample, size)}
+}
+
+// Add records a price, overwriting the oldest sample once full
+func (r *priceRing) Add(at time.Time, price float64) {
+	size := len(r.samples)
+	r.samples[r.next] = priceSample{at: at, price: price}
+	r.next = (r.next + 1) % size
+	if r.count < size {
+		r.count++
+	}
+}
+
+// Span returns the oldest sample at or after cutoff and the latest sample,
+// reporting false when fewer than two samples fall in that range
+func (r *priceRing) Span(cutoff time.Time) (oldest, latest priceSample, ok bool) {
+	if r.count < 2 {
+		return oldest, latest, false
+	}
+	size := len(r.samples)
+	latest = r.samples[(r.next-1+size)%size]
+	for i := r.count; i >= 1; i-- {
+		sample := r.samples[(r.next-i+size)%size]
+		if !sample.at.Before(cutoff) {
+			return sample, latest, sample.at.Before(latest.at)
+		}
+	}
+	return oldest, latest, false
+}
+
+// recordPrice adds an instrument's current price to its ring buffer
+func (m Model) recordPrice(instId string, price float64) {
+	if price <= 0 {
+		return
+	}
+	ring, ok := m.priceRings[instId]
+	if !ok {
+		ring = newPriceRing(m.bufLimits.price)
+		m.priceRings[instId] = ring
+	}
+	ring.Add(time.Now(), price)
+}
+
+// liqDistancePct returns how far the price can move against the position
+// before reaching the liquidation price, in percent of the current price
+func liqDistancePct(pos core.PositionData) (float64, bool) {
+	if pos.LiqPrice <= 0 || pos.CurrentPrice <= 0 {
+		return 0, false
+	}
+	distance := (pos.CurrentPrice - pos.LiqPrice) / pos.CurrentPrice * 100
+	if pos.IsShort() {
+		distance = -distance
+	}
+	return math.Max(distance, 0), true
+}
+
+// liqStyle returns the style for a liquidation price that is distance
+// percent away, switching to the warning style within the warning threshold
+func (m Model) liqStyle(distance float64) lipgloss.Style {
+	if m.liqWarnPct > 0 && distance <= m.liqWarnPct {
+		return liqWarningStyle
+	}
+	return liqPriceStyle
+}
+
+// estimateLiqETA extrapolates the price trend over liqTrendWindow to estimate when the
+// liquidation price would be reached. It reports false when the price is not
+// moving toward liquidation or there is too little history.
+func (m Model) estimateLiqETA(pos core.PositionData) (time.Duration, bool) {
+	ring, ok := m.priceRings[pos.InstrumentID]
+	if !ok {
+		return 0, false
+	}
+	oldest, latest, ok := ring.Span(time.Now().Add(-liqTrendWindow))
+	span := latest.at.Sub(oldest.at)
+	if !ok || span < minLiqTrendSpan {
+		return 0, false
+	}
+
+	velocity := (latest.price - oldest.price) / span.Seconds()
+	towardLiq := velocity < 0
+	if pos.IsShort() {
+		towardLiq = velocity > 0
+	}
+	if !towardLiq {
+		return 0, false
+	}
+
+	seconds := math.Abs(pos.CurrentPrice-pos.LiqPrice) / math.Abs(velocity)
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// renderLiqWarning renders the liquidation distance, and optionally the trend
+// based ETA, for positions within the warning threshold. Safe positions get "".
+func (m Model) renderLiqWarning(pos core.PositionData) string {
+	distance, ok := liqDistancePct(pos)
+	if !ok || m.liqWarnPct <= 0 || distance > m.liqWarnPct {
+		return ""
+	}
+
+	warning := "\n" + liqWarningStyle.Render(fmt.Sprintf("⚠ LIQ %s%% away", m.formatFixed(distance, 1)))
+	if !m.liqETA {
+		return warning
+	}
+
+	if eta, ok := m.estimateLiqETA(pos); ok {
+		return warning + "\n" + labelStyle.Render("ETA ") + negativeStyle.Render("~"+eta.Round(time.Second).String()) + labelStyle.Render(" (est.)")
+	}
+	return warning + "\n" + labelStyle.Render("ETA ") + neutralStyle.Render("none, not trending")
+}
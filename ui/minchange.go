@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// minChange is the smallest PnL move a position update must make to be shown
+type minChange struct {
+	units float64 // Absolute, in units of the last PnL digit shown for the position
+	pct   float64 // Relative, in percent of the previous PnL
+}
+
+// parseMinChange parses a minimum PnL change: "N%" of the previous PnL, or N
+// units of the last displayed PnL digit, e.g. "5" is 0.05 USDT on a USDT-settled
+// position and 5 of the last coin digit on a coin-settled one. Empty disables it.
+func parseMinChange(value string) (minChange, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return minChange{}, nil
+	}
+
+	pct := strings.HasSuffix(value, "%")
+	n, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || n < 0 {
+		return minChange{}, fmt.Errorf("invalid minimum PnL change %q, use N or N%%", value)
+	}
+	if pct {
+		return minChange{pct: n}, nil
+	}
+	return minChange{units: n}, nil
+}
+
+// samePositionShape reports whether two updates of a position differ only in
+// the values that move with the price
+func samePositionShape(a, b core.PositionData) bool {
+	return a.InstrumentID == b.InstrumentID &&
+		a.PositionSide == b.PositionSide &&
+		a.Size == b.Size &&
+		a.AvgPrice == b.AvgPrice &&
+		a.Leverage == b.Leverage &&
+		a.MarginCurrency == b.MarginCurrency
+}
+
+// belowMinChange reports whether an update moves a position's PnL by less
+// than the configured minimum, so it can be dropped without a redraw. Changes
+// to the position itself, like its size or entry, always count.
+func (m Model) belowMinChange(prev, next core.PositionData) bool {
+	if m.minChange == (minChange{}) || !samePositionShape(prev, next) {
+		return false
+	}
+
+	change := math.Abs(next.PnL - prev.PnL)
+	if m.minChange.pct > 0 {
+		return change < math.Abs(prev.PnL)*m.minChange.pct/100
+	}
+	// Compare in displayed digits, with a little slack so a move of exactly the
+	// minimum isn't lost to float error, e.g. 100.05 - 100 = 0.0499...
+	digits := change * math.Pow(10, float64(pnlPrecision(next)))
+	return digits < m.minChange.units-1e-6
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMinChangeDropsSmallMoves(t *testing.T) {
+	tests := []struct {
+		name      string
+		minChange string
+		instId    string
+		pnl, next float64
+		wantShown bool
+	}{
+		{"below units", "5", "BTC-USDT-SWAP", 100, 100.04, false},
+		{"at units", "5", "BTC-USDT-SWAP", 100, 100.05, true},
+		{"below units, falling", "5", "BTC-USDT-SWAP", 100, 99.96, false},
+		{"coin digits are finer", "5", "BTC-USD-SWAP", 0.01, 0.01 + 6*math.Pow(10, -coinPnLPrecision), true},
+		{"coin below units", "5", "BTC-USD-SWAP", 0.01, 0.01 + 4*math.Pow(10, -coinPnLPrecision), false},
+		{"below percent", "1%", "BTC-USDT-SWAP", 200, 201.9, false},
+		{"at percent", "1%", "BTC-USDT-SWAP", 200, 202, true},
+		{"disabled", "", "BTC-USDT-SWAP", 100, 100.0001, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModelWithOptions(nil, nil, nil, Options{MinChange: tt.minChange})
+			pos := testPosition(tt.instId, "long", 1, 50000, 50100, tt.pnl)
+			m = updateModel(m, pos)
+			key := tt.instId + "-long"
+
+			pos.PnL, pos.CurrentPrice = tt.next, 50101
+			m = updateModel(m, pos)
+			if shown := m.positions[key].PnL == tt.next; shown != tt.wantShown {
+				t.Errorf("PnL %v -> %v: shown %v, want %v (model has %v)", tt.pnl, tt.next, shown, tt.wantShown, m.positions[key].PnL)
+			}
+			if !tt.wantShown && m.positions[key].CurrentPrice != 50100 {
+				t.Error("a dropped update still changed the position")
+			}
+		})
+	}
+}
+
+func TestMinChangeKeepsStructureChanges(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{MinChange: "1000"})
+	pos := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50100, 100)
+	m = updateModel(m, pos)
+
+	pos.Size, pos.PnL = 2, 100.01
+	m = updateModel(m, pos)
+	if got := m.positions["BTC-USDT-SWAP-long"]; got.Size != 2 {
+		t.Errorf("size change dropped, size = %v", got.Size)
+	}
+
+	pos.AvgPrice = 49000
+	m = updateModel(m, pos)
+	if got := m.positions["BTC-USDT-SWAP-long"]; got.AvgPrice != 49000 {
+		t.Errorf("entry change dropped, entry = %v", got.AvgPrice)
+	}
+}
+
+func TestParseMinChange(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    minChange
+		wantErr bool
+	}{
+		{"", minChange{}, false},
+		{" 5 ", minChange{units: 5}, false},
+		{"0.5%", minChange{pct: 0.5}, false},
+		{"-1", minChange{}, true},
+		{"lots", minChange{}, true},
+		{"%", minChange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseMinChange(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseMinChange(%q) = %+v, %v; want %+v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// maxNoteLength caps a position note so it fits on one card line
+const maxNoteLength = 20
+
+var (
+	// noteStyle renders a position's note on its card
+	noteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("180")).
+			Italic(true)
+
+	// notePromptStyle frames the note being edited above the footer
+	notePromptStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("230")).
+			Background(lipgloss.Color("238")).
+			Padding(0, 1)
+)
+
+// noteSavedMsg reports the outcome of writing the notes file
+type noteSavedMsg struct {
+	err error
+}
+
+// LoadNotes reads position notes keyed by instrument and side, e.g.
+// "BTC-USDT-SWAP-long", from a JSON object file. A missing file has no notes.
+func LoadNotes(file string) (map[string]string, error) {
+	notes := make(map[string]string)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return notes, nil
+}
+
+// DefaultNotesFile returns the notes file in the user's config directory, or
+// one in the working directory when there is no config directory
+func DefaultNotesFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "okx-monitor-notes.json"
+	}
+	return filepath.Join(dir, "okx-tui-monitor", "notes.json")
+}
+
+// noteKey returns the key a position's note is stored under
+func noteKey(pos core.PositionData) string {
+	return fmt.Sprintf("%s-%s", pos.InstrumentID, pos.PositionSide)
+}
+
+// renderNote renders a position's note as a card line, or "" without one
+func (m Model) renderNote(pos core.PositionData) string {
+	note := m.notes[noteKey(pos)]
+	if note == "" {
+		return ""
+	}
+	return noteStyle.Render("✎ "+note) + "\n"
+}
+
+// startNoteEdit opens the note editor on the selected position
+func (m *Model) startNoteEdit() {
+	pos, ok := m.selectedPosition()
+	if !ok || m.notesFile == "" {
+		return
+	}
+	m.noteEditKey = noteKey(pos)
+	m.noteInput = m.notes[m.noteEditKey]
+}
+
+// handleNoteKey edits the note being typed: enter saves it, esc cancels and
+// backspace deletes. An empty note removes it.
+func (m *Model) handleNoteKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if note := strings.TrimSpace(m.noteInput); note == "" {
+			delete(m.notes, m.noteEditKey)
+		} else {
+			m.notes[m.noteEditKey] = note
+		}
+		m.noteEditKey = ""
+		return m.saveNotes()
+	case tea.KeyEsc:
+		m.noteEditKey = ""
+	case tea.KeyBackspace:
+		if m.noteInput != "" {
+			_, size := utf8.DecodeLastRuneInString(m.noteInput)
+			m.noteInput = m.noteInput[:len(m.noteInput)-size]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		if utf8.RuneCountInString(m.noteInput)+len(msg.Runes) <= maxNoteLength {
+			m.noteInput += string(msg.Runes)
+		}
+	}
+	return nil
+}
+
+// renderNotePrompt renders the note being edited for the footer
+func (m Model) renderNotePrompt() string {
+	return notePromptStyle.Render(fmt.Sprintf("Note for %s: %s█", m.noteEditKey, m.noteInput)) +
+		labelStyle.Render(" enter save, esc cancel")
+}
+
+// pruneNote drops a closed position's note when closed notes aren't kept
+func (m *Model) pruneNote(key string) tea.Cmd {
+	if m.keepClosedNotes {
+		return nil
+	}
+	if _, ok := m.notes[key]; !ok {
+		return nil
+	}
+	delete(m.notes, key)
+	return m.saveNotes()
+}
+
+// saveNotes writes the notes file in the background, replacing it atomically
+func (m Model) saveNotes() tea.Cmd {
+	data, err := json.MarshalIndent(m.notes, "", "  ")
+	if err != nil {
+		return func() tea.Msg { return noteSavedMsg{err: err} }
+	}
+
+	file := m.notesFile
+	return func() tea.Msg {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return noteSavedMsg{err: err}
+		}
+		tmp := file + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return noteSavedMsg{err: err}
+		}
+		return noteSavedMsg{err: os.Rename(tmp, file)}
+	}
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notifyTitle is the title of desktop notifications
+const notifyTitle = "OKX Position Monitor"
+
+// notifyCommand returns the command showing a desktop notification on this
+// platform, or nil where none is known. On macOS the text is passed as an
+// argument rather than spliced into the script, so quotes in it are harmless.
+func notifyCommand(text string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return exec.Command("notify-send", notifyTitle, text)
+	case "darwin":
+		return exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", fmt.Sprintf("display notification (item 1 of argv) with title %q", notifyTitle),
+			"-e", "end run",
+			text)
+	}
+	return nil
+}
+
+// notifyDesktop shows the alert text as a desktop notification in the background
+func notifyDesktop(text string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := notifyCommand(text)
+		if cmd == nil {
+			return errorMsg(fmt.Sprintf("DEBUG: Desktop notifications are not supported on %s", runtime.GOOS))
+		}
+		if err := cmd.Run(); err != nil {
+			return errorMsg(fmt.Sprintf("DEBUG: Desktop notification failed: %v", err))
+		}
+		return nil
+	}
+}
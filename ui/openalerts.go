@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+const (
+	// openAlertWarmup ignores positions appearing this soon after the first
+	// position update, which is OKX's snapshot of the already open ones
+	openAlertWarmup = 5 * time.Second
+
+	// bellPatternGap separates the rings of a multi-ring bell pattern
+	bellPatternGap = 200 * time.Millisecond
+
+	// maxBellPattern caps how many times one notification rings the bell
+	maxBellPattern = 5
+)
+
+// openAlert is how a new position on one side is announced
+type openAlert struct {
+	bells   int    // Times to ring the terminal bell
+	sound   bool   // Play the -alert-sound file
+	desktop bool   // Show a desktop notification
+	webhook string // URL the notification is posted to, "" for none
+}
+
+// enabled reports whether the alert announces anything
+func (a openAlert) enabled() bool {
+	return a.bells > 0 || a.sound || a.desktop || a.webhook != ""
+}
+
+// parseOpenAlert parses a comma-separated new-position alert spec: "bell" or
+// "bell:N" rings the bell N times, "sound" plays the alert sound, "desktop"
+// shows a desktop notification and an http(s) URL posts a webhook, e.g.
+// "bell:2,https://hooks.example.com/x". An empty spec disables the alert.
+func parseOpenAlert(spec string) (openAlert, error) {
+	var alert openAlert
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+		case part == "bell":
+			alert.bells = 1
+		case part == "sound":
+			alert.sound = true
+		case part == "desktop":
+			alert.desktop = true
+		case strings.HasPrefix(part, "bell:"):
+			n, err := strconv.Atoi(strings.TrimPrefix(part, "bell:"))
+			if err != nil || n < 1 || n > maxBellPattern {
+				return openAlert{}, fmt.Errorf("invalid bell pattern %q, use bell:1 to bell:%d", part, maxBellPattern)
+			}
+			alert.bells = n
+		case strings.HasPrefix(part, "http://") || strings.HasPrefix(part, "https://"):
+			alert.webhook = part
+		default:
+			return openAlert{}, fmt.Errorf("invalid open alert %q, use bell, bell:N, sound, desktop or a webhook URL", part)
+		}
+	}
+	return alert, nil
+}
+
+// positionSideName returns "long" or "short" for a position, net-mode included
+func positionSideName(pos core.PositionData) string {
+	if pos.IsShort() {
+		return "short"
+	}
+	return "long"
+}
+
+// checkOpenAlert announces a position that just appeared, using the alert
+// configured for its side. Opens within the debounce window of the last
+// announcement on that side, e.g. a basket filling at once, only get a debug
+// message.
+func (m *Model) checkOpenAlert(pos core.PositionData) tea.Cmd {
+	now := time.Now()
+	if m.firstPositionAt.IsZero() {
+		m.firstPositionAt = now
+	}
+
+	side := positionSideName(pos)
+	alert := m.openAlerts[side]
+	if !alert.enabled() || now.Sub(m.firstPositionAt) < openAlertWarmup {
+		return nil
+	}
+
+	text := fmt.Sprintf("New %s: %s %s", side, pos.InstrumentID, m.formatFixed(pos.Size, 4))
+	if now.Sub(m.lastOpenAlert[side]) < m.openDebounce {
+		m.AddDebugMessage(text + " (alert debounced)")
+		return nil
+	}
+	m.lastOpenAlert[side] = now
+
+	m.AddDebugMessage(text)
+	m.logAlert(text)
+	m.showToast(text)
+	var bells, sound, desktop tea.Cmd
+	if !m.headless {
+		bells = ringBellPattern(alert.bells)
+	}
+	if alert.sound {
+		sound = m.sound.play()
+	}
+	if alert.desktop {
+		desktop = notifyDesktop(text)
+	}
+	return tea.Batch(bells, sound, postAlertWebhook(alert.webhook, text), desktop)
+}
+
+// ringBellPattern rings the terminal bell n times, briefly apart, so sides
+// can be told apart by ear
+func ringBellPattern(n int) tea.Cmd {
+	if n <= 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				time.Sleep(bellPatternGap)
+			}
+			fmt.Fprint(os.Stderr, "\a")
+		}
+		return nil
+	}
+}
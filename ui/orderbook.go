@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// bookBarWidth is the maximum width of the cumulative size bars
+const bookBarWidth = 20
+
+var bookStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("238")).
+	Padding(1)
+
+// renderDetailView renders the selected position alongside its order book
+func (m Model) renderDetailView() string {
+	group, ok := m.selectedGroup()
+	if !ok {
+		return m.renderPositionCards()
+	}
+
+	card := m.renderGroupCard(group, true)
+	if leverage := m.renderLeverageInfo(group); leverage != "" {
+		card = lipgloss.JoinVertical(lipgloss.Left, card, leverage)
+	}
+	if spread := m.renderPriceSpread(group); spread != "" {
+		card = lipgloss.JoinVertical(lipgloss.Left, card, spread)
+	}
+	if timing := m.renderTimingInfo(group); timing != "" {
+		card = lipgloss.JoinVertical(lipgloss.Left, card, timing)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, card, m.renderOrderBook(group[0].InstrumentID))
+}
+
+// renderOrderBook renders the top 5 bids/asks with cumulative size bars
+func (m Model) renderOrderBook(instId string) string {
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render(fmt.Sprintf("Order Book %s", instId)))
+	content.WriteString("\n")
+
+	if m.bookReqCh == nil {
+		content.WriteString(labelStyle.Render("Order book unavailable"))
+		return bookStyle.Render(content.String())
+	}
+
+	book, ok := m.books[instId]
+	if !ok {
+		content.WriteString(labelStyle.Render("Waiting for order book..."))
+		return bookStyle.Render(content.String())
+	}
+
+	asks := cumulativeSizes(book.Asks)
+	bids := cumulativeSizes(book.Bids)
+
+	// Scale bars against the deepest cumulative size on either side
+	var maxCum float64
+	if len(asks) > 0 {
+		maxCum = asks[len(asks)-1]
+	}
+	if len(bids) > 0 && bids[len(bids)-1] > maxCum {
+		maxCum = bids[len(bids)-1]
+	}
+
+	// Asks are listed best-last so the spread sits in the middle
+	for i := len(book.Asks) - 1; i >= 0; i-- {
+		content.WriteString(m.renderBookLevel(book.Asks[i], asks[i], maxCum, negativeStyle))
+	}
+	content.WriteString(labelStyle.Render(strings.Repeat("─", bookBarWidth+24)))
+	content.WriteString("\n")
+	for i := range book.Bids {
+		content.WriteString(m.renderBookLevel(book.Bids[i], bids[i], maxCum, positiveStyle))
+	}
+
+	return bookStyle.Render(content.String())
+}
+
+// renderBookLevel renders a single order book row with its cumulative size bar
+func (m Model) renderBookLevel(level core.BookLevel, cum, maxCum float64, style lipgloss.Style) string {
+	barLen := 0
+	if maxCum > 0 {
+		barLen = int(cum / maxCum * bookBarWidth)
+	}
+
+	return fmt.Sprintf("%12s %10s %s\n",
+		style.Render(m.formatPrice(level.Price)),
+		m.formatFixed(level.Size, 4),
+		style.Render(strings.Repeat("█", barLen)))
+}
+
+// cumulativeSizes returns the running total of sizes from the best level outward
+func cumulativeSizes(levels []core.BookLevel) []float64 {
+	cum := make([]float64, len(levels))
+	var total float64
+	for i, level := range levels {
+		total += level.Size
+		cum[i] = total
+	}
+	return cum
+}
+
+// resubscribeBook requests the order book for the selected position in detail view,
+// or stops the subscription when leaving it
+func (m Model) resubscribeBook() tea.Cmd {
+	if m.bookReqCh == nil {
+		return nil
+	}
+
+	instId := ""
+	if m.detailView {
+		if pos, ok := m.selectedPosition(); ok {
+			instId = pos.InstrumentID
+		}
+	}
+
+	ch := m.bookReqCh
+	return func() tea.Msg {
+		ch <- instId
+		return nil
+	}
+}
+
+// waitForBookUpdate waits for order book snapshots from the channel
+func waitForBookUpdate(ch <-chan core.BookData) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		book, ok := <-ch
+		if !ok {
+			return errorMsg("Order book channel closed.")
+		}
+		return bookUpdateMsg(book)
+	}
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// minOthersPositions is the fewest small positions worth collapsing into a card
+const minOthersPositions = 2
+
+// isDust reports whether a position's notional (price × size) is below the
+// configured threshold for the Others card. Pinned instruments never are.
+func (m Model) isDust(pos core.PositionData) bool {
+	if _, pinned := m.pinIndex(pos.InstrumentID); pinned {
+		return false
+	}
+	return m.dustNotional > 0 && math.Abs(pos.CurrentPrice*pos.Size) < m.dustNotional
+}
+
+// othersCollapsed reports whether small positions are gathered into one card
+func (m Model) othersCollapsed() bool {
+	return m.dustNotional > 0 && !m.othersExpanded
+}
+
+// collapseDust moves single-position groups below the dust threshold into one
+// trailing Others group, when there are enough of them to be worth it. Hedge
+// pairs always keep their own card.
+func (m Model) collapseDust(groups [][]core.PositionData) [][]core.PositionData {
+	if !m.othersCollapsed() {
+		return groups
+	}
+
+	var kept [][]core.PositionData
+	var dust []core.PositionData
+	for _, group := range groups {
+		if len(group) == 1 && m.isDust(group[0]) {
+			dust = append(dust, group[0])
+			continue
+		}
+		kept = append(kept, group)
+	}
+	if len(dust) < minOthersPositions {
+		return groups
+	}
+	return append(kept, dust)
+}
+
+// isOthersGroup reports whether a card group is the collapsed Others card. With
+// hedge pairing on, two legs of one instrument are always a hedge group, so a
+// group can only be both all-dust and a pair when it is the Others card.
+func (m Model) isOthersGroup(group []core.PositionData) bool {
+	if !m.othersCollapsed() || len(group) < minOthersPositions {
+		return false
+	}
+	if len(group) == 2 && m.pairHedges && group[0].InstrumentID == group[1].InstrumentID {
+		return false
+	}
+	for _, pos := range group {
+		if !m.isDust(pos) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderOthersCard renders collapsed small positions as one card with their
+// combined PnL and notional
+func (m Model) renderOthersCard(group []core.PositionData, selected bool) string {
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render(fmt.Sprintf("▶ Others (%d) ◀", len(group))))
+	content.WriteString("\n")
+
+	content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("PnL:"),
+		m.renderUSDSum(group, func(pos core.PositionData) float64 { return pos.PnL })))
+
+	var notional float64
+	for _, pos := range group {
+		notional += math.Abs(pos.CurrentPrice * pos.Size)
+	}
+	content.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render("Notional:"), valueStyle.Render(m.formatAmount(notional, 2))))
+
+	// Name the instruments by coin on one line, inside the card's padding
+	var coins []string
+	for _, pos := range group {
+		coins = append(coins, strings.SplitN(pos.InstrumentID, "-", 2)[0])
+	}
+	content.WriteString(neutralStyle.Copy().MaxWidth(m.cardWidth - 2).Render(strings.Join(coins, " ")))
+	content.WriteString("\n")
+	content.WriteString(labelStyle.Render("enter to expand"))
+
+	if selected {
+		return m.sizedCard(selectedCardStyle).Render(content.String())
+	}
+	return m.sizedCard(cardStyle).Render(content.String())
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestOthersCardCollapseAndExpand(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{DustNotional: 1000})
+	m.width, m.height = 160, 60
+	m = updateModel(m,
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50000, 120),
+		testPosition("DOGE-USDT-SWAP", "long", 1000, 0.1, 0.1, 5),
+		testPosition("XRP-USDT-SWAP", "short", 500, 0.5, 0.5, -3),
+		testPosition("ADA-USDT-SWAP", "long", 1000, 0.4, 0.4, 2),
+	)
+
+	groups := m.positionGroups()
+	if len(groups) != 2 || len(groups[1]) != 3 || !m.isOthersGroup(groups[1]) {
+		t.Fatalf("groups = %v, want BTC and an Others card of 3", groupIDs(groups))
+	}
+	card := m.renderGroupCard(groups[1], false)
+	for _, want := range []string{"Others (3)", "+4.00", "750.00"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("Others card is missing %q:\n%s", want, card)
+		}
+	}
+
+	// The aggregate follows live updates
+	m = updateModel(m, testPosition("DOGE-USDT-SWAP", "long", 1000, 0.1, 0.1, 10))
+	if card := m.renderGroupCard(m.positionGroups()[1], false); !strings.Contains(card, "+9.00") {
+		t.Errorf("Others card PnL not updated:\n%s", card)
+	}
+
+	// Enter on the Others card expands it, o collapses it again
+	m.selected = 1
+	m = updateModel(m, testKey("enter"))
+	if groups := m.positionGroups(); len(groups) != 4 || m.detailView {
+		t.Fatalf("after enter: groups %v (detail %v), want 4 expanded cards", groupIDs(groups), m.detailView)
+	}
+	m = updateModel(m, testKey("o"))
+	if groups := m.positionGroups(); len(groups) != 2 || m.selected != 0 {
+		t.Errorf("after o: groups %v, selected %d; want collapsed again", groupIDs(groups), m.selected)
+	}
+}
+
+func TestOthersCardNeedsSeveralDustPositions(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{DustNotional: 1000})
+	m = updateModel(m,
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50000, 120),
+		testPosition("DOGE-USDT-SWAP", "long", 1000, 0.1, 0.1, 5),
+	)
+	if groups := m.positionGroups(); len(groups) != 2 || m.isOthersGroup(groups[1]) {
+		t.Errorf("groups = %v, want a lone small position kept as its own card", groupIDs(groups))
+	}
+
+	// Pinned instruments are never collapsed
+	m = updateModel(m, testPosition("XRP-USDT-SWAP", "long", 500, 0.5, 0.5, 1))
+	m.pins = []string{"DOGE-USDT-SWAP"}
+	if groups := m.positionGroups(); len(groups) != 3 {
+		t.Errorf("groups = %v, want the pinned DOGE kept out of Others", groupIDs(groups))
+	}
+}
+
+// groupIDs lists the instruments of each card group, for failure messages
+func groupIDs(groups [][]core.PositionData) [][]string {
+	var ids [][]string
+	for _, group := range groups {
+		var names []string
+		for _, pos := range group {
+			names = append(names, pos.InstrumentID)
+		}
+		ids = append(ids, names)
+	}
+	return ids
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// pinMarker follows a pinned instrument's name on its card
+const pinMarker = " ★"
+
+// MergePins returns the configured pins in their order followed by the ones
+// saved in the UI settings not among them, upper-cased and without duplicates
+func MergePins(configured, saved []string) []string {
+	var pins []string
+	seen := make(map[string]bool)
+	for _, instId := range append(append([]string(nil), configured...), saved...) {
+		instId = strings.ToUpper(strings.TrimSpace(instId))
+		if instId != "" && !seen[instId] {
+			seen[instId] = true
+			pins = append(pins, instId)
+		}
+	}
+	return pins
+}
+
+// pinIndex returns an instrument's place among the pins, reporting false when
+// it isn't pinned
+func (m Model) pinIndex(instId string) (int, bool) {
+	for i, pinned := range m.pins {
+		if pinned == instId {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// pinPositions moves pinned instruments to the front in pin order, keeping
+// the sorted order among the rest and between the legs of a pinned hedge
+func (m Model) pinPositions(positions []core.PositionData) {
+	if len(m.pins) == 0 {
+		return
+	}
+	rank := func(pos core.PositionData) int {
+		if i, ok := m.pinIndex(pos.InstrumentID); ok {
+			return i
+		}
+		return len(m.pins)
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return rank(positions[i]) < rank(positions[j])
+	})
+}
+
+// togglePin pins the selected card's instrument, or unpins it, keeping the
+// card selected at its new place. Pins are saved with the UI settings on quit.
+func (m *Model) togglePin() {
+	group, ok := m.selectedGroup()
+	if !ok || m.isOthersGroup(group) {
+		return
+	}
+
+	instId := group[0].InstrumentID
+	if i, pinned := m.pinIndex(instId); pinned {
+		m.pins = append(m.pins[:i:i], m.pins[i+1:]...)
+		m.showToast("Unpinned " + instId)
+	} else {
+		m.pins = append(m.pins, instId)
+		m.showToast("Pinned " + instId)
+	}
+
+	for i, group := range m.positionGroups() {
+		if group[0].InstrumentID == instId {
+			m.selected = i
+			break
+		}
+	}
+}
+
+// pinLabel returns the pin marker for a pinned instrument's card header, or ""
+func (m Model) pinLabel(instId string) string {
+	if _, ok := m.pinIndex(instId); ok {
+		return pinMarker
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPinsFirstInEverySortMode(t *testing.T) {
+	tests := []struct {
+		sort     string
+		wantRest string // Unpinned instruments below the pins
+	}{
+		{"instrument", "BBB DDD"},
+		{"latency", "DDD BBB"},
+		{"pnl", "DDD BBB"},
+		{"pnl_pct", "DDD BBB"},
+		{"size", "BBB DDD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			m := newModelWithOptions(nil, nil, nil, Options{
+				SortMode: tt.sort,
+				Pins:     MergePins([]string{"eee", "AAA"}, []string{"AAA"}),
+			})
+			for _, pos := range []positionUpdateMsg{
+				{InstrumentID: "AAA", PositionSide: "long", Size: 1, PnL: 10, PnLRatio: 0.01},
+				{InstrumentID: "BBB", PositionSide: "long", Size: 5, PnL: 100, PnLRatio: 0.1},
+				{InstrumentID: "CCC", PositionSide: "long", Size: 3, PnL: -50, PnLRatio: -0.05},
+				{InstrumentID: "DDD", PositionSide: "long", Size: 2, PnL: 300, PnLRatio: 0.3},
+				{InstrumentID: "EEE", PositionSide: "long", Size: 4, PnL: 20, PnLRatio: 0.02},
+			} {
+				m = updateModel(m, pos)
+			}
+			now := time.Now()
+			for instId, ago := range map[string]time.Duration{"AAA": 1, "BBB": 2, "CCC": 3, "DDD": 9, "EEE": 5} {
+				m.lastSeen[instId] = now.Add(-ago * time.Second)
+			}
+
+			// Pin CCC at runtime, after the configured pins
+			for i, group := range m.positionGroups() {
+				if group[0].InstrumentID == "CCC" {
+					m.selected = i
+				}
+			}
+			m.togglePin()
+			if got, _ := m.selectedPosition(); got.InstrumentID != "CCC" {
+				t.Errorf("selected %s after pinning, want CCC at its new place", got.InstrumentID)
+			}
+
+			var order []string
+			for _, pos := range m.sortedPositions() {
+				order = append(order, pos.InstrumentID)
+			}
+			if got, want := strings.Join(order, " "), "EEE AAA CCC "+tt.wantRest; got != want {
+				t.Errorf("order = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPinsSavedWithUISettings(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{Pins: []string{"BTC-USDT-SWAP"}})
+	m = updateModel(m, testPosition("ETH-USDT-SWAP", "long", 1, 3000, 3000, 0))
+	m.selected = 0
+	m.togglePin()
+
+	file := filepath.Join(t.TempDir(), "settings.json")
+	if err := SaveUISettings(file, m.UISettings()); err != nil {
+		t.Fatal(err)
+	}
+	saved := LoadUISettings(file).Pins
+	if want := []string{"BTC-USDT-SWAP", "ETH-USDT-SWAP"}; !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved pins = %v, want %v", saved, want)
+	}
+
+	// Configured pins lead on the next run, the saved ones follow once
+	if got, want := MergePins([]string{"SOL-USDT-SWAP", "ETH-USDT-SWAP"}, saved), []string{"SOL-USDT-SWAP", "ETH-USDT-SWAP", "BTC-USDT-SWAP"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MergePins() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// pnlSparkSamples is how many recent PnL values a card's sparkline shows
+const pnlSparkSamples = 30
+
+// pnlSpark is a ring buffer of a position's most recent PnL values
+type pnlSpark struct {
+	values []float64
+	next   int
+	count  int
+}
+
+// Add records a PnL value, overwriting the oldest once full
+func (s *pnlSpark) Add(pnl float64) {
+	if s.values == nil {
+		s.values = make([]float64, pnlSparkSamples)
+	}
+	size := len(s.values)
+	s.values[s.next] = pnl
+	s.next = (s.next + 1) % size
+	if s.count < size {
+		s.count++
+	}
+}
+
+// Values returns the recorded PnL values, oldest first
+func (s *pnlSpark) Values() []float64 {
+	size := len(s.values)
+	values := make([]float64, s.count)
+	for i := range values {
+		values[i] = s.values[(s.next-s.count+i+size)%size]
+	}
+	return values
+}
+
+// recordPnLSpark adds a position's PnL to its sparkline when sparklines are shown
+func (m Model) recordPnLSpark(key string, pnl float64) {
+	if !m.pnlSparkline {
+		return
+	}
+	spark, ok := m.pnlSparks[key]
+	if !ok {
+		spark = &pnlSpark{}
+		m.pnlSparks[key] = spark
+	}
+	spark.Add(pnl)
+}
+
+// renderPnLSparkline renders the position's recent PnL as a sparkline colored
+// by whether it rose or fell over the window, or "" until there are two values.
+// Narrow cards average neighbouring values so the line never widens the card.
+func (m Model) renderPnLSparkline(pos core.PositionData) string {
+	if !m.pnlSparkline {
+		return ""
+	}
+	spark, ok := m.pnlSparks[fmt.Sprintf("%s-%s", pos.InstrumentID, pos.PositionSide)]
+	if !ok || spark.count < 2 {
+		return ""
+	}
+
+	// Fit the card's content width, averaging values down on narrow cards
+	label := "Trend: "
+	values := spark.Values()
+	line := sparkline(values, m.cardWidth-2-len(label))
+	switch change := values[len(values)-1] - values[0]; {
+	case change > 0:
+		line = positiveStyle.Render(line)
+	case change < 0:
+		line = negativeStyle.Render(line)
+	default:
+		line = neutralStyle.Render(line)
+	}
+	return "\n" + labelStyle.Render(label) + line
+}
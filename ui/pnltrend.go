@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// pnlSampleSpacing is the least time between recorded PnL samples, which keeps
+// a position's history small however fast updates arrive
+const pnlSampleSpacing = 5 * time.Second
+
+// pnlSample is one observed unrealized PnL of a position
+type pnlSample struct {
+	at  time.Time
+	pnl float64
+}
+
+// pnlHistory holds a position's recent PnL samples, oldest first, covering
+// just over the longest trend interval
+type pnlHistory struct {
+	samples []pnlSample
+	limit   int // Most samples kept, 0 bounds them by age alone
+}
+
+// Add records a PnL sample unless the last one is more recent than
+// pnlSampleSpacing, dropping samples older than keep and the oldest beyond
+// the history's limit
+func (h *pnlHistory) Add(at time.Time, pnl float64, keep time.Duration) {
+	if n := len(h.samples); n > 0 && at.Sub(h.samples[n-1].at) < pnlSampleSpacing {
+		return
+	}
+	h.samples = append(h.samples, pnlSample{at: at, pnl: pnl})
+
+	// Keep one sample at or before the cutoff so the full window stays covered
+	cutoff := at.Add(-keep)
+	drop := 0
+	for drop+1 < len(h.samples) && !h.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	if h.limit > 0 && len(h.samples)-drop > h.limit {
+		drop = len(h.samples) - h.limit
+	}
+	h.samples = append(h.samples[:0], h.samples[drop:]...)
+}
+
+// At returns the PnL of the newest sample at or before t, reporting false when
+// the history doesn't reach back that far
+func (h *pnlHistory) At(t time.Time) (float64, bool) {
+	for i := len(h.samples) - 1; i >= 0; i-- {
+		if !h.samples[i].at.After(t) {
+			return h.samples[i].pnl, true
+		}
+	}
+	return 0, false
+}
+
+// parsePnLTrendIntervals parses comma-separated trend intervals like "1m,5m"
+func parsePnLTrendIntervals(value string) ([]time.Duration, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var intervals []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		interval, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || interval < pnlSampleSpacing {
+			return nil, fmt.Errorf("invalid PnL trend interval %q, use durations of at least %s like 1m,5m", part, pnlSampleSpacing)
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, nil
+}
+
+// pnlHistoryKeep is how much PnL history the trend intervals need
+func (m Model) pnlHistoryKeep() time.Duration {
+	var keep time.Duration
+	for _, interval := range m.pnlTrends {
+		if interval > keep {
+			keep = interval
+		}
+	}
+	return keep
+}
+
+// recordPnL adds a position's PnL to its history when trends are shown
+func (m Model) recordPnL(key string, pnl float64) {
+	if len(m.pnlTrends) == 0 {
+		return
+	}
+	history, ok := m.pnlHistories[key]
+	if !ok {
+		history = &pnlHistory{limit: m.bufLimits.pnl}
+		m.pnlHistories[key] = history
+	}
+	history.Add(time.Now(), pnl, m.pnlHistoryKeep())
+}
+
+// pnlChange returns how much a position's PnL changed over the interval,
+// reporting false until its history covers the interval
+func (m Model) pnlChange(pos core.PositionData, interval time.Duration) (float64, bool) {
+	history, ok := m.pnlHistories[fmt.Sprintf("%s-%s", pos.InstrumentID, pos.PositionSide)]
+	if !ok {
+		return 0, false
+	}
+	past, ok := history.At(time.Now().Add(-interval))
+	if !ok {
+		return 0, false
+	}
+	return pos.PnL - past, true
+}
+
+// renderPnLTrend renders the PnL change over each trend interval with an arrow,
+// one line each like "5m: ▲ +30.00", or "" when trends are off. Intervals without enough
+// history yet show "…".
+func (m Model) renderPnLTrend(pos core.PositionData) string {
+	if len(m.pnlTrends) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, interval := range m.pnlTrends {
+		label := labelStyle.Render(shortDuration(interval) + ":")
+		change, ok := m.pnlChange(pos, interval)
+		switch {
+		case !ok:
+			parts = append(parts, label+" "+neutralStyle.Render("…"))
+		case change > 0:
+			parts = append(parts, label+" "+positiveStyle.Render("▲ ")+m.stylePnL(change, pnlPrecision(pos)))
+		case change < 0:
+			parts = append(parts, label+" "+negativeStyle.Render("▼ ")+m.stylePnL(change, pnlPrecision(pos)))
+		default:
+			parts = append(parts, label+" "+neutralStyle.Render("= 0"))
+		}
+	}
+	return "\n" + strings.Join(parts, "\n")
+}
+
+// shortDuration formats an interval compactly, e.g. "5m" rather than "5m0s"
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
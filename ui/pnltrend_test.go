@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestPnLHistory(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := &pnlHistory{}
+	h.Add(start, 10, 5*time.Minute)
+	h.Add(start.Add(time.Second), 11, 5*time.Minute) // Within the sample spacing, skipped
+	h.Add(start.Add(time.Minute), 20, 5*time.Minute)
+	if len(h.samples) != 2 {
+		t.Fatalf("%d samples, want 2 with the close one skipped", len(h.samples))
+	}
+
+	tests := []struct {
+		at     time.Time
+		want   float64
+		wantOK bool
+	}{
+		{start.Add(-time.Second), 0, false}, // Before the history
+		{start, 10, true},
+		{start.Add(59 * time.Second), 10, true}, // Newest sample at or before
+		{start.Add(time.Hour), 20, true},
+	}
+	for _, tt := range tests {
+		if got, ok := h.At(tt.at); got != tt.want || ok != tt.wantOK {
+			t.Errorf("At(%s) = %v, %v; want %v, %v", tt.at.Sub(start), got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// Old samples are pruned, keeping one at or before the window's start
+	h.Add(start.Add(7*time.Minute), 30, 5*time.Minute)
+	if len(h.samples) != 2 || h.samples[0].pnl != 20 {
+		t.Errorf("samples after pruning = %+v, want the 1m and 7m ones", h.samples)
+	}
+
+	// The limit bounds the history regardless of age
+	limited := &pnlHistory{limit: 3}
+	for i := 0; i < 10; i++ {
+		limited.Add(start.Add(time.Duration(i)*10*time.Second), float64(i), time.Hour)
+	}
+	if len(limited.samples) != 3 || limited.samples[0].pnl != 7 {
+		t.Errorf("limited samples = %+v, want the newest 3", limited.samples)
+	}
+}
+
+func TestPnLChangeOverIntervals(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{PnLTrend: "1m,5m,10m"})
+	now := time.Now()
+	m.pnlHistories["BTC-USDT-SWAP-long"] = &pnlHistory{samples: []pnlSample{
+		{now.Add(-6 * time.Minute), 100},
+		{now.Add(-4 * time.Minute), 120},
+		{now.Add(-50 * time.Second), 150},
+	}}
+	pos := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50130, 130)
+
+	tests := []struct {
+		interval time.Duration
+		want     float64
+		wantOK   bool
+	}{
+		{time.Minute, 10, true},      // Against the 4m sample
+		{5 * time.Minute, 30, true},  // Against the 6m sample
+		{10 * time.Minute, 0, false}, // History doesn't reach back that far
+	}
+	for _, tt := range tests {
+		if got, ok := m.pnlChange(core.PositionData(pos), tt.interval); !approxEqual(got, tt.want) || ok != tt.wantOK {
+			t.Errorf("pnlChange(%s) = %v, %v; want %v, %v", tt.interval, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	trend := m.renderPnLTrend(core.PositionData(pos))
+	for _, want := range []string{"1m: ▲ +10.00", "5m: ▲ +30.00", "10m: …"} {
+		if !strings.Contains(trend, want) {
+			t.Errorf("trend %q is missing %q", trend, want)
+		}
+	}
+	pos.PnL = 90
+	if trend := m.renderPnLTrend(core.PositionData(pos)); !strings.Contains(trend, "5m: ▼ -10.00") {
+		t.Errorf("trend %q, want a falling 5m change", trend)
+	}
+}
+
+func TestParsePnLTrendIntervals(t *testing.T) {
+	intervals, err := parsePnLTrendIntervals(" 1m, 5m ,1h")
+	if err != nil || len(intervals) != 3 || intervals[2] != time.Hour {
+		t.Fatalf("parsePnLTrendIntervals() = %v, %v", intervals, err)
+	}
+	for _, value := range []string{"1m,soon", "1s", "1m,"} {
+		if _, err := parsePnLTrendIntervals(value); err == nil {
+			t.Errorf("parsePnLTrendIntervals(%q) accepted an invalid interval", value)
+		}
+	}
+	for d, want := range map[time.Duration]string{time.Minute: "1m", 5 * time.Minute: "5m", time.Hour: "1h", 90 * time.Second: "1m30s"} {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
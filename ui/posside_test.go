@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNetShortPositionStaysOpen(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	pos := testPosition("BTC-USDT-SWAP", "net", -2, 50000, 49000, 2000)
+	m = updateModel(m, pos)
+	if len(m.positions) != 1 {
+		t.Fatalf("%d positions after a net short, want it kept open", len(m.positions))
+	}
+
+	// Notional counts the size without its sign
+	if risk, want := m.renderRiskSummary(), m.formatAmount(98000, 2); !strings.Contains(risk, want) {
+		t.Errorf("risk summary does not show the %s notional:\n%s", want, risk)
+	}
+	segments := m.tapeSegments()
+	if len(segments) == 0 || !strings.HasPrefix(segments[0].text, "BTC(S)") {
+		t.Errorf("tape segments %v, want BTC marked short first", segments)
+	}
+
+	pos.Size = 0
+	m = updateModel(m, pos)
+	if _, closing := m.closing["BTC-USDT-SWAP-net"]; len(m.positions) != 0 || !closing {
+		t.Errorf("zero-size net position not closed: %d open, closing %v", len(m.positions), closing)
+	}
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// maxRecentlyClosed is the most closed positions listed below the cards
+const maxRecentlyClosed = 10
+
+// closedHistoryMsg carries an account's recently closed positions
+type closedHistoryMsg core.ClosedHistory
+
+// waitForClosedHistory waits for the next list of recently closed positions
+func waitForClosedHistory(ch <-chan core.ClosedHistory) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		history, ok := <-ch
+		if !ok {
+			return errorMsg("Closed positions channel closed.")
+		}
+		return closedHistoryMsg(history)
+	}
+}
+
+// renderRecentlyClosed renders the positions closed within the lookback with
+// their realized PnL and close time, newest first, or "" when the section is off
+func (m Model) renderRecentlyClosed() string {
+	if m.closedCh == nil {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render("Recently Closed"))
+	if !m.closedFetched {
+		content.WriteString("\n" + neutralStyle.Render("Loading..."))
+	} else if len(m.recentlyClosed) == 0 {
+		content.WriteString("\n" + neutralStyle.Render(fmt.Sprintf("No positions closed in the last %s", shortDuration(m.closedLookback))))
+	}
+	for i, closed := range m.recentlyClosed {
+		if i == maxRecentlyClosed {
+			content.WriteString("\n" + labelStyle.Render(fmt.Sprintf("+%d more", len(m.recentlyClosed)-i)))
+			break
+		}
+		content.WriteString("\n")
+		content.WriteString(labelStyle.Render(closed.ClosedAt.Format("01-02 15:04") + "  "))
+		content.WriteString(valueStyle.Render(fmt.Sprintf("%-20s %-5s ", closed.InstrumentID, strings.ToUpper(closed.PositionSide))))
+		content.WriteString(m.stylePnL(closed.RealizedPnL, 2) + " " + labelStyle.Render(closed.Currency))
+		if closed.CloseAvgPrice > 0 {
+			content.WriteString(labelStyle.Render(" @ " + m.formatPrice(closed.CloseAvgPrice)))
+		}
+	}
+	return timingStyle.Render(content.String())
+}
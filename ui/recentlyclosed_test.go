@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+func TestRenderRecentlyClosed(t *testing.T) {
+	closedAt := time.Date(2026, 3, 14, 9, 26, 0, 0, time.Local)
+	m := NewModel(nil, nil, nil)
+	if got := m.renderRecentlyClosed(); got != "" {
+		t.Errorf("section rendered with the flag off: %q", got)
+	}
+
+	m.closedCh = make(chan core.ClosedHistory)
+	m.closedLookback = 24 * time.Hour
+	if got := m.renderRecentlyClosed(); !strings.Contains(got, "Loading...") {
+		t.Errorf("before the first fetch = %q, want Loading...", got)
+	}
+
+	m = updateModel(m, closedHistoryMsg{})
+	if got := m.renderRecentlyClosed(); !strings.Contains(got, "No positions closed in the last") {
+		t.Errorf("empty history = %q, want no positions closed", got)
+	}
+
+	m = updateModel(m, closedHistoryMsg{Positions: []core.ClosedPosition{
+		{InstrumentID: "BTC-USDT-SWAP", PositionSide: "long", RealizedPnL: 125.5, CloseAvgPrice: 51000, Currency: "USDT", ClosedAt: closedAt},
+	}})
+	got := m.renderRecentlyClosed()
+	for _, want := range []string{"Recently Closed", "03-14 09:26", "BTC-USDT-SWAP", "LONG", "125.50", "USDT", "@ 51000"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("recently closed section missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderRecentlyClosedCapsList(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.closedCh = make(chan core.ClosedHistory)
+	var positions []core.ClosedPosition
+	for i := 0; i < maxRecentlyClosed+3; i++ {
+		positions = append(positions, core.ClosedPosition{InstrumentID: fmt.Sprintf("C%02d-USDT-SWAP", i), PositionSide: "long"})
+	}
+	m = updateModel(m, closedHistoryMsg{Positions: positions})
+
+	got := m.renderRecentlyClosed()
+	if !strings.Contains(got, fmt.Sprintf("C%02d-USDT-SWAP", maxRecentlyClosed-1)) || strings.Contains(got, fmt.Sprintf("C%02d-USDT-SWAP", maxRecentlyClosed)) {
+		t.Errorf("want the first %d positions listed:\n%s", maxRecentlyClosed, got)
+	}
+	if !strings.Contains(got, "+3 more") {
+		t.Errorf("no count of the positions left out:\n%s", got)
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// renderRiskSummary renders aggregated margin and notional across open positions,
+// followed by margin frozen in orders, any account borrowing and the equity
+// totaled across accounts
+func (m Model) renderRiskSummary() string {
+	var account []string
+	for _, part := range []string{m.renderOrderFrozen(), m.renderBorrowing(), m.renderGrandTotalEquity()} {
+		if part != "" {
+			account = append(account, part)
+		}
+	}
+	if len(m.positions) == 0 {
+		return strings.Join(account, labelStyle.Render(" | "))
+	}
+
+	var totalMargin, totalNotional float64
+	estimated := false
+	for _, pos := range m.positions {
+		totalMargin += pos.Margin
+		totalNotional += pos.CurrentPrice * math.Abs(pos.Size)
+		if pos.MarginEstimated {
+			estimated = true
+		}
+	}
+
+	var parts []string
+
+	marginStr := m.formatFixed(totalMargin, 2)
+	if estimated {
+		marginStr = "~" + marginStr
+	}
+	if equity := m.totalEquity(); equity > 0 {
+		marginStr += fmt.Sprintf(" (%s%% of equity)", m.formatFixed(totalMargin/equity*100, 1))
+	}
+	parts = append(parts, fmt.Sprintf("%s %s", labelStyle.Render("Margin:"), valueStyle.Render(marginStr)))
+
+	parts = append(parts, fmt.Sprintf("%s %s",
+		labelStyle.Render("Notional:"),
+		valueStyle.Render(m.formatAmount(totalNotional, 2))))
+
+	parts = append(parts, account...)
+
+	return strings.Join(parts, labelStyle.Render(" | "))
+}
+
+// renderOrderFrozen renders margin held by open orders, which is why available
+// balance can sit below equity, or "" when nothing is frozen
+func (m Model) renderOrderFrozen() string {
+	var frozen float64
+	for _, balance := range m.balances {
+		frozen += balance.OrderFrozen
+	}
+	if frozen <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", labelStyle.Render("In orders:"), valueStyle.Render(m.formatAmount(frozen, 2)))
+}
+
+// renderBorrowing renders borrowed amounts with accrued interest and the
+// auto-borrow setting for margin accounts, or "" when nothing is borrowed and
+// OKX did not report auto-borrow
+func (m Model) renderBorrowing() string {
+	var borrows []string
+	autoLoan, autoLoanReported := false, false
+	for _, balance := range m.balances {
+		for _, borrow := range balance.Borrows {
+			precision := 2
+			if !stableSettleCurrencies[borrow.Currency] {
+				precision = coinPnLPrecision
+			}
+			text := fmt.Sprintf("%s %s", m.formatFixed(borrow.Liability, precision), borrow.Currency)
+			if borrow.Interest > 0 {
+				text += fmt.Sprintf(" (+%s int)", m.formatFixed(borrow.Interest, precision))
+			}
+			borrows = append(borrows, text)
+		}
+		if balance.AutoLoanReported {
+			autoLoanReported = true
+			autoLoan = autoLoan || balance.AutoLoan
+		}
+	}
+	sort.Strings(borrows)
+
+	var parts []string
+	if len(borrows) > 0 {
+		parts = append(parts, fmt.Sprintf("%s %s", labelStyle.Render("Borrowed:"), negativeStyle.Render(strings.Join(borrows, ", "))))
+	}
+	if autoLoanReported {
+		state := "off"
+		if autoLoan {
+			state = "on"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", labelStyle.Render("Auto-borrow:"), valueStyle.Render(state)))
+	}
+	return strings.Join(parts, labelStyle.Render(" | "))
+}
+
+// totalEquity sums total equity across all currencies
+func (m Model) totalEquity() float64 {
+	var total float64
+	for _, balance := range m.balances {
+		total += balance.TotalEquity
+	}
+	return total
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRiskSummaryShowsOrderFrozenMargin(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m = updateModel(m,
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500),
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 11000, AvailBalance: 8000, OrderFrozen: 250.5},
+		balanceUpdateMsg{Currency: "USDC", TotalEquity: 1000, AvailBalance: 900, OrderFrozen: 100},
+	)
+	if risk := m.renderRiskSummary(); !strings.Contains(risk, "In orders: 350.50") {
+		t.Errorf("risk summary does not show the frozen margin:\n%s", risk)
+	}
+
+	m = updateModel(m,
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 11000, AvailBalance: 8000},
+		balanceUpdateMsg{Currency: "USDC", TotalEquity: 1000, AvailBalance: 900},
+	)
+	if risk := m.renderRiskSummary(); strings.Contains(risk, "In orders") {
+		t.Errorf("risk summary shows frozen margin with none reported:\n%s", risk)
+	}
+}
@@ -0,0 +1,78 @@
+package ui
+
+import "testing"
+
+func TestBalanceBaselineModes(t *testing.T) {
+	type step struct {
+		equity    float64
+		wantTrend int
+		wantOK    bool
+	}
+	tests := []struct {
+		name  string
+		mode  balanceBaselineMode
+		steps []step
+	}{
+		{
+			name: "tick compares against the last frame",
+			mode: balanceBaselineTick,
+			steps: []step{
+				{1000, 0, false},
+				{1010, 1, true},
+				{1005, -1, true},
+				{990, -1, true},
+				{1000, 1, true},
+			},
+		},
+		{
+			name: "session compares against the first balance",
+			mode: balanceBaselineSession,
+			steps: []step{
+				{1000, 0, true},
+				{1010, 1, true},
+				{1005, 1, true}, // Down on the last frame, still up on the session
+				{990, -1, true},
+				{1000, 0, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(nil, nil, nil)
+			m.balanceBaseline = tt.mode
+			for i, s := range tt.steps {
+				m = updateModel(m, balanceUpdateMsg{Currency: "USDT", TotalEquity: s.equity})
+				trend, ok := m.balanceColorTrend(s.equity)
+				if trend != s.wantTrend || ok != s.wantOK {
+					t.Errorf("step %d (%v): trend = %d, %v; want %d, %v", i+1, s.equity, trend, ok, s.wantTrend, s.wantOK)
+				}
+				_ = m.View()
+			}
+		})
+	}
+}
+
+func TestSessionStartIsFirstBalance(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.balanceBaseline = balanceBaselineSession
+	m = updateModel(m,
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 800},
+		balanceUpdateMsg{Currency: "USDT", TotalEquity: 900},
+		balanceUpdateMsg{Currency: "USDC", TotalEquity: 100},
+	)
+	if m.sessionStartEquity != 800 {
+		t.Errorf("session start = %v, want the first balance 800", m.sessionStartEquity)
+	}
+}
+
+func TestParseBalanceBaselineMode(t *testing.T) {
+	for value, want := range map[string]balanceBaselineMode{"": balanceBaselineTick, "tick": balanceBaselineTick, "session": balanceBaselineSession} {
+		if got, err := parseBalanceBaselineMode(value); err != nil || got != want {
+			t.Errorf("parseBalanceBaselineMode(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseBalanceBaselineMode("daily"); err == nil {
+		t.Error("parseBalanceBaselineMode(daily) accepted an unknown mode")
+	}
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// UISettings are the display preferences kept across runs
+type UISettings struct {
+	ShowDebug bool     `json:"show_debug"`
+	SortMode  string   `json:"sort_mode,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+	StaleOnly bool     `json:"stale_only"`
+	Pins      []string `json:"pins,omitempty"`
+}
+
+// DefaultUISettingsFile returns the UI settings file in the user's config
+// directory, or one in the working directory when there is no config directory
+func DefaultUISettingsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "okx-monitor-settings.json"
+	}
+	return filepath.Join(dir, "okx-tui-monitor", "settings.json")
+}
+
+// LoadUISettings reads the UI settings saved on the last quit. A missing or
+// corrupt file, or an unknown sort mode in it, falls back to the defaults.
+func LoadUISettings(file string) UISettings {
+	var settings UISettings
+	data, err := os.ReadFile(file)
+	if err != nil || json.Unmarshal(data, &settings) != nil {
+		return UISettings{}
+	}
+	if _, err := parseSortMode(settings.SortMode); err != nil {
+		settings.SortMode = ""
+	}
+	return settings
+}
+
+// SaveUISettings writes the UI settings, replacing the file atomically
+func SaveUISettings(file string, settings UISettings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// UISettings returns the model's current display preferences, to save on quit
+func (m Model) UISettings() UISettings {
+	return UISettings{
+		ShowDebug: m.showDebug,
+		SortMode:  m.sortMode.String(),
+		Filter:    m.filterText,
+		StaleOnly: m.staleOnly,
+		Pins:      m.pins,
+	}
+}
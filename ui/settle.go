@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// stableSettleCurrencies settle in (roughly) dollars and need no conversion
+var stableSettleCurrencies = map[string]bool{"USDT": true, "USDC": true, "USD": true}
+
+// coinPnLPrecision is the number of decimals for PnL in a non-dollar coin
+const coinPnLPrecision = 6
+
+// isCoinSettled reports whether the position's PnL is denominated in a coin
+// such as BTC rather than a dollar stablecoin
+func isCoinSettled(pos core.PositionData) bool {
+	ccy := pos.SettleCurrency()
+	return ccy != "" && !stableSettleCurrencies[ccy]
+}
+
+// pnlPrecision returns the decimals used to display the position's PnL
+func pnlPrecision(pos core.PositionData) int {
+	if isCoinSettled(pos) {
+		return coinPnLPrecision
+	}
+	return 2
+}
+
+// usdPrice returns the dollar price of a coin from the prices already streaming
+// for open positions. It reports false when no position tracks that coin.
+func (m Model) usdPrice(coin string) (float64, bool) {
+	for _, instId := range []string{coin + "-USD-SWAP", coin + "-USDT-SWAP", coin + "-USDC-SWAP"} {
+		for _, pos := range m.positions {
+			if pos.InstrumentID == instId && pos.CurrentPrice > 0 {
+				return pos.CurrentPrice, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// renderUSDPnL renders a coin-settled position's PnL converted to dollars, or
+// notes that no price is available for the conversion
+func (m Model) renderUSDPnL(pos core.PositionData) string {
+	price, ok := m.dollarRate(pos.SettleCurrency())
+	if !ok {
+		return neutralStyle.Render("≈ $? (no price)")
+	}
+	return labelStyle.Render("≈ $") + m.stylePnL(pos.PnL*price, 2)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+	"github.com/gandol/okx-tui-monitor/store"
+)
+
+// snapshotInterval is how often position and balance state is queued for persistence
+const snapshotInterval = 5 * time.Second
+
+// recordSnapshot hands the current positions and balances to the recorder off the
+// UI thread. Write failures are reported as debug messages so they never disrupt the UI.
+func (m Model) recordSnapshot() tea.Cmd {
+	positions := make([]core.PositionData, 0, len(m.positions))
+	for _, pos := range m.positions {
+		positions = append(positions, pos)
+	}
+	balances := make([]core.BalanceData, 0, len(m.balances))
+	for _, bal := range m.balances {
+		balances = append(balances, bal)
+	}
+
+	recorder := m.recorder
+	return func() tea.Msg {
+		if err := recorder.Record(positions, balances, time.Now()); err != nil {
+			return errorMsg(fmt.Sprintf("DEBUG: Snapshot write failed: %v", err))
+		}
+		return nil
+	}
+}
+
+// logUpdate appends an incoming update to the JSONL update log, if enabled. The
+// first write failure is reported and turns logging off rather than repeating
+// on every update.
+func (m *Model) logUpdate(write func(log *store.UpdateLog) error) {
+	if m.updateLog == nil {
+		return
+	}
+	if err := write(m.updateLog); err != nil {
+		m.SetError(fmt.Sprintf("Update log disabled: %v", err))
+		m.updateLog = nil
+	}
+}
+
+// Playback controls a replayed session such as a timelapse
+type Playback interface {
+	TogglePause() bool
+	Paused() bool
+	SetSpeed(speed float64)
+	Speed() float64
+}
+
+// handlePlaybackKey applies playback keys, reporting whether the key was consumed
+func (m *Model) handlePlaybackKey(key string) bool {
+	if m.playback == nil {
+		return false
+	}
+
+	switch key {
+	case " ":
+		if m.playback.TogglePause() {
+			m.showToast("Timelapse paused")
+		} else {
+			m.showToast("Timelapse resumed")
+		}
+	case "+", "=":
+		m.playback.SetSpeed(m.playback.Speed() * 2)
+		m.showToast(fmt.Sprintf("Timelapse speed %gx", m.playback.Speed()))
+	case "-", "_":
+		m.playback.SetSpeed(m.playback.Speed() / 2)
+		m.showToast(fmt.Sprintf("Timelapse speed %gx", m.playback.Speed()))
+	default:
+		return false
+	}
+	return true
+}
+
+// playbackStatus renders the timelapse state for the footer
+func (m Model) playbackStatus() string {
+	if m.playback == nil {
+		return ""
+	}
+
+	status := fmt.Sprintf(" | Timelapse: %gx", m.playback.Speed())
+	if m.playback.Paused() {
+		status += " (paused)"
+	}
+	return status + " | Space pause | +/- speed"
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// sortMode controls the order position cards are displayed in
+type sortMode int
+
+const (
+	sortByInstrument sortMode = iota // Alphabetical by instrument, then side
+	sortByLatency                    // Stalest instrument first
+	sortByPnL                        // Largest unrealized PnL first
+	sortByPnLPct                     // Largest unrealized PnL ratio first
+	sortBySize                       // Largest absolute position size first
+)
+
+// sortModeNames maps sort modes to their flag and footer names
+var sortModeNames = []string{"instrument", "latency", "pnl", "pnl_pct", "size"}
+
+func (s sortMode) String() string {
+	return sortModeNames[s]
+}
+
+// parseSortMode parses a sort mode name such as "instrument" or "latency"
+func parseSortMode(value string) (sortMode, error) {
+	if value == "" {
+		return sortByInstrument, nil
+	}
+	for i, name := range sortModeNames {
+		if value == name {
+			return sortMode(i), nil
+		}
+	}
+	return sortByInstrument, fmt.Errorf("invalid sort mode %q, use one of: %s", value, strings.Join(sortModeNames, ", "))
+}
+
+// sortValue returns the value a position is ordered by, largest first, in the
+// PnL and size sort modes
+func (m Model) sortValue(pos core.PositionData) float64 {
+	switch m.sortMode {
+	case sortByPnL:
+		return pos.PnL
+	case sortByPnLPct:
+		return pos.PnLRatio
+	case sortBySize:
+		return math.Abs(pos.Size)
+	}
+	return 0
+}
+
+// sortPositions orders positions for display according to the current sort mode.
+// Ties fall back to instrument then side so hedge legs stay adjacent; with hedge
+// pairing on, both legs sort by the larger of their values for the same reason.
+func (m Model) sortPositions(positions []core.PositionData) {
+	// Largest value per instrument, for hedge pairs
+	instValues := make(map[string]float64)
+	for _, pos := range positions {
+		if prev, ok := instValues[pos.InstrumentID]; !ok || m.sortValue(pos) > prev {
+			instValues[pos.InstrumentID] = m.sortValue(pos)
+		}
+	}
+	valueOf := func(pos core.PositionData) float64 {
+		if m.pairHedges {
+			return instValues[pos.InstrumentID]
+		}
+		return m.sortValue(pos)
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		switch m.sortMode {
+		case sortByLatency:
+			// Whole seconds keep cards from reshuffling on every tick
+			ageI := m.instrumentAge(positions[i].InstrumentID).Truncate(time.Second)
+			ageJ := m.instrumentAge(positions[j].InstrumentID).Truncate(time.Second)
+			if ageI != ageJ {
+				return ageI > ageJ
+			}
+		case sortByPnL, sortByPnLPct, sortBySize:
+			if valueI, valueJ := valueOf(positions[i]), valueOf(positions[j]); valueI != valueJ {
+				return valueI > valueJ
+			}
+		}
+		if positions[i].InstrumentID == positions[j].InstrumentID {
+			return positions[i].PositionSide < positions[j].PositionSide
+		}
+		return positions[i].InstrumentID < positions[j].InstrumentID
+	})
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// soundDebounce is the least time between the starts of two alert sounds, on
+// top of never overlapping a sound still playing
+const soundDebounce = 2 * time.Second
+
+// soundPlayers are the command-line players tried in order per platform when
+// no player is configured. "{file}" is replaced with the sound file.
+var soundPlayers = map[string][][]string{
+	"darwin": {{"afplay", "{file}"}},
+	"linux": {
+		{"paplay", "{file}"},
+		{"aplay", "-q", "{file}"},
+		{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", "{file}"},
+	},
+	"windows": {{"powershell", "-NoProfile", "-Command", "(New-Object Media.SoundPlayer '{file}').PlaySync()"}},
+}
+
+// alertSound plays a sound file on alerts through an external player. It is
+// shared by model copies, so playback state survives Update.
+type alertSound struct {
+	args []string // Player command with the file substituted
+
+	mu      sync.Mutex
+	playing bool
+	lastAt  time.Time
+}
+
+// newAlertSound sets up playback of file with the given player command, e.g.
+// "mpv --no-video {file}", or the first installed platform player when player
+// is "" or "auto". The file is appended when the command has no "{file}".
+func newAlertSound(file, player string) (*alertSound, error) {
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+
+	var candidates [][]string
+	if player == "" || player == "auto" {
+		candidates = soundPlayers[runtime.GOOS]
+	} else {
+		args := strings.Fields(player)
+		if !strings.Contains(player, "{file}") {
+			args = append(args, "{file}")
+		}
+		candidates = [][]string{args}
+	}
+
+	var tried []string
+	for _, candidate := range candidates {
+		tried = append(tried, candidate[0])
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+		args := make([]string, len(candidate))
+		for i, arg := range candidate {
+			args[i] = strings.ReplaceAll(arg, "{file}", file)
+		}
+		return &alertSound{args: args}, nil
+	}
+	if len(tried) == 0 {
+		return nil, fmt.Errorf("no known audio player on %s, set one with -alert-sound-player", runtime.GOOS)
+	}
+	return nil, fmt.Errorf("no audio player found (tried %s)", strings.Join(tried, ", "))
+}
+
+// play plays the sound in the background, unless it is still playing or
+// started within the debounce window. A nil alertSound plays nothing.
+func (s *alertSound) play() tea.Cmd {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	if s.playing || time.Since(s.lastAt) < soundDebounce {
+		s.mu.Unlock()
+		return nil
+	}
+	s.playing, s.lastAt = true, time.Now()
+	s.mu.Unlock()
+
+	return func() tea.Msg {
+		err := exec.Command(s.args[0], s.args[1:]...).Run()
+
+		s.mu.Lock()
+		s.playing = false
+		s.mu.Unlock()
+
+		if err != nil {
+			return errorMsg(fmt.Sprintf("DEBUG: Alert sound failed: %v", err))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// soundFile returns an empty file to stand in for an alert sound
+func soundFile(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "alert.wav")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestNewAlertSound(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("no true command to stand in for a player")
+	}
+	file := soundFile(t)
+
+	tests := []struct {
+		player   string
+		wantArgs []string
+	}{
+		{"true --quiet", []string{"true", "--quiet", file}}, // File appended
+		{"true -f {file} --loop", []string{"true", "-f", file, "--loop"}},
+	}
+	for _, tt := range tests {
+		sound, err := newAlertSound(file, tt.player)
+		if err != nil || !reflect.DeepEqual(sound.args, tt.wantArgs) {
+			t.Errorf("newAlertSound(%q) = %v, %v; want %v", tt.player, sound, err, tt.wantArgs)
+		}
+	}
+
+	if _, err := newAlertSound(filepath.Join(t.TempDir(), "missing.wav"), "true"); err == nil {
+		t.Error("missing sound file accepted")
+	}
+	if _, err := newAlertSound(file, "no-such-player-here {file}"); err == nil || !strings.Contains(err.Error(), "tried no-such-player-here") {
+		t.Errorf("missing player error = %v, want the player tried named", err)
+	}
+}
+
+func TestAlertSoundDebounce(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("no true command to stand in for a player")
+	}
+	sound, err := newAlertSound(soundFile(t), "true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := sound.play()
+	if cmd == nil {
+		t.Fatal("first alert played no sound")
+	}
+	if sound.play() != nil {
+		t.Error("second sound overlapped one still playing")
+	}
+	if msg := cmd(); msg != nil {
+		t.Errorf("playback = %v, want it to finish cleanly", msg)
+	}
+	if sound.play() != nil {
+		t.Errorf("sound replayed within %v of the last one", soundDebounce)
+	}
+
+	sound.lastAt = time.Now().Add(-soundDebounce)
+	if sound.play() == nil {
+		t.Error("no sound once the debounce passed")
+	}
+
+	var none *alertSound
+	if none.play() != nil {
+		t.Error("sound played with none configured")
+	}
+}
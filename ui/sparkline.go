@@ -0,0 +1,51 @@
+package ui
+
+// sparkBlocks are the unicode levels used for sparklines, lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a unicode block sparkline of at most width runes,
+// averaging neighbouring values when there are more values than width
+func sparkline(values []float64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	values = downsample(values, width)
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		if v < low {
+			low = v
+		}
+		if v > high {
+			high = v
+		}
+	}
+
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(sparkBlocks)-1))
+		}
+		runes[i] = sparkBlocks[level]
+	}
+	return string(runes)
+}
+
+// downsample averages neighbouring values down to at most width values
+func downsample(values []float64, width int) []float64 {
+	if len(values) <= width {
+		return values
+	}
+	sampled := make([]float64, width)
+	for i := range sampled {
+		from := i * len(values) / width
+		to := (i + 1) * len(values) / width
+		var sum float64
+		for _, v := range values[from:to] {
+			sum += v
+		}
+		sampled[i] = sum / float64(to-from)
+	}
+	return sampled
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+var spreadStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#6B7280")).
+	Padding(0, 1)
+
+// renderPriceSpread renders the entry, mark and last prices of the selected
+// group side by side, since PnL is marked at the mark price but exits trade at
+// the last. The basis is colored green when exiting at the last price beats
+// the mark the PnL assumes. A missing mark or last price is left out. Returns
+// "" when the spread display is off.
+func (m Model) renderPriceSpread(group []core.PositionData) string {
+	if !m.priceSpread {
+		return ""
+	}
+
+	var parts []string
+	for _, pos := range group {
+		label := "entry"
+		if len(group) > 1 {
+			label += " " + strings.ToUpper(pos.PositionSide[:1])
+		}
+		parts = append(parts, labelStyle.Render(label+" ")+valueStyle.Render(m.formatPrice(pos.AvgPrice)))
+	}
+
+	pos := group[0]
+	mark, last := pos.CurrentPrice, pos.LastPrice
+	if pos.PriceIsLast {
+		mark = 0
+	}
+	if mark > 0 {
+		parts = append(parts, labelStyle.Render("mark ")+valueStyle.Render(m.formatPrice(mark)))
+	}
+	if last > 0 {
+		parts = append(parts, labelStyle.Render("last ")+valueStyle.Render(m.formatPrice(last)))
+	}
+
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render("Prices"))
+	content.WriteString("\n")
+	content.WriteString(strings.Join(parts, labelStyle.Render(" | ")))
+	if mark > 0 && last > 0 {
+		content.WriteString("\n" + labelStyle.Render("last−mark: ") + m.renderBasis(group, last-mark, mark))
+	}
+	return spreadStyle.Render(content.String())
+}
+
+// renderBasis renders the last-minus-mark basis and its percentage of the
+// mark, colored by whether it favors exiting the position at the last price.
+// A hedge's legs pull opposite ways, so its basis stays neutral.
+func (m Model) renderBasis(group []core.PositionData, basis, mark float64) string {
+	text := m.formatSigned(basis, priceDecimals(mark)) + " (" + m.formatSigned(basis/mark*100, 3) + "%)"
+	favor := basis
+	if group[0].IsShort() {
+		favor = -basis
+	}
+	switch {
+	case len(group) > 1 || favor == 0:
+		return neutralStyle.Render(text)
+	case favor > 0:
+		return positiveStyle.Render(text)
+	default:
+		return negativeStyle.Render(text)
+	}
+}
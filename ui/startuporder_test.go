@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPositionsBeforeBalanceRenderStably(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.kpis = kpiNames
+	m = updateModel(m,
+		tea.WindowSizeMsg{Width: 120, Height: 40},
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500),
+		testPosition("ETH-USDT-SWAP", "short", 2, 3000, 3100, -200),
+		tickMsg{},
+	)
+
+	if got := m.renderBalance(); got != "" {
+		t.Errorf("renderBalance() = %q before any balance, want nothing", got)
+	}
+	for name, got := range map[string]string{
+		"view":      m.View(),
+		"total pnl": m.renderTotalPnL(),
+		"kpis":      m.renderKPIRow(120),
+		"risk":      m.renderRiskSummary(),
+		"available": m.renderAvailable(0),
+	} {
+		if strings.Contains(got, "NaN") || strings.Contains(got, "Inf") {
+			t.Errorf("%s renders a non-finite value before the balance:\n%s", name, got)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "BTC-USDT-SWAP") || !strings.Contains(view, "ETH-USDT-SWAP") {
+		t.Errorf("positions missing from the view before the balance:\n%s", view)
+	}
+
+	// The first balance then fills in the header
+	m = updateModel(m, balanceUpdateMsg{Currency: "USDT", TotalEquity: 1000, AvailBalance: 900})
+	if got := m.renderBalance(); !strings.Contains(got, "1000.00 USDT") {
+		t.Errorf("renderBalance() = %q after the balance, want 1000.00 USDT", got)
+	}
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tapeScrollInterval is how often the ticker tape advances by one column
+const tapeScrollInterval = 200 * time.Millisecond
+
+// tapeSeparator is placed between ticker tape entries and at the wrap point
+const tapeSeparator = "   •   "
+
+// tapeTickMsg advances the ticker tape scroll position. The generation ties it
+// to one run of tape mode so toggling quickly never leaves two chains running.
+type tapeTickMsg struct {
+	gen int
+}
+
+// tapeTick schedules the next ticker tape scroll step
+func tapeTick(gen int) tea.Cmd {
+	return tea.Tick(tapeScrollInterval, func(time.Time) tea.Msg {
+		return tapeTickMsg{gen: gen}
+	})
+}
+
+// tapeSegment is a run of tape text sharing one style
+type tapeSegment struct {
+	text  string
+	style lipgloss.Style
+}
+
+// tapeSegments builds the ticker tape entries from the displayed positions,
+// e.g. "BTC +1.20%", each colored by PnL sign
+func (m Model) tapeSegments() []tapeSegment {
+	var segments []tapeSegment
+	for _, pos := range m.sortedPositions() {
+		name := strings.SplitN(pos.InstrumentID, "-", 2)[0]
+		if pos.IsShort() {
+			name += "(S)"
+		}
+
+		style := neutralStyle
+		if pos.PnLRatio > 0 {
+			style = positiveStyle
+		} else if pos.PnLRatio < 0 {
+			style = negativeStyle
+		}
+
+		segments = append(segments,
+			tapeSegment{text: name + " ", style: valueStyle},
+			tapeSegment{text: m.formatSigned(pos.PnLRatio, 2) + "%", style: style},
+			tapeSegment{text: tapeSeparator, style: labelStyle})
+	}
+	return segments
+}
+
+// renderTape renders the ticker tape as a single line of the terminal width,
+// scrolling through the entries when they don't all fit
+func (m Model) renderTape() string {
+	segments := m.tapeSegments()
+	if len(segments) == 0 {
+		return labelStyle.Render("No positions")
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+
+	// Flatten to runes tagged with their segment so the window can start mid-entry
+	var runes []rune
+	var owners []int
+	for i, seg := range segments {
+		for _, r := range seg.text {
+			runes = append(runes, r)
+			owners = append(owners, i)
+		}
+	}
+
+	// Entries that fit are shown without scrolling, minus the trailing separator
+	start := 0
+	count := width
+	if len(runes) <= width {
+		count = len(runes) - len([]rune(tapeSeparator))
+	} else {
+		start = m.tapeOffset % len(runes)
+	}
+
+	// Render runs of runes from the same segment with that segment's style
+	var line strings.Builder
+	var run []rune
+	owner := -1
+	for i := 0; i < count; i++ {
+		idx := (start + i) % len(runes)
+		if owners[idx] != owner && len(run) > 0 {
+			line.WriteString(segments[owner].style.Render(string(run)))
+			run = run[:0]
+		}
+		owner = owners[idx]
+		run = append(run, runes[idx])
+	}
+	if len(run) > 0 {
+		line.WriteString(segments[owner].style.Render(string(run)))
+	}
+	return line.String()
+}
+
+// startTape starts a new chain of tape scroll ticks when ticker tape mode is on
+func (m *Model) startTape() tea.Cmd {
+	if !m.tapeMode {
+		return nil
+	}
+	m.tapeGen++
+	return tapeTick(m.tapeGen)
+}
+
+// initialTapeTick starts the first tape tick chain when starting in ticker tape mode
+func (m Model) initialTapeTick() tea.Cmd {
+	if !m.tapeMode {
+		return nil
+	}
+	return tapeTick(m.tapeGen)
+}
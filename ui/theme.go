@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// themeElements recolors one element of the interface for a theme override
+var themeElements = map[string]func(color lipgloss.Color){
+	"title": func(color lipgloss.Color) {
+		titleStyle = titleStyle.Foreground(color)
+		cardHeaderStyle = cardHeaderStyle.Foreground(color)
+	},
+	"label":    func(color lipgloss.Color) { labelStyle = labelStyle.Foreground(color) },
+	"value":    func(color lipgloss.Color) { valueStyle = valueStyle.Foreground(color) },
+	"positive": func(color lipgloss.Color) { positiveStyle = positiveStyle.Foreground(color) },
+	"negative": func(color lipgloss.Color) { negativeStyle = negativeStyle.Foreground(color) },
+	"neutral":  func(color lipgloss.Color) { neutralStyle = neutralStyle.Foreground(color) },
+	"error":    func(color lipgloss.Color) { errorStyle = errorStyle.Foreground(color) },
+	"border": func(color lipgloss.Color) {
+		baseStyle = baseStyle.BorderForeground(color)
+		cardStyle = cardStyle.BorderForeground(color)
+	},
+	"selected": func(color lipgloss.Color) { selectedCardStyle = selectedCardStyle.BorderForeground(color) },
+	"debug": func(color lipgloss.Color) {
+		debugStyle = debugStyle.Foreground(color).BorderForeground(color)
+	},
+	"debug_header": func(color lipgloss.Color) { debugHeaderStyle = debugHeaderStyle.Foreground(color) },
+}
+
+// applyTheme overrides the colors of the named elements with ANSI (e.g. "46")
+// or hex (e.g. "#00ff00") colors. Styles are shared by every model, so it is
+// applied once at startup.
+func applyTheme(colors map[string]string) error {
+	if err := validateTheme(colors); err != nil {
+		return err
+	}
+	for name, color := range colors {
+		themeElements[strings.ToLower(name)](lipgloss.Color(strings.TrimSpace(color)))
+	}
+	return nil
+}
+
+// validateTheme checks theme overrides name known elements and have colors
+func validateTheme(colors map[string]string) error {
+	for name, color := range colors {
+		if _, ok := themeElements[strings.ToLower(name)]; !ok {
+			names := make([]string, 0, len(themeElements))
+			for element := range themeElements {
+				names = append(names, element)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown theme element %q, use any of: %s", name, strings.Join(names, ", "))
+		}
+		if strings.TrimSpace(color) == "" {
+			return fmt.Errorf("theme element %q has no color", name)
+		}
+	}
+	return nil
+}
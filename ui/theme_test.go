@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestApplyThemeDebugPane(t *testing.T) {
+	savedDebug, savedHeader := debugStyle, debugHeaderStyle
+	defer func() { debugStyle, debugHeaderStyle = savedDebug, savedHeader }()
+
+	if err := applyTheme(map[string]string{"debug": "#123456", "Debug_Header": " 99 "}); err != nil {
+		t.Fatal(err)
+	}
+	if got := debugStyle.GetForeground(); got != lipgloss.Color("#123456") {
+		t.Errorf("debug foreground = %v, want #123456", got)
+	}
+	if got := debugStyle.GetBorderTopForeground(); got != lipgloss.Color("#123456") {
+		t.Errorf("debug border = %v, want #123456", got)
+	}
+	if got := debugHeaderStyle.GetForeground(); got != lipgloss.Color("99") {
+		t.Errorf("debug header foreground = %v, want 99", got)
+	}
+}
+
+func TestApplyThemeRejectsUnknownElements(t *testing.T) {
+	savedValue := valueStyle
+	defer func() { valueStyle = savedValue }()
+
+	err := applyTheme(map[string]string{"value": "46", "debugger": "46"})
+	if err == nil || !strings.Contains(err.Error(), "debug, debug_header") {
+		t.Fatalf("err = %v, want the unknown element listing debug and debug_header", err)
+	}
+	if valueStyle.GetForeground() != savedValue.GetForeground() {
+		t.Error("known elements recolored despite the unknown one")
+	}
+}
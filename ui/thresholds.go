@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startThresholdEdit opens the alert threshold prompt, filled in with the
+// current loss and gain thresholds
+func (m *Model) startThresholdEdit() {
+	m.thresholdEdit = true
+	m.thresholdInput = formatThresholds(m.lossAlertPct, m.gainAlertPct)
+	m.thresholdErr = ""
+}
+
+// formatThresholds formats loss and gain thresholds as the prompt takes them
+func formatThresholds(loss, gain float64) string {
+	return strconv.FormatFloat(loss, 'f', -1, 64) + "," + strconv.FormatFloat(gain, 'f', -1, 64)
+}
+
+// parseThresholds parses "loss,gain" PnL % alert thresholds, e.g. "5,10". A
+// single value sets the loss threshold and keeps gain; 0 disables an alert.
+func parseThresholds(value string, gain float64) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("use loss,gain in PnL %%, e.g. 5,10")
+	}
+
+	values := []float64{0, gain}
+	for i, part := range parts {
+		pct, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || pct < 0 {
+			return 0, 0, fmt.Errorf("invalid threshold %q, use a PnL %% of at least 0", strings.TrimSpace(part))
+		}
+		values[i] = pct
+	}
+	return values[0], values[1], nil
+}
+
+// handleThresholdKey edits the alert thresholds being typed: enter applies
+// them, checking open positions against them at once, esc cancels and
+// backspace deletes. Invalid thresholds keep the prompt open.
+func (m *Model) handleThresholdKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		loss, gain, err := parseThresholds(m.thresholdInput, m.gainAlertPct)
+		if err != nil {
+			m.thresholdErr = err.Error()
+			return nil
+		}
+		m.lossAlertPct, m.gainAlertPct = loss, gain
+		m.thresholdEdit = false
+		m.showToast(fmt.Sprintf("Alerts: loss %s, gain %s", thresholdLabel(loss, "-"), thresholdLabel(gain, "+")))
+		return m.checkAlerts()
+	case tea.KeyEsc:
+		m.thresholdEdit = false
+	case tea.KeyBackspace:
+		if m.thresholdInput != "" {
+			_, size := utf8.DecodeLastRuneInString(m.thresholdInput)
+			m.thresholdInput = m.thresholdInput[:len(m.thresholdInput)-size]
+		}
+		m.thresholdErr = ""
+	case tea.KeyRunes:
+		m.thresholdInput += string(msg.Runes)
+		m.thresholdErr = ""
+	}
+	return nil
+}
+
+// thresholdLabel describes a threshold for the toast, "off" when disabled
+func thresholdLabel(pct float64, sign string) string {
+	if pct <= 0 {
+		return "off"
+	}
+	return sign + strconv.FormatFloat(pct, 'f', -1, 64) + "%"
+}
+
+// renderThresholdPrompt renders the thresholds being edited for the footer
+func (m Model) renderThresholdPrompt() string {
+	prompt := notePromptStyle.Render(fmt.Sprintf("Alert loss,gain %%: %s█", m.thresholdInput)) +
+		labelStyle.Render(" enter apply, esc cancel, 0 disables")
+	if m.thresholdErr != "" {
+		prompt += " " + errorStyle.Render(m.thresholdErr)
+	}
+	return prompt
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseThresholds(t *testing.T) {
+	tests := []struct {
+		value      string
+		loss, gain float64
+		wantErr    bool
+	}{
+		{"5,10", 5, 10, false},
+		{" 2.5 , 0 ", 2.5, 0, false},
+		{"3", 3, 7, false}, // Keeps the current gain threshold
+		{"-1,10", 0, 0, true},
+		{"5,ten", 0, 0, true},
+		{"1,2,3", 0, 0, true},
+		{"", 0, 0, true},
+	}
+	for _, tt := range tests {
+		loss, gain, err := parseThresholds(tt.value, 7)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseThresholds(%q) = %v, %v; want an error", tt.value, loss, gain)
+			}
+			continue
+		}
+		if err != nil || loss != tt.loss || gain != tt.gain {
+			t.Errorf("parseThresholds(%q) = %v, %v, %v; want %v, %v", tt.value, loss, gain, err, tt.loss, tt.gain)
+		}
+	}
+}
+
+func TestThresholdPromptSetsAlerts(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	pos := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 49000, -1000)
+	pos.PnLRatio = -20
+	m = updateModel(m, pos)
+
+	m = updateModel(m, testKey("A"))
+	if !m.thresholdEdit || m.thresholdInput != "0,0" {
+		t.Fatalf("prompt open = %v with %q, want it open with the current thresholds", m.thresholdEdit, m.thresholdInput)
+	}
+
+	// Keys go to the prompt rather than their usual bindings
+	m = updateModel(m, testKey("backspace"), testKey("backspace"), testKey("backspace"), testKey("5"), testKey(","), testKey("q"))
+	if m.thresholdInput != "5,q" {
+		t.Fatalf("typed %q, want 5,q", m.thresholdInput)
+	}
+
+	// Invalid thresholds keep the prompt open with the reason
+	m = updateModel(m, testKey("enter"))
+	if !m.thresholdEdit || m.thresholdErr == "" {
+		t.Fatalf("invalid input closed the prompt (open %v, err %q)", m.thresholdEdit, m.thresholdErr)
+	}
+	if !strings.Contains(m.View(), m.thresholdErr) {
+		t.Errorf("view does not show %q", m.thresholdErr)
+	}
+
+	m = updateModel(m, testKey("backspace"), testKey("1"), testKey("2"), testKey("enter"))
+	if m.thresholdEdit || m.lossAlertPct != 5 || m.gainAlertPct != 12 {
+		t.Fatalf("thresholds = %v/%v (open %v), want 5/12 applied", m.lossAlertPct, m.gainAlertPct, m.thresholdEdit)
+	}
+
+	// The open position is already past the new loss threshold
+	if len(m.alerted) != 1 || !strings.HasPrefix(m.toastMsg, "Loss alert: BTC-USDT-SWAP") {
+		t.Errorf("loss alert did not fire for the open position, toast %q", m.toastMsg)
+	}
+}
+
+func TestThresholdPromptEscKeepsThresholds(t *testing.T) {
+	m := NewModel(nil, nil, nil)
+	m.lossAlertPct, m.gainAlertPct = 5, 10
+	m = updateModel(m, testKey("A"), testKey("backspace"), testKey("9"), testKey("esc"))
+	if m.thresholdEdit || m.lossAlertPct != 5 || m.gainAlertPct != 10 {
+		t.Errorf("thresholds = %v/%v (open %v) after esc, want 5/10 unchanged", m.lossAlertPct, m.gainAlertPct, m.thresholdEdit)
+	}
+}
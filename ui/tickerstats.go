@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// tickerUpdateMsg carries the latest bid, ask and 24h volume of an instrument
+type tickerUpdateMsg core.TickerData
+
+// waitForTickerUpdate waits for ticker stats from the channel
+func waitForTickerUpdate(ch <-chan core.TickerData) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		ticker, ok := <-ch
+		if !ok {
+			return errorMsg("Ticker channel closed.")
+		}
+		return tickerUpdateMsg(ticker)
+	}
+}
+
+// toggleTickerStats shows or hides the bid/ask spread and 24h volume on cards
+func (m *Model) toggleTickerStats() {
+	if m.tickerCh == nil {
+		m.showToast("Ticker stats unavailable with the mark-price feed")
+		return
+	}
+	m.showTickerStats = !m.showTickerStats
+	if m.showTickerStats {
+		m.showToast("Showing bid/ask spread and 24h volume")
+	} else {
+		m.showToast("Hiding bid/ask spread and 24h volume")
+	}
+}
+
+// renderTickerStats renders an instrument's bid/ask, spread and 24h volume
+// lines for its card, or "" while they are hidden
+func (m Model) renderTickerStats(instId string) string {
+	if !m.showTickerStats {
+		return ""
+	}
+	ticker, ok := m.tickers[instId]
+	if !ok {
+		return "\n" + labelStyle.Render("Bid/Ask:") + " " + neutralStyle.Render("waiting...")
+	}
+
+	bidAsk := "\n" + labelStyle.Render("Bid/Ask:") + " "
+	if ticker.BidPrice > 0 && ticker.AskPrice > 0 {
+		spread := ticker.AskPrice - ticker.BidPrice
+		mid := (ticker.AskPrice + ticker.BidPrice) / 2
+		bidAsk += valueStyle.Render(m.formatPrice(ticker.BidPrice)+" / "+m.formatPrice(ticker.AskPrice)) +
+			"\n" + labelStyle.Render("Spread:") + " " +
+			valueStyle.Render(fmt.Sprintf("%s (%s bp)", m.formatFixed(spread, priceDecimals(mid)), m.formatFixed(spread/mid*10000, 1)))
+	} else {
+		bidAsk += neutralStyle.Render("n/a")
+	}
+	return bidAsk + "\n" + labelStyle.Render("Vol 24h:") + " " + valueStyle.Render(m.formatCompact(ticker.Volume24h, 2))
+}
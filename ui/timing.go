@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+var timingStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#6B7280")).
+	Padding(0, 1)
+
+// renderTimingInfo renders the raw exchange timestamp, local receipt time and
+// latency for each position in the group, or "" when timing display is off
+func (m Model) renderTimingInfo(group []core.PositionData) string {
+	if !m.showDebug && !m.showTimestamps {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(cardHeaderStyle.Render("Timing"))
+	for _, pos := range group {
+		content.WriteString("\n")
+		if len(group) > 1 {
+			content.WriteString(valueStyle.Render(strings.ToUpper(pos.PositionSide)) + "\n")
+		}
+		content.WriteString(labelStyle.Render("OKX ts: ") + valueStyle.Render(formatMillis(pos.Timestamp)) + "\n")
+		if pos.ReceivedAt == 0 {
+			content.WriteString(labelStyle.Render("Received: ") + neutralStyle.Render("n/a"))
+			continue
+		}
+		content.WriteString(labelStyle.Render("Received: ") + valueStyle.Render(formatMillis(pos.ReceivedAt)) + "\n")
+		content.WriteString(labelStyle.Render("Latency: ") + valueStyle.Render(fmt.Sprintf("%dms", pos.ReceivedAt-pos.Timestamp)))
+	}
+	return timingStyle.Render(content.String())
+}
+
+// formatMillis renders an epoch millisecond timestamp raw and as local time
+func formatMillis(ms int64) string {
+	t := time.Unix(0, ms*int64(time.Millisecond))
+	return fmt.Sprintf("%d (%s)", ms, t.Format("15:04:05.000"))
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gandol/okx-tui-monitor/core"
+)
+
+// maxTradeLines limits how many prints the trades pane shows at once
+const maxTradeLines = 10
+
+var (
+	tradesStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Margin(1, 0, 0, 0)
+
+	tradesHeaderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("86")).
+				Bold(true)
+)
+
+// renderTradesSection renders the most recent trade prints across watched instruments
+func (m Model) renderTradesSection() string {
+	// Merge per-instrument buffers into a single feed
+	var feed []core.TradeData
+	for _, buf := range m.trades {
+		feed = append(feed, buf...)
+	}
+
+	var content strings.Builder
+	content.WriteString(tradesHeaderStyle.Render("Recent Trades"))
+	content.WriteString("\n")
+
+	if len(feed) == 0 {
+		content.WriteString(labelStyle.Render("Waiting for trades..."))
+		return tradesStyle.Render(content.String())
+	}
+
+	// Newest prints first
+	sort.SliceStable(feed, func(i, j int) bool {
+		return feed[i].Timestamp > feed[j].Timestamp
+	})
+	if len(feed) > maxTradeLines {
+		feed = feed[:maxTradeLines]
+	}
+
+	for _, trade := range feed {
+		tradeTime := time.Unix(0, trade.Timestamp*int64(time.Millisecond)).Format("15:04:05")
+
+		line := fmt.Sprintf("%-16s %-4s %12s %12s", trade.InstrumentID, trade.Side, m.formatPrice(trade.Price), m.formatFixed(trade.Size, 4))
+		if trade.Side == "buy" {
+			line = positiveStyle.Render(line)
+		} else {
+			line = negativeStyle.Render(line)
+		}
+
+		content.WriteString(fmt.Sprintf("%s %s\n", timeStyle.Render(tradeTime), line))
+	}
+
+	return tradesStyle.Render(content.String())
+}
+
+// waitForTradeUpdate waits for trade prints from the channel
+func waitForTradeUpdate(ch <-chan core.TradeData) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		trade, ok := <-ch
+		if !ok {
+			return errorMsg("Trades channel closed.")
+		}
+		return tradeUpdateMsg(trade)
+	}
+}
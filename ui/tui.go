@@ -2,11 +2,13 @@ package ui
 
 import (
 	"fmt"
-	"sort"
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gandol/okx-tui-monitor/core"
+	"github.com/gandol/okx-tui-monitor/store"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -35,9 +37,12 @@ var (
 		BorderForeground(lipgloss.Color("238")).
 		Padding(1).
 		Margin(0, 1, 1, 0).
-		Width(24).
+		Width(defaultCardWidth).
 		Height(12)
 
+	selectedCardStyle = cardStyle.Copy().
+		BorderForeground(lipgloss.Color("86"))
+
 	cardHeaderStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.Color("86")).
 		Bold(true).
@@ -78,7 +83,9 @@ var (
 type Model struct {
 	positions       map[string]core.PositionData
 	balances        map[string]core.BalanceData
-	previousBalance float64 // Store previous total balance for color comparison
+	rendered        *renderedBalance // Balance as of the last rendered frame, baseline for color
+	balanceBaseline balanceBaselineMode // What the balance color is compared against
+	sessionStartEquity float64          // Total equity at the first balance update
 	positionCh      <-chan core.PositionData
 	balanceCh       <-chan core.BalanceData
 	errorCh         <-chan string
@@ -91,6 +98,225 @@ type Model struct {
 	debugMessages   []string
 	maxDebugLines   int
 	showDebug       bool // Toggle for debug output visibility
+	debugWidth      int  // Fixed debug line width, 0 fits the terminal
+	debugCursor     int  // Selected debug line, counted back from the newest
+	debugExpanded   bool // Show the selected debug line in full
+	debugOut        io.Writer // Optional stream every debug message is also written to
+	tradeCh         <-chan core.TradeData
+	trades          map[string][]core.TradeData // Recent trade prints per instrument
+	showTrades      bool                        // Toggle for recent trades pane visibility
+	tickerCh        <-chan core.TickerData
+	tickers         map[string]core.TickerData  // Latest bid, ask and 24h volume per instrument
+	showTickerStats bool                        // Show bid/ask spread and 24h volume on cards
+	selected        int                         // Index of the selected card in sorted order
+	detailView      bool                        // Show detail view for the selected position
+	bookCh          <-chan core.BookData
+	bookReqCh       chan<- string               // Requests books5 for an instrument, "" to stop
+	pauseReqCh      chan<- bool                 // Pauses (true) or resumes (false) the client's feed
+	feedPaused      bool                        // Subscriptions paused with p
+	books           map[string]core.BookData    // Latest order book per instrument
+	lossAlertPct    float64                     // Fire a loss alert when PnL% drops below -lossAlertPct, 0 disables
+	alertSelect     bool                        // Auto-select the worst-PnL position when an alert fires
+	alerted         map[string]bool             // Positions currently in alert, rearmed on recovery
+	gainAlertPct    float64                     // Fire a gain alert when PnL% rises above gainAlertPct, 0 disables
+	gainAlerted     map[string]bool             // Positions currently in gain alert, rearmed on pullback
+	alertHysteresis float64                     // PnL% points past a threshold before its alert rearms
+	alertFlashes    map[string]alertFlash       // Cards highlighted after a PnL alert, by position key
+	lastManualNav   time.Time                   // Last manual scroll/selection, suppresses auto-select
+	toastMsg        string                      // Transient notification shown in the footer
+	toastUntil      time.Time
+	warnings        []string                    // Persistent warnings, e.g. unknown configured instruments
+	recorder        *store.Recorder             // Optional SQLite snapshot persistence
+	updateLog       *store.UpdateLog            // Optional JSONL log of every update, nil disables it
+	lastSnapshot    time.Time                   // Time of the last queued snapshot
+	playback        Playback                    // Timelapse controls, nil for live data
+	equity          *equityHistory              // Bounded total equity history
+	equityWindow    time.Duration               // Equity window displayed, 0 for the whole session
+	pairHedges      bool                        // Render both legs of a hedge as one card
+	focused         bool                        // Terminal focus as reported by focus events
+	pauseUnfocused  bool                        // Throttle the clock and renders while unfocused
+	viewCache       *viewCache                  // Last rendered frame, reused while unfocused
+	showTimestamps  bool                        // Show raw OKX timestamps and latency in the detail view
+	priceSpread     bool                        // Show entry, mark and last prices in the detail view
+	showMaxLeverage bool                        // Show leverage against the exchange maximum in the detail view
+	maxLeverage     map[string]float64          // Exchange maximum leverage per instrument, nil until fetched
+	maxLevLoading   bool                        // Instrument metadata fetch in progress
+	metaRefresh     time.Duration               // Interval cached instrument metadata is fetched again, 0 never
+	sortMode        sortMode                    // Card display order
+	staleOnly       bool                        // Only show instruments without recent updates
+	filterText      string                      // Only show instruments containing this, ignoring case
+	filtering       bool                        // Instrument filter prompt open, taking key input
+	thresholdEdit   bool                        // Alert threshold prompt open, taking key input
+	thresholdInput  string                      // Alert thresholds being typed, as loss,gain
+	thresholdErr    string                      // Why the typed thresholds were rejected, "" when fine
+	staleAfter      time.Duration               // Time without updates before an instrument is stale
+	staleGrace      time.Duration               // Extra wait before marking a card stale, absorbs brief feed gaps
+	reconnectStart  time.Time                   // When the current or last reconnect began
+	lastSeen        map[string]time.Time        // Local time of the last update per instrument
+	cardFields      []string                    // Position card fields in display order
+	tapeMode        bool                        // Show a single scrolling ticker tape line instead of cards
+	showEquityChart bool                        // Show the full-screen equity chart instead of cards
+	tapeOffset      int                         // Ticker tape scroll position in runes
+	tapeGen         int                         // Current ticker tape tick chain
+	negativeAvail   negativeAvailMode           // How a negative available balance is shown
+	usdConvert      bool                        // Also show coin-settled PnL converted to dollars
+	fxRates         map[string]fxRate           // Dollar rates of settlement currencies for totals
+	fxPrices        map[string]float64          // Latest prices of the tickers live FX rates follow
+	colorCurrent    bool                        // Color the current price by side of entry
+	feedDiagnostics bool                        // Show per-instrument feed timeout countdowns
+	kpis            []string                    // Account KPIs in the summary row, in display order
+	debugInstruments map[string]bool            // Instruments whose update traces are logged, nil for all
+	priceRings      map[string]*priceRing       // Recent prices per instrument for the liquidation ETA
+	minChange       minChange                   // Smallest PnL move an update must make to be shown
+	pnlTrends       []time.Duration             // Intervals to show PnL change over on cards, nil hides them
+	pnlHistories    map[string]*pnlHistory      // Recent PnL per position for the trend intervals
+	pnlSparks       map[string]*pnlSpark        // Last PnL values per position for the card sparklines
+	pnlSparkline    bool                        // Show a sparkline of recent PnL on each card
+	cardWidth       int                         // Position card width, fewer columns fit rather than narrower cards
+	liqWarnPct      float64                     // Warn on cards within this % of liquidation, 0 disables
+	liqETA          bool                        // Add a trend-based time estimate to liquidation warnings
+	statusCh        <-chan core.ConnState       // Connection state updates, nil when not connected live
+	connState       core.ConnState              // Latest connection state
+	refreshPending  map[string]bool             // Positions kept from before a reconnect, not yet refreshed
+	marginAlertPct  float64                     // Alert when a margin ratio drops below this %, 0 disables
+	marginAlerted   map[string]bool             // Positions currently in margin alert
+	liqAlertPct     float64                     // Alert when a position comes within this % of liquidation, 0 disables
+	liqAlerted      map[string]bool             // Positions currently in liquidation alert
+	alertRules      []AlertRule                 // Per-instrument alert overrides
+	priceAlerted    map[string]bool             // Positions currently in price alert
+	alertWebhook    string                      // Optional URL alerts are posted to
+	alertDesktop    bool                        // Also show alerts as desktop notifications
+	headless        bool                        // Running without a terminal, so alerts never ring the bell
+	alertLog        io.Writer                   // Alerts are written here one per line, nil for none
+	sound           *alertSound                 // Plays the alert sound file, nil when off
+	openAlerts      map[string]openAlert        // New-position alert per side, "long" and "short"
+	openDebounce    time.Duration               // Least time between new-position alerts on one side
+	lastOpenAlert   map[string]time.Time        // When each side last announced a new position
+	firstPositionAt time.Time                   // First position update, new-position alerts wait out the snapshot
+	panicAt         time.Time                   // Render panics from this time on, zero disables
+	compactPnL      bool                        // Abbreviate PnL and notional amounts with K/M suffixes
+	rounding        roundingMode                // Rounding of displayed values
+	accounts        []string                    // Account labels in switcher order, nil with a single account
+	account         string                      // Label of the account shown
+	accountStates   map[string]*accountState    // State of the accounts not shown, by label
+	accountTotals   bool                        // Show equity and PnL totaled across accounts
+	tickInterval    time.Duration               // Clock and redraw interval while focused
+	notes           map[string]string           // Position notes keyed by instrument and side
+	notesFile       string                      // File notes are saved to, "" disables editing
+	keepClosedNotes bool                        // Keep notes of positions that close
+	noteEditKey     string                      // Position whose note is being edited, "" when not editing
+	noteInput       string                      // Note text being edited
+	dustNotional    float64                     // Collapse positions below this notional into an Others card, 0 disables
+	othersExpanded  bool                        // Show collapsed small positions as their own cards
+	closing         map[string]closedPosition   // Just-closed positions still flashed on screen
+	closedCh        <-chan core.ClosedHistory   // Recently closed positions, nil hides the section
+	closedLookback  time.Duration               // How far back the recently closed positions go
+	recentlyClosed  []core.ClosedPosition       // Positions closed within the lookback, newest first
+	closedFetched   bool                        // Recently closed positions have arrived for the account
+	bufLimits       bufferLimits                // Caps of each kind of history buffer
+	bufferCap       int                         // Most samples across per-instrument buffers, negative uncaps
+	pins            []string                    // Instruments shown first, in this order
+	heapInUse       uint64                      // Heap bytes in use when last sampled, for diagnostics
+	heapSampledAt   time.Time                   // When the heap in use was last sampled
+	backpressure    *core.Backpressure          // Full and dropped counts of the client channels, nil when not counted
+	restClient      *http.Client                // HTTP client for REST requests like instrument metadata, nil for the default
+}
+
+// Options holds optional settings for the TUI
+type Options struct {
+	Debug     bool                  // Start with debug output visible
+	TradeCh    <-chan core.TradeData // Recent trades feed, nil disables the trades pane
+	BookCh     <-chan core.BookData  // Order book snapshots for the detail view
+	TickerCh   <-chan core.TickerData // Bid, ask and 24h volume per instrument, nil disables the t toggle
+	BookReqCh  chan<- string         // Order book subscription requests, nil disables the book
+	PauseReqCh chan<- bool           // Feed pause/resume requests, nil disables pausing
+
+	LossAlertPct float64 // Loss alert threshold in PnL %, 0 disables
+	GainAlertPct float64 // Gain alert threshold in PnL %, 0 disables
+	AlertHysteresis float64 // PnL % points a position must move back before its loss or gain alert rearms, 0 keeps the default, negative rearms right away
+	AlertSelect  bool    // Auto-select the worst-PnL position when an alert fires
+
+	Recorder *store.Recorder // Periodic position/balance snapshots, nil disables persistence
+	UpdateLog *store.UpdateLog // Every position/balance update appended as JSONL, nil disables logging
+	Playback Playback        // Timelapse playback controls, nil for live data
+
+	EquityWindow string        // Equity history window: a duration like "5m" or "session"
+	EquityBucket time.Duration // Equity history aggregation bucket width
+
+	PairHedges bool // Render long and short legs of the same instrument as one card
+
+	MarginAlertPct float64 // Margin ratio alert threshold in %, 0 disables
+	LiqAlertPct    float64 // Liquidation distance alert threshold in % of the price, 0 disables
+	AlertWebhook   string  // URL alerts are posted to as JSON, empty disables
+	AlertDesktop   bool    // Show alerts as desktop notifications too
+	AlertSound       string // Sound file played on alerts, empty disables
+	AlertSoundPlayer string // Command playing the sound, "{file}" marks the file; empty or "auto" finds one
+
+
+	OpenAlertLong     string        // Alert for new long positions: bell, bell:N, sound, desktop and/or a webhook URL, empty disables
+	OpenAlertShort    string        // Alert for new short positions, like OpenAlertLong
+	OpenAlertDebounce time.Duration // Least time between new-position alerts on one side
+	AlertRules     []AlertRule // Per-instrument loss and price thresholds
+
+	PauseUnfocused bool // Throttle the clock and renders while the terminal is unfocused
+	DebugWidth     int  // Truncate debug lines to this width, 0 fits the terminal
+	DebugInstruments []string // Only log per-instrument update traces for these, empty for all
+	Rounding       string // Displayed value rounding: "default", "half-up" or "truncate"
+	NegativeAvail  string // Negative available balance display: "show" or "clamp"
+	USDConvert     bool   // Also show coin-settled PnL in dollars using streamed prices
+	FXRates        string // Currency dollar rates for totals like "USDC=0.9998,EUR=live", empty treats stables as 1:1
+	ColorCurrent   bool   // Color the current price green/red by side of entry, side-aware
+	FeedDiagnostics bool   // Show a countdown to each instrument's feed stale threshold
+	LiqWarnPct     float64 // Warn on cards within this % of the liquidation price, 0 disables
+	LiqETA         bool    // Estimate time to liquidation from the recent price trend
+	MinCardWidth   int     // Minimum position card width, below the default it has no effect
+	Notes          map[string]string // Position notes loaded from NotesFile
+	NotesFile      string  // File position notes are saved to, "" disables editing
+	KeepClosedNotes bool   // Keep notes of closed positions instead of pruning them
+	DustNotional   float64 // Collapse positions with notional (price × size) below this into one card, 0 disables
+	CompactPnL     bool    // Abbreviate PnL and notional amounts with K/M suffixes, PnL % stays full
+	PanicAfter     time.Duration // Panic while rendering this long after start, to test crash handling; 0 disables
+
+	StatusCh <-chan core.ConnState // Connection state updates from the client
+
+	Pins []string // Instruments shown first whatever the sort, in this order
+
+	BufferLimits string // Per-buffer caps like "price=240,trades=20", empty keeps the defaults
+	BufferCap    int    // Most samples across per-instrument history buffers, 0 keeps the default, negative uncaps
+
+	Backpressure *core.Backpressure // Client channel full counts for the diagnostics, nil hides them
+	RESTClient   *http.Client       // HTTP client for REST requests like instrument metadata, e.g. through the proxy; nil for the default
+
+	ClosedCh       <-chan core.ClosedHistory // Recently closed positions, nil hides the section
+	ClosedLookback time.Duration             // How far back the recently closed positions go
+
+	Accounts      []string // Account labels in switcher order, the first shown at start; nil for a single account
+	AccountTotals bool     // Show equity and PnL totaled across accounts in dollars, with per-account subtotals
+
+	RefreshInterval time.Duration     // Clock and redraw interval, 0 keeps the default 1s
+	MetadataRefresh time.Duration     // Interval cached instrument metadata is fetched again, 0 never
+	Theme           map[string]string // Colors by element (title, label, value, positive, ...), nil keeps the defaults
+
+	CardFields string // Comma-separated position card fields, empty uses the default layout
+	MinChange  string // Smallest PnL move to redraw for: N units of the last PnL digit or N%, empty redraws on every update
+	PnLTrend   string // Comma-separated intervals to show PnL change over, e.g. "1m,5m", empty hides them
+	KPIs       string // Comma-separated account KPIs for the summary row, "none" hides it
+
+	BalanceBaseline string // Balance color baseline: "tick" (last frame) or "session" (session start)
+	Tape       bool   // Start in ticker tape mode
+
+	NoAltScreen bool      // Render inline instead of on the alternate screen
+	DebugWriter io.Writer // Also write debug messages here, e.g. os.Stderr
+	ShowTimestamps bool // Show raw OKX timestamps and latency in the detail view
+	PriceSpread    bool // Show entry, mark and last prices with their basis in the detail view
+	PnLSparkline   bool // Show a sparkline of each position's recent PnL on its card
+	ShowMaxLeverage bool // Show leverage against the exchange maximum in the detail view
+
+	SortMode   string        // Initial card order, one of sortModeNames: "instrument", "latency", "pnl", "pnl_pct" or "size"
+	StaleAfter time.Duration // Time without updates before an instrument is stale, 0 uses the default
+	StaleGrace time.Duration // Extra wait before a card is marked stale, 0 marks it right away
+	StaleOnly  bool          // Start showing only instruments without recent updates
+	Filter     string        // Initial instrument filter, empty shows all
 }
 
 // NewProgram creates a new Bubble Tea program
@@ -101,9 +327,183 @@ func NewProgram(positionCh <-chan core.PositionData, balanceCh <-chan core.Balan
 
 // NewProgramWithDebug creates a new Bubble Tea program with debug mode enabled
 func NewProgramWithDebug(positionCh <-chan core.PositionData, balanceCh <-chan core.BalanceData, errorCh <-chan string) *tea.Program {
+	return NewProgramWithOptions(positionCh, balanceCh, errorCh, Options{Debug: true})
+}
+
+// NewProgramWithOptions creates a new Bubble Tea program with the given options
+func NewProgramWithOptions(positionCh <-chan core.PositionData, balanceCh <-chan core.BalanceData, errorCh <-chan string, opts Options) *tea.Program {
+	model := newModelWithOptions(positionCh, balanceCh, errorCh, opts)
+
+	var programOpts []tea.ProgramOption
+	if !opts.NoAltScreen {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	if opts.PauseUnfocused {
+		programOpts = append(programOpts, tea.WithReportFocus())
+	}
+	// Panics reach the caller of Run, which restores the terminal and logs them;
+	// Bubble Tea's own handler would swallow them and exit successfully
+	programOpts = append(programOpts, tea.WithoutCatchPanics())
+	return tea.NewProgram(model, programOpts...)
+}
+
+// newModelWithOptions creates a model with the given options applied, for a
+// program or for running headless
+func newModelWithOptions(positionCh <-chan core.PositionData, balanceCh <-chan core.BalanceData, errorCh <-chan string, opts Options) Model {
 	model := NewModel(positionCh, balanceCh, errorCh)
-	model.showDebug = true // Enable debug mode
-	return tea.NewProgram(model, tea.WithAltScreen())
+	model.showDebug = opts.Debug
+	model.tradeCh = opts.TradeCh
+	model.bookCh = opts.BookCh
+	model.tickerCh = opts.TickerCh
+	model.bookReqCh = opts.BookReqCh
+	model.pauseReqCh = opts.PauseReqCh
+	model.lossAlertPct = opts.LossAlertPct
+	model.gainAlertPct = opts.GainAlertPct
+	if opts.AlertHysteresis > 0 {
+		model.alertHysteresis = opts.AlertHysteresis
+	} else if opts.AlertHysteresis < 0 {
+		model.alertHysteresis = 0
+	}
+	model.alertSelect = opts.AlertSelect
+	model.recorder = opts.Recorder
+	model.updateLog = opts.UpdateLog
+	model.playback = opts.Playback
+	if limits, err := parseBufferLimits(opts.BufferLimits); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.bufLimits = limits
+	}
+	model.pins = opts.Pins
+	if opts.BufferCap != 0 {
+		model.bufferCap = opts.BufferCap
+	}
+	model.equity = newEquityHistory(opts.EquityBucket, model.bufLimits.equity)
+	model.backpressure = opts.Backpressure
+	model.restClient = opts.RESTClient
+	model.setAccounts(opts.Accounts)
+	model.accountTotals = opts.AccountTotals
+	if opts.RefreshInterval > 0 {
+		model.tickInterval = opts.RefreshInterval
+	}
+	model.metaRefresh = opts.MetadataRefresh
+	model.closedCh = opts.ClosedCh
+	model.closedLookback = opts.ClosedLookback
+	if err := applyTheme(opts.Theme); err != nil {
+		model.SetError(err.Error())
+	}
+	model.pairHedges = opts.PairHedges
+	model.marginAlertPct = opts.MarginAlertPct
+	model.liqAlertPct = opts.LiqAlertPct
+	model.alertWebhook = opts.AlertWebhook
+	model.alertDesktop = opts.AlertDesktop
+	if opts.AlertSound != "" {
+		// Without a working player alerts still ring the bell
+		if sound, err := newAlertSound(opts.AlertSound, opts.AlertSoundPlayer); err != nil {
+			model.addWarning(fmt.Sprintf("Alert sound disabled: %v", err))
+		} else {
+			model.sound = sound
+		}
+	}
+	for side, spec := range map[string]string{"long": opts.OpenAlertLong, "short": opts.OpenAlertShort} {
+		if alert, err := parseOpenAlert(spec); err != nil {
+			model.SetError(fmt.Sprintf("-open-alert-%s: %v", side, err))
+		} else {
+			model.openAlerts[side] = alert
+		}
+	}
+	model.openDebounce = opts.OpenAlertDebounce
+	model.alertRules = opts.AlertRules
+	if window, err := parseEquityWindow(opts.EquityWindow); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.equityWindow = window
+	}
+	model.pauseUnfocused = opts.PauseUnfocused
+	model.debugWidth = opts.DebugWidth
+	model.usdConvert = opts.USDConvert
+	if rates, err := parseFXRates(opts.FXRates); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.fxRates = rates
+	}
+	model.colorCurrent = opts.ColorCurrent
+	model.feedDiagnostics = opts.FeedDiagnostics
+	model.liqWarnPct = opts.LiqWarnPct
+	model.liqETA = opts.LiqETA
+	model.compactPnL = opts.CompactPnL
+	model.dustNotional = opts.DustNotional
+	if opts.Notes != nil {
+		model.notes = opts.Notes
+	}
+	model.notesFile = opts.NotesFile
+	model.keepClosedNotes = opts.KeepClosedNotes
+	if opts.PanicAfter > 0 {
+		model.panicAt = time.Now().Add(opts.PanicAfter)
+	}
+	if opts.MinCardWidth > model.cardWidth {
+		model.cardWidth = opts.MinCardWidth
+	}
+	for _, instId := range opts.DebugInstruments {
+		if model.debugInstruments == nil {
+			model.debugInstruments = make(map[string]bool)
+		}
+		model.debugInstruments[instId] = true
+	}
+	model.statusCh = opts.StatusCh
+	if mode, err := parseNegativeAvailMode(opts.NegativeAvail); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.negativeAvail = mode
+	}
+	if mode, err := parseRoundingMode(opts.Rounding); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.rounding = mode
+	}
+	model.tapeMode = opts.Tape
+	model.debugOut = opts.DebugWriter
+	model.showTimestamps = opts.ShowTimestamps
+	model.priceSpread = opts.PriceSpread
+	model.pnlSparkline = opts.PnLSparkline
+	model.showMaxLeverage = opts.ShowMaxLeverage
+	model.maxLevLoading = opts.ShowMaxLeverage
+	if mode, err := parseSortMode(opts.SortMode); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.sortMode = mode
+	}
+	model.staleOnly = opts.StaleOnly
+	model.filterText = opts.Filter
+	if fields, err := parseCardFields(opts.CardFields); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.cardFields = fields
+	}
+	if change, err := parseMinChange(opts.MinChange); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.minChange = change
+	}
+	if intervals, err := parsePnLTrendIntervals(opts.PnLTrend); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.pnlTrends = intervals
+	}
+	if kpis, err := parseKPIs(opts.KPIs); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.kpis = kpis
+	}
+	if mode, err := parseBalanceBaselineMode(opts.BalanceBaseline); err != nil {
+		model.SetError(err.Error())
+	} else {
+		model.balanceBaseline = mode
+	}
+	if opts.StaleAfter > 0 {
+		model.staleAfter = opts.StaleAfter
+	}
+	model.staleGrace = opts.StaleGrace
+	return model
 }
 
 // NewModel creates a new model
@@ -119,34 +519,92 @@ func NewModel(positionCh <-chan core.PositionData, balanceCh <-chan core.Balance
 		debugMessages: make([]string, 0),
 		maxDebugLines: 10, // Keep last 10 debug messages
 		showDebug:     false, // Debug output hidden by default
+		trades:        make(map[string][]core.TradeData),
+		tickers:       make(map[string]core.TickerData),
+		bufLimits:     defaultBufferLimits,
+		bufferCap:     defaultBufferCap,
+		books:         make(map[string]core.BookData),
+		alerted:       make(map[string]bool),
+		marginAlerted: make(map[string]bool),
+		liqAlerted:    make(map[string]bool),
+		priceAlerted:  make(map[string]bool),
+		gainAlerted:   make(map[string]bool),
+		alertHysteresis: defaultAlertHysteresis,
+		alertFlashes:  make(map[string]alertFlash),
+		openAlerts:    make(map[string]openAlert),
+		lastOpenAlert: make(map[string]time.Time),
+		refreshPending: make(map[string]bool),
+		equity:        newEquityHistory(0, 0),
+		focused:       true, // Assume focus until the terminal reports otherwise
+		viewCache:     &viewCache{},
+		rendered:      &renderedBalance{},
+		staleAfter:    defaultStaleAfter,
+		lastSeen:      make(map[string]time.Time),
+		priceRings:    make(map[string]*priceRing),
+		pnlHistories:  make(map[string]*pnlHistory),
+		pnlSparks:     make(map[string]*pnlSpark),
+		notes:         make(map[string]string),
+		cardWidth:     defaultCardWidth,
+		cardFields:    defaultCardFields,
+		kpis:          defaultKPIs,
+		tickInterval:  defaultTickInterval,
+		metaRefresh:   defaultMetadataRefresh,
+		fxRates:       defaultFXRates,
+		fxPrices:      make(map[string]float64),
 	}
 }
 
 // renderPositionCards renders all position cards in a dynamic grid based on terminal width
 func (m Model) renderPositionCards() string {
-	if len(m.positions) == 0 {
+	// Create cards from sorted positions, followed by those that just closed
+	var cards []string
+	for i, group := range m.positionGroups() {
+		cards = append(cards, m.renderGroupCard(group, i == m.selected))
+	}
+	cards = append(cards, m.renderClosedCards()...)
+	if len(cards) == 0 && len(m.positions) == 0 {
 		return ""
 	}
 
-	// Convert map to slice for sorting
-	var positions []core.PositionData
-	for _, pos := range m.positions {
-		positions = append(positions, pos)
+	if len(cards) == 0 && m.filterText != "" {
+		return m.sizedCard(cardStyle).Render(neutralStyle.Render(fmt.Sprintf("No positions match %q", m.filterText)))
+	}
+	if len(cards) == 0 && m.staleOnly {
+		return m.sizedCard(cardStyle).Render(neutralStyle.Render(fmt.Sprintf("No positions stale for over %s", m.staleAfter)))
 	}
 
-	// Sort positions by InstrumentID (coin name) in ascending order
-	sort.Slice(positions, func(i, j int) bool {
-		return positions[i].InstrumentID < positions[j].InstrumentID
-	})
+	cardsPerRow := m.cardsPerRow()
+	
+	// Create rows with calculated cards per row
+	var rows []string
+	for i := 0; i < len(cards); i += cardsPerRow {
+		end := i + cardsPerRow
+		if end > len(cards) {
+			end = len(cards)
+		}
+		
+		rowCards := cards[i:end]
+		row := lipgloss.JoinHorizontal(lipgloss.Top, rowCards...)
+		rows = append(rows, row)
+	}
 
-	// Create cards from sorted positions
-	var cards []string
-	for _, pos := range positions {
-		cards = append(cards, m.renderPositionCard(pos))
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// defaultCardWidth is the width of a position card unless a larger minimum is set
+const defaultCardWidth = 24
+
+// sizedCard applies the configured card width to a card style
+func (m Model) sizedCard(style lipgloss.Style) lipgloss.Style {
+	if m.cardWidth == defaultCardWidth {
+		return style
 	}
+	return style.Copy().Width(m.cardWidth)
+}
 
-	// Calculate dynamic cards per row based on terminal width
-	cardWidth := 26 // Each card is 24 chars wide + 2 chars margin
+// cardsPerRow calculates dynamic cards per row based on terminal width
+func (m Model) cardsPerRow() int {
+	cardWidth := m.cardWidth + 2 // Each card plus 2 chars margin, never squeezed below its width
 	availableWidth := m.width - 8 // Account for base style padding and borders
 	if availableWidth < 40 {
 		availableWidth = 40 // Minimum width
@@ -159,21 +617,67 @@ func (m Model) renderPositionCards() string {
 	if cardsPerRow > 8 {
 		cardsPerRow = 8 // Maximum 8 cards per row for readability
 	}
-	
-	// Create rows with calculated cards per row
-	var rows []string
-	for i := 0; i < len(cards); i += cardsPerRow {
-		end := i + cardsPerRow
-		if end > len(cards) {
-			end = len(cards)
+	return cardsPerRow
+}
+
+// sortedPositions returns positions in display order
+func (m Model) sortedPositions() []core.PositionData {
+	// Convert map to slice for sorting
+	var positions []core.PositionData
+	for _, pos := range m.positions {
+		// Hide instruments that are still updating when the stale filter is on
+		if m.staleOnly && !m.isStale(pos.InstrumentID) {
+			continue
 		}
-		
-		rowCards := cards[i:end]
-		row := lipgloss.JoinHorizontal(lipgloss.Top, rowCards...)
-		rows = append(rows, row)
+		// Hide instruments that don't match the filter
+		if !m.matchesFilter(pos) {
+			continue
+		}
+		positions = append(positions, pos)
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	// Sort positions by the active sort mode, pinned instruments first
+	m.sortPositions(positions)
+	m.pinPositions(positions)
+
+	return positions
+}
+
+// positionGroups returns positions in display order grouped per card. Each group
+// holds a single position, or both legs of a hedge when hedge pairing is enabled,
+// except for the last, which gathers small positions when they are collapsed.
+func (m Model) positionGroups() [][]core.PositionData {
+	positions := m.sortedPositions()
+
+	var groups [][]core.PositionData
+	for i := 0; i < len(positions); i++ {
+		// Legs of the same instrument are adjacent after sorting
+		if m.pairHedges && i+1 < len(positions) && positions[i+1].InstrumentID == positions[i].InstrumentID {
+			groups = append(groups, positions[i:i+2])
+			i++
+			continue
+		}
+		groups = append(groups, positions[i:i+1])
+	}
+	return m.collapseDust(groups)
+}
+
+// selectedGroup returns the positions of the currently selected card, if any
+func (m Model) selectedGroup() ([]core.PositionData, bool) {
+	groups := m.positionGroups()
+	if m.selected < 0 || m.selected >= len(groups) {
+		return nil, false
+	}
+	return groups[m.selected], true
+}
+
+// selectedPosition returns the currently selected position, if any
+func (m Model) selectedPosition() (core.PositionData, bool) {
+	group, ok := m.selectedGroup()
+	if !ok {
+		return core.PositionData{}, false
+	}
+	return group[0], true
 }
 
 // renderBalance renders the total account balance with color coding based on change
@@ -199,18 +703,23 @@ func (m Model) renderBalance() string {
 	}
 
 	// Format balance with appropriate styling
-	balanceText := fmt.Sprintf("%.2f %s", totalEquity, mainCurrency)
+	balanceText := fmt.Sprintf("%s %s", m.formatFixed(totalEquity, 2), mainCurrency)
 	
-	// Style based on balance change compared to previous balance
+	// Style based on net balance change since the last rendered frame, so several
+	// rapid updates in between can't make the color oscillate. This frame then
+	// becomes the baseline for the next.
 	var styledBalance string
-	if m.previousBalance > 0 { // Only apply color coding if we have a previous balance to compare
-		if totalEquity > m.previousBalance {
-			// Current balance is greater than previous - green
+	trend, ok := m.balanceColorTrend(totalEquity)
+	m.rendered.commit(totalEquity, m.pendingBalanceTrend(totalEquity))
+	if ok { // Only apply color coding if we have a baseline to compare
+		switch trend {
+		case 1:
+			// Balance went up - green
 			styledBalance = positiveStyle.Render(balanceText)
-		} else if totalEquity < m.previousBalance {
-			// Current balance is less than previous - red
+		case -1:
+			// Balance went down - red
 			styledBalance = negativeStyle.Render(balanceText)
-		} else {
+		default:
 			// Balance unchanged - neutral
 			styledBalance = neutralStyle.Render(balanceText)
 		}
@@ -219,95 +728,66 @@ func (m Model) renderBalance() string {
 		styledBalance = valueStyle.Render(balanceText)
 	}
 
-	return fmt.Sprintf("%s %s", 
+	return fmt.Sprintf("%s %s  %s", 
 		labelStyle.Render("Balance:"), 
-		styledBalance)
+		styledBalance,
+		m.renderAvailable(totalEquity))
+}
+
+// pendingBalanceTrend returns the direction of total relative to the last rendered
+// balance, or the last rendered trend when nothing changed since then
+func (m Model) pendingBalanceTrend(total float64) int {
+	if total > m.rendered.total {
+		return 1
+	} else if total < m.rendered.total {
+		return -1
+	}
+	return m.rendered.trend
 }
 
 // renderPositionCard renders a single position card
-func (m Model) renderPositionCard(pos core.PositionData) string {
+func (m Model) renderPositionCard(pos core.PositionData, selected bool) string {
 	var content strings.Builder
 	
 	// Use full instrument ID (e.g., "SOL-USDT-SWAP") instead of just coin name
 	instrumentName := pos.InstrumentID
 	
-	// Card header with prominent instrument name - full trading pair
-	content.WriteString(cardHeaderStyle.Render(fmt.Sprintf("▶ %s ◀", instrumentName)))
+	// Card header with prominent instrument name - full trading pair,
+	// with a marker while the data is left over from before a reconnect
+	header := fmt.Sprintf("▶ %s ◀", instrumentName) + m.pinLabel(instrumentName)
+	if m.awaitingRefresh(pos) {
+		header += " ⟳"
+	}
+	content.WriteString(cardHeaderStyle.Render(header))
 	content.WriteString("\n")
+	content.WriteString(m.renderNote(pos))
 	
-	// Position details
-	content.WriteString(fmt.Sprintf("%s %s\n", 
-		labelStyle.Render("Side:"), 
-		valueStyle.Render(pos.PositionSide)))
-	
-	content.WriteString(fmt.Sprintf("%s %s\n", 
-		labelStyle.Render("Size:"), 
-		valueStyle.Render(fmt.Sprintf("%.4f", pos.Size))))
-	
-	// Format entry price with appropriate precision
-	var entryPriceStr string
-	if pos.AvgPrice < 0.001 {
-		entryPriceStr = fmt.Sprintf("%.6f", pos.AvgPrice)
-	} else if pos.AvgPrice < 0.1 {
-		entryPriceStr = fmt.Sprintf("%.5f", pos.AvgPrice)
-	} else if pos.AvgPrice < 1.0 {
-		entryPriceStr = fmt.Sprintf("%.4f", pos.AvgPrice)
-	} else {
-		entryPriceStr = fmt.Sprintf("%.2f", pos.AvgPrice)
-	}
-	content.WriteString(fmt.Sprintf("%s %s\n", 
-		labelStyle.Render("Entry:"), 
-		valueStyle.Render(entryPriceStr)))
-	
-	// Format current price with appropriate precision
-	var currentPriceStr string
-	if pos.CurrentPrice < 0.001 {
-		currentPriceStr = fmt.Sprintf("%.6f", pos.CurrentPrice)
-	} else if pos.CurrentPrice < 0.1 {
-		currentPriceStr = fmt.Sprintf("%.5f", pos.CurrentPrice)
-	} else if pos.CurrentPrice < 1.0 {
-		currentPriceStr = fmt.Sprintf("%.4f", pos.CurrentPrice)
-	} else {
-		currentPriceStr = fmt.Sprintf("%.2f", pos.CurrentPrice)
-	}
-	content.WriteString(fmt.Sprintf("%s %s\n", 
-		labelStyle.Render("Current:"), 
-		valueStyle.Render(currentPriceStr)))
-	
-	// PnL with color
-	pnlStr := fmt.Sprintf("%.2f", pos.PnL)
-	if pos.PnL > 0 {
-		pnlStr = "+" + pnlStr
-		pnlStr = positiveStyle.Render(pnlStr)
-	} else if pos.PnL < 0 {
-		pnlStr = negativeStyle.Render(pnlStr)
-	} else {
-		pnlStr = neutralStyle.Render(pnlStr)
+	// Position details, in the configured field order
+	content.WriteString(m.renderCardFields(pos))
+	content.WriteString(m.renderLiqWarning(pos))
+	content.WriteString(m.renderPnLTrend(pos))
+	content.WriteString(m.renderPnLSparkline(pos))
+
+	if m.showLatency() {
+		content.WriteString(m.renderLatencyLines(pos))
 	}
-	
-	content.WriteString(fmt.Sprintf("%s %s\n", 
-		labelStyle.Render("PnL:"), pnlStr))
-	
-	// PnL percentage with color
-	pnlPercentStr := fmt.Sprintf("%.2f%%", pos.PnLRatio)
-	if pos.PnLRatio > 0 {
-		pnlPercentStr = "+" + pnlPercentStr
-		pnlPercentStr = positiveStyle.Render(pnlPercentStr)
-	} else if pos.PnLRatio < 0 {
-		pnlPercentStr = negativeStyle.Render(pnlPercentStr)
-	} else {
-		pnlPercentStr = neutralStyle.Render(pnlPercentStr)
+	if m.showFeedCountdown() {
+		content.WriteString(m.renderFeedCountdown(pos.InstrumentID))
 	}
+	content.WriteString(m.renderTickerStats(pos.InstrumentID))
 	
-	content.WriteString(fmt.Sprintf("%s %s\n", 
-		labelStyle.Render("PnL %:"), pnlPercentStr))
-	
-	content.WriteString(fmt.Sprintf("%s %s", 
-		labelStyle.Render("Leverage:"), 
-		valueStyle.Render(fmt.Sprintf("%.0fx", pos.Leverage))))
-	
-	// Render the entire card with border and styling
-	return cardStyle.Render(content.String())
+	// Render the entire card with border and styling, highlighting a fresh
+	// PnL alert over the selection
+	if style, ok := m.alertCardStyle(pos); ok {
+		return m.sizedCard(style).Render(content.String())
+	}
+	if selected {
+		return m.sizedCard(selectedCardStyle).Render(content.String())
+	}
+	if m.awaitingRefresh(pos) {
+		return m.sizedCard(staleCardStyle).Render(content.String())
+	}
+	return m.sizedCard(cardStyle).Render(content.String())
 }
 
 // Init initializes the model
@@ -316,7 +796,14 @@ func (m Model) Init() tea.Cmd {
 		waitForPositionUpdate(m.positionCh),
 		waitForBalanceUpdate(m.balanceCh),
 		waitForError(m.errorCh),
-		tick(),
+		waitForTradeUpdate(m.tradeCh),
+		waitForBookUpdate(m.bookCh),
+		waitForTickerUpdate(m.tickerCh),
+		waitForStatusUpdate(m.statusCh),
+		waitForClosedHistory(m.closedCh),
+		m.nextTick(),
+		m.initialTapeTick(),
+		m.initialMaxLeverage(),
 	)
 }
 
@@ -324,11 +811,32 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	// Track terminal focus for clock and render throttling
+	if m.handleFocusMsg(msg) {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// The note editor takes all keys while open
+		if m.noteEditKey != "" {
+			return m, m.handleNoteKey(msg)
+		}
+
+		// So does the instrument filter prompt
+		if m.filtering {
+			m.handleFilterKey(msg)
+			return m, m.resubscribeBook()
+		}
+
+		// And the alert threshold prompt
+		if m.thresholdEdit {
+			return m, m.handleThresholdKey(msg)
+		}
+
 		// Calculate content lines and max scroll for boundary checking
 		var mainContent string
-		if len(m.positions) == 0 {
+		if len(m.positions) == 0 && len(m.closing) == 0 {
 			// Show different messages based on whether we have received any updates
 			var statusMsg, detailMsg string
 			if m.lastUpdate.IsZero() {
@@ -344,9 +852,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				labelStyle.Render("Time: ") + valueStyle.Render(time.Now().Format("15:04:05")) + "\n\n" +
 				neutralStyle.Render(detailMsg))
 			mainContent = waitingMsg
+		} else if m.detailView {
+			mainContent = m.renderDetailView()
 		} else {
 			mainContent = m.renderPositionCards()
 		}
+		if closed := m.renderRecentlyClosed(); closed != "" && !m.detailView {
+			mainContent += "\n" + closed
+		}
 		
 		lines := strings.Split(mainContent, "\n")
 		availableHeight := m.height - 10 // Reserve space for header, footer, padding, etc.
@@ -360,6 +873,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			maxScroll = 0
 		}
 		
+		// Remember manual navigation so alerts don't yank the selection away
+		switch msg.String() {
+		case "up", "k", "down", "j", "pgup", "pgdown", "home", "end", "left", "h", "right", "l":
+			m.lastManualNav = time.Now()
+		}
+
+		// Timelapse playback keys take precedence while replaying
+		if m.handlePlaybackKey(msg.String()) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
@@ -369,7 +893,123 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Clear existing debug messages when turning debug off
 			if !m.showDebug {
 				m.debugMessages = make([]string, 0)
+				m.debugCursor = 0
+				m.debugExpanded = false
+			}
+		case "[":
+			// Select an older debug line
+			m.moveDebugCursor(1)
+		case "]":
+			// Select a newer debug line
+			m.moveDebugCursor(-1)
+		case "x":
+			// Expand or truncate the selected debug line
+			if m.showDebug {
+				m.debugExpanded = !m.debugExpanded
+			}
+		case "r":
+			// Toggle recent trades pane when the trades feed is enabled
+			if m.tradeCh != nil {
+				m.showTrades = !m.showTrades
+			}
+		case "left", "h":
+			// Select previous card
+			if m.selected > 0 {
+				m.selected--
+				return m, m.resubscribeBook()
+			}
+		case "right", "l":
+			// Select next card
+			if m.selected < len(m.positionGroups())-1 {
+				m.selected++
+				return m, m.resubscribeBook()
+			}
+		case "s":
+			// Cycle the card sort mode
+			m.sortMode = (m.sortMode + 1) % sortMode(len(sortModeNames))
+			m.selected = 0
+			return m, m.resubscribeBook()
+		case "p":
+			// Pause or resume the client's subscriptions
+			return m, m.toggleFeedPause()
+		case "t":
+			// Toggle bid/ask spread and 24h volume on the cards
+			m.toggleTickerStats()
+		case "c":
+			// Toggle the full-screen equity chart
+			m.toggleEquityChart()
+		case "T":
+			// Toggle the ticker tape view
+			m.tapeMode = !m.tapeMode
+			return m, m.startTape()
+		case "tab":
+			// Show the next account
+			if m.nextAccount() {
+				return m, m.resubscribeBook()
+			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			// Show the account with that number
+			if m.switchAccount(int(msg.String()[0] - '1')) {
+				return m, m.resubscribeBook()
 			}
+		case "M":
+			// Show or hide leverage headroom in the detail view
+			return m, m.toggleMaxLeverage()
+		case "e":
+			// Save every position to a CSV in the background
+			return m, m.exportCSV()
+		case "I":
+			// Save the current view as a PNG in the background
+			return m, exportImage(m.render())
+		case "n":
+			// Edit the selected position's note
+			m.startNoteEdit()
+		case "P":
+			// Pin the selected card's instrument to the top, or unpin it
+			m.togglePin()
+		case "S":
+			// Toggle showing only stale instruments
+			m.staleOnly = !m.staleOnly
+			m.selected = 0
+			return m, m.resubscribeBook()
+		case "o":
+			// Collapse small positions into the Others card, or expand them
+			if m.dustNotional > 0 {
+				m.othersExpanded = !m.othersExpanded
+				m.selected = 0
+				return m, m.resubscribeBook()
+			}
+		case "enter":
+			// Expand the Others card into the positions it holds
+			if group, ok := m.selectedGroup(); ok && m.isOthersGroup(group) {
+				m.othersExpanded = true
+				return m, nil
+			}
+			// Toggle detail view for the selected position
+			if _, ok := m.selectedPosition(); ok {
+				m.detailView = !m.detailView
+				return m, m.resubscribeBook()
+			}
+		case "esc":
+			// Leave the equity chart or detail view, or clear the instrument filter
+			if m.showEquityChart {
+				m.toggleEquityChart()
+				return m, nil
+			}
+			if m.detailView {
+				m.detailView = false
+				return m, m.resubscribeBook()
+			}
+			if m.filterText != "" {
+				m.clearFilter()
+				return m, m.resubscribeBook()
+			}
+		case "/":
+			// Filter cards by instrument
+			m.startFilter()
+		case "A":
+			// Set the loss and gain alert thresholds
+			m.startThresholdEdit()
 		case "up", "k":
 			// Scroll up
 			if m.scrollOffset > 0 {
@@ -406,38 +1046,89 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case positionUpdateMsg:
+		if msg.Account != m.account {
+			return m.updateAccount(msg.Account, msg)
+		}
+
 		// Handle position updates - could be full position data or just ticker updates
+		var noteCmd, openCmd tea.Cmd
+		m.logUpdate(func(log *store.UpdateLog) error { return log.LogPosition(core.PositionData(msg)) })
+		m.markSeen(msg.InstrumentID)
+		m.recordPrice(msg.InstrumentID, msg.CurrentPrice)
 		if msg.PositionSide != "" {
 			// Full position data with position side
 			key := fmt.Sprintf("%s-%s", msg.InstrumentID, msg.PositionSide)
 			
-			if msg.Size > 0 {
-				// Position is open - add or update it
+			// Fresh data for this position, it is no longer left over from before a reconnect
+			delete(m.refreshPending, key)
+
+			if msg.Size != 0 {
+				// Position is open, net-mode shorts have a negative size - add or update it
+				prev, existed := m.positions[key]
+				if existed && m.belowMinChange(prev, core.PositionData(msg)) {
+					// Too small a move to redraw for, keep showing the previous update
+					return m, waitForPositionUpdate(m.positionCh)
+				}
 				m.positions[key] = core.PositionData(msg)
+				if msg.LastPrice == 0 && existed {
+					// Keep the streamed last price when the update has none
+					position := m.positions[key]
+					position.LastPrice = prev.LastPrice
+					m.positions[key] = position
+				}
+				// A reopened position replaces its closed card
+				delete(m.closing, key)
+				if !existed {
+					openCmd = m.checkOpenAlert(core.PositionData(msg))
+				}
+				m.recordPnL(key, msg.PnL)
+				m.recordPnLSpark(key, msg.PnL)
 				m.lastUpdate = time.Now()
 
 				// Add debug message for position update
-				m.AddDebugMessage(fmt.Sprintf("Position updated: %s %s %.4f @ %.2f", 
+				m.addInstrumentDebug(msg.InstrumentID, fmt.Sprintf("Position updated: %s %s %.4f @ %.2f", 
 					msg.InstrumentID, msg.PositionSide, msg.Size, msg.CurrentPrice))
 			} else {
 				// Position is closed (size = 0) - remove it from display
-				if _, exists := m.positions[key]; exists {
+				if closed, exists := m.positions[key]; exists {
 					delete(m.positions, key)
+					delete(m.pnlHistories, key)
+					delete(m.pnlSparks, key)
 					m.lastUpdate = time.Now()
 					
 					// Add debug message for position closure
-					m.AddDebugMessage(fmt.Sprintf("Position closed: %s %s", 
+					m.addInstrumentDebug(msg.InstrumentID, fmt.Sprintf("Position closed: %s %s", 
 						msg.InstrumentID, msg.PositionSide))
+					noteCmd = tea.Batch(m.pruneNote(key), m.flashClosed(key, closed))
+
+					// Keep selection within bounds after removal
+					if groups := len(m.positionGroups()); m.selected >= groups && m.selected > 0 {
+						m.selected = groups - 1
+					}
+					if len(m.positions) == 0 && m.detailView {
+						m.detailView = false
+						m.ClearError()
+						return m, tea.Batch(waitForPositionUpdate(m.positionCh), m.resubscribeBook(), noteCmd)
+					}
 				}
 			}
 		} else {
 			// Ticker update - find existing positions for this instrument and update price only
+			m.recordFXPrice(msg.InstrumentID, msg.CurrentPrice)
 			updated := false
 			for key, position := range m.positions {
 				if position.InstrumentID == msg.InstrumentID {
-					// Update current price only - preserve PnL from OKX API
-					position.CurrentPrice = msg.CurrentPrice
+					// Update current price only - preserve PnL from OKX API.
+					// Last-price-only updates leave the mark price alone.
+					if msg.CurrentPrice > 0 {
+						position.CurrentPrice = msg.CurrentPrice
+						position.PriceIsLast = msg.PriceIsLast
+					}
+					if msg.LastPrice > 0 {
+						position.LastPrice = msg.LastPrice
+					}
 					position.Timestamp = msg.Timestamp
+					position.ReceivedAt = msg.ReceivedAt
 					
 					m.positions[key] = position
 					updated = true
@@ -447,7 +1138,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if updated {
 				m.lastUpdate = time.Now()
 				// Add debug message for ticker update
-				m.AddDebugMessage(fmt.Sprintf("Ticker updated: %s @ %.6f", 
+				m.addInstrumentDebug(msg.InstrumentID, fmt.Sprintf("Ticker updated: %s @ %.6f", 
 					msg.InstrumentID, msg.CurrentPrice))
 			}
 		}
@@ -455,23 +1146,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Clear any previous errors when we get successful updates
 		m.ClearError()
 
+		// Check alerts against the updated positions
+		if alertCmd := m.checkAlerts(); alertCmd != nil {
+			return m, tea.Batch(waitForPositionUpdate(m.positionCh), alertCmd, noteCmd, openCmd)
+		}
+		if noteCmd != nil || openCmd != nil {
+			return m, tea.Batch(waitForPositionUpdate(m.positionCh), noteCmd, openCmd)
+		}
+
 		return m, waitForPositionUpdate(m.positionCh)
 
 	case balanceUpdateMsg:
-		// Calculate current total balance before updating
-		var currentTotalBalance float64
-		for _, balance := range m.balances {
-			currentTotalBalance += balance.TotalEquity
+		if msg.Account != m.account {
+			return m.updateAccount(msg.Account, msg)
 		}
-		
-		// Store the current total as previous balance
-		if currentTotalBalance > 0 {
-			m.previousBalance = currentTotalBalance
-		}
-		
+
 		// Update balance data
+		m.logUpdate(func(log *store.UpdateLog) error { return log.LogBalance(core.BalanceData(msg)) })
 		m.balances[msg.Currency] = core.BalanceData(msg)
 		m.lastUpdate = time.Now()
+		m.recordSessionStart()
+
+		// Record total equity for the history graph
+		m.equity.Add(m.lastUpdate, m.totalEquity())
 
 		// Add debug message for balance update
 		m.AddDebugMessage(fmt.Sprintf("Balance updated: %s Total: %.4f Available: %.4f", 
@@ -486,29 +1183,123 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Remove "DEBUG:" prefix and add to debug messages
 			debugMsg := strings.TrimPrefix(msgStr, "DEBUG:")
 			m.AddDebugMessage(strings.TrimSpace(debugMsg))
+		} else if strings.HasPrefix(msgStr, warningPrefix) {
+			// Warnings stay visible until the end of the session
+			m.addWarning(strings.TrimSpace(strings.TrimPrefix(msgStr, warningPrefix)))
 		} else {
 			// Set as error message
 			m.SetError(msgStr)
 		}
 		return m, waitForError(m.errorCh)
 
+	case maxLeverageMsg:
+		return m, m.handleMaxLeverage(msg)
+
+	case metadataRefreshMsg:
+		return m, m.refreshMetadata()
+
+	case closedExpiredMsg:
+		m.expireClosed(msg)
+		return m, nil
+
+	case closedHistoryMsg:
+		if msg.Account != m.account {
+			return m.updateAccount(msg.Account, msg)
+		}
+		m.recentlyClosed = msg.Positions
+		m.closedFetched = true
+		return m, waitForClosedHistory(m.closedCh)
+
+	case noteSavedMsg:
+		if msg.err != nil {
+			m.SetError(fmt.Sprintf("Failed to save notes: %v", msg.err))
+		}
+		return m, nil
+
+	case tradeUpdateMsg:
+		// Append the print to the bounded per-instrument buffer
+		buf := append(m.trades[msg.InstrumentID], core.TradeData(msg))
+		if len(buf) > m.bufLimits.trades {
+			buf = buf[len(buf)-m.bufLimits.trades:]
+		}
+		m.trades[msg.InstrumentID] = buf
+
+		return m, waitForTradeUpdate(m.tradeCh)
+
+	case bookUpdateMsg:
+		m.books[msg.InstrumentID] = core.BookData(msg)
+		return m, waitForBookUpdate(m.bookCh)
+
+	case tickerUpdateMsg:
+		m.tickers[msg.InstrumentID] = core.TickerData(msg)
+		return m, waitForTickerUpdate(m.tickerCh)
+
+	case imageExportMsg:
+		m.handleImageExport(msg)
+		return m, nil
+
+	case csvExportMsg:
+		m.handleCSVExport(msg)
+		return m, nil
+
+	case statusUpdateMsg:
+		m.handleConnState(core.ConnState(msg))
+		return m, waitForStatusUpdate(m.statusCh)
+
+	case tapeTickMsg:
+		// Advance the tape while it is shown, letting stale tick chains lapse
+		if !m.tapeMode || msg.gen != m.tapeGen {
+			return m, nil
+		}
+		m.tapeOffset++
+		return m, tapeTick(m.tapeGen)
+
 	case tickMsg:
-		return m, tick()
+		// Keep history buffers within their global cap
+		m.enforceBufferCap()
+		m.sampleHeap()
+
+		// Queue a snapshot for persistence when one is due
+		if m.recorder != nil && time.Since(m.lastSnapshot) >= snapshotInterval {
+			m.lastSnapshot = time.Now()
+			return m, tea.Batch(m.nextTick(), m.recordSnapshot())
+		}
+		return m, m.nextTick()
 	}
 
 	return m, cmd
 }
 
-// View renders the UI
-func (m Model) View() string {
+// render renders the UI
+func (m Model) render() string {
+	if !m.panicAt.IsZero() && time.Now().After(m.panicAt) {
+		panic("injected render panic (-debug-panic)")
+	}
+
+	// Ticker tape mode replaces the whole UI with one line
+	if m.tapeMode {
+		return m.renderTape()
+	}
+
+	// The equity chart takes over the screen below the tape
+	if m.showEquityChart {
+		return m.renderEquityChart()
+	}
+
 	// Build the UI components
 	var content strings.Builder
 	
 	// Create header with title on left and time info on right
-	title := titleStyle.Render("OKX Position Monitor")
+	title := titleStyle.Render("OKX Position Monitor") + m.renderAccountName() + m.renderConnIndicator()
 	
-	// Get balance display
+	// Get balance display, followed by the total unrealized PnL
 	balance := m.renderBalance()
+	if pnl := m.renderTotalPnL(); pnl != "" {
+		if balance != "" {
+			balance += "  "
+		}
+		balance += pnl
+	}
 	
 	// Current time and last update time
 	currentTime := time.Now()
@@ -571,11 +1362,42 @@ func (m Model) View() string {
 	}
 	
 	content.WriteString(header)
-	content.WriteString("\n\n")
+	content.WriteString("\n")
+
+	// Add the account KPI chips below the header
+	if kpiRow := m.renderKPIRow(headerWidth); kpiRow != "" {
+		content.WriteString(kpiRow)
+		content.WriteString("\n")
+	}
+	
+	// Add the totals across accounts
+	if totals := m.renderAccountTotals(); totals != "" {
+		content.WriteString(totals)
+		content.WriteString("\n")
+	}
+	
+	// Add persistent alert banner while positions are at risk
+	if banner := m.renderAlertBanner(); banner != "" {
+		content.WriteString(banner)
+		content.WriteString("\n")
+	}
+	
+	// Add risk summary below the header when positions are open
+	if riskSummary := m.renderRiskSummary(); riskSummary != "" {
+		content.WriteString(riskSummary)
+		content.WriteString("\n")
+	}
+	
+	// Add equity history sparkline once there are enough samples
+	if equityLine := m.renderEquityLine(); equityLine != "" {
+		content.WriteString(equityLine)
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
 	
 	// Add position cards or waiting message
 	var mainContent string
-	if len(m.positions) == 0 {
+	if len(m.positions) == 0 && len(m.closing) == 0 {
 		// Show different messages based on whether we have received any updates
 		var statusMsg, detailMsg string
 		if m.lastUpdate.IsZero() {
@@ -591,9 +1413,14 @@ func (m Model) View() string {
 			labelStyle.Render("Time: ") + valueStyle.Render(currentTime.Format("15:04:05")) + "\n\n" +
 			neutralStyle.Render(detailMsg))
 		mainContent = waitingMsg
+	} else if m.detailView {
+		mainContent = m.renderDetailView()
 	} else {
 		mainContent = m.renderPositionCards()
 	}
+	if closed := m.renderRecentlyClosed(); closed != "" && !m.detailView {
+		mainContent += "\n" + closed
+	}
 	
 	// Split main content into lines for scrolling
 	lines := strings.Split(mainContent, "\n")
@@ -623,6 +1450,16 @@ func (m Model) View() string {
 	content.WriteString(strings.Join(visibleLines, "\n"))
 	content.WriteString("\n")
 	
+	// Add recent trades pane if enabled
+	if m.showTrades {
+		tradesSection := m.renderTradesSection()
+		if tradesSection != "" {
+			content.WriteString("\n")
+			content.WriteString(tradesSection)
+			content.WriteString("\n")
+		}
+	}
+	
 	// Add debug section if enabled and there are debug messages
 	if m.showDebug {
 		debugSection := m.renderDebugSection()
@@ -653,12 +1490,51 @@ func (m Model) View() string {
 	// Show debug status
 	debugStatus := ""
 	if m.showDebug {
-		debugStatus = " | Debug: ON"
+		debugStatus = " | Debug: ON ([/] select, x expand)"
 	} else {
 		debugStatus = " | Debug: OFF"
 	}
 	
-	footerText := "Press q or Ctrl+C to quit | d to toggle debug | ↑↓ or j/k to scroll | PgUp/PgDn | Home/End" + scrollInfo + debugStatus
+	// Show trades toggle only when the trades feed is enabled
+	tradesHelp := ""
+	if m.tradeCh != nil {
+		tradesHelp = " | r to toggle trades"
+	}
+	if m.pauseReqCh != nil {
+		tradesHelp += " | p pause feed"
+	}
+	
+	// Show persistent warnings above the footer
+	if len(m.warnings) > 0 {
+		content.WriteString(m.renderWarnings())
+		content.WriteString("\n")
+	}
+
+	// Show the note being edited above the footer
+	if m.noteEditKey != "" {
+		content.WriteString(m.renderNotePrompt())
+		content.WriteString("\n")
+	}
+
+	// Show the instrument filter being typed above the footer
+	if m.filtering {
+		content.WriteString(m.renderFilterPrompt())
+		content.WriteString("\n")
+	}
+
+	// Show the alert thresholds being typed above the footer
+	if m.thresholdEdit {
+		content.WriteString(m.renderThresholdPrompt())
+		content.WriteString("\n")
+	}
+
+	// Show transient toast above the footer
+	if m.toastMsg != "" && time.Now().Before(m.toastUntil) {
+		content.WriteString(toastStyle.Render(m.toastMsg))
+		content.WriteString("\n")
+	}
+	
+	footerText := "Press q or Ctrl+C to quit | d to toggle debug" + tradesHelp + " | ←→ or h/l to select | Enter for detail | t bid/ask | c equity | T tape | s sort | S stale | / filter | A alerts | P pin | ↑↓ or j/k to scroll | PgUp/PgDn | Home/End" + m.accountHelp() + scrollInfo + debugStatus + m.connectionStatus() + m.latencyStatus() + m.filterStatus() + m.playbackStatus()
 	content.WriteString(footerText)
 
 	return baseStyle.Render(content.String())
@@ -669,6 +1545,8 @@ type positionUpdateMsg core.PositionData
 type balanceUpdateMsg core.BalanceData
 type tickMsg time.Time
 type errorMsg string
+type tradeUpdateMsg core.TradeData
+type bookUpdateMsg core.BookData
 
 // SetError sets an error message
 func (m *Model) SetError(msg string) {
@@ -684,6 +1562,11 @@ func (m *Model) ClearError() {
 
 // AddDebugMessage adds a debug message to the debug output
 func (m *Model) AddDebugMessage(msg string) {
+	// Stream to the debug writer regardless of the pane's visibility
+	if m.debugOut != nil {
+		fmt.Fprintf(m.debugOut, "%s DEBUG %s\n", time.Now().Format("2006-01-02 15:04:05.000"), msg)
+	}
+
 	// Only add debug messages when debug mode is enabled
 	if !m.showDebug {
 		return
@@ -709,9 +1592,14 @@ func (m Model) renderDebugSection() string {
 	var content strings.Builder
 	content.WriteString(debugHeaderStyle.Render("Debug Output"))
 	content.WriteString("\n")
+	content.WriteString("  " + truncateLine(m.renderBufferStats(), m.debugLineWidth()))
+	content.WriteString("\n")
+	content.WriteString("  " + truncateLine(m.renderChannelStats(), m.debugLineWidth()))
+	content.WriteString("\n")
 	
-	for _, msg := range m.debugMessages {
-		content.WriteString(msg)
+	// Full messages are kept; lines are truncated only when rendered
+	for i, msg := range m.debugMessages {
+		content.WriteString(m.renderDebugLine(i, msg))
 		content.WriteString("\n")
 	}
 	
@@ -751,9 +1639,9 @@ func waitForError(ch <-chan string) tea.Cmd {
 	}
 }
 
-// tick sends a tick message every second for UI updates
-func tick() tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+// tickEvery sends a tick message after the given interval
+func tickEvery(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
\ No newline at end of file
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testKeyTypes are the key names Update matches that aren't typed runes
+var testKeyTypes = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"backspace": tea.KeyBackspace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"ctrl+c":    tea.KeyCtrlC,
+}
+
+// testKey returns the key message for a key name as Update sees it, e.g. "j",
+// "tab" or "esc"
+func testKey(name string) tea.KeyMsg {
+	if keyType, ok := testKeyTypes[name]; ok {
+		return tea.KeyMsg{Type: keyType}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// testPosition returns a full position update
+func testPosition(instId, side string, size, avgPrice, price, pnl float64) positionUpdateMsg {
+	return positionUpdateMsg{
+		InstrumentID: instId,
+		PositionSide: side,
+		Size:         size,
+		AvgPrice:     avgPrice,
+		CurrentPrice: price,
+		PnL:          pnl,
+		Leverage:     10,
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// updateModel runs msgs through Update in order and returns the final model
+func updateModel(m Model, msgs ...tea.Msg) Model {
+	for _, msg := range msgs {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+// updateStep is a message sent to Update and what the model must look like after it
+type updateStep struct {
+	msg   tea.Msg
+	check func(t *testing.T, m Model)
+}
+
+func wantPositions(n int) func(*testing.T, Model) {
+	return func(t *testing.T, m Model) {
+		t.Helper()
+		if len(m.positions) != n {
+			t.Errorf("positions = %d, want %d", len(m.positions), n)
+		}
+	}
+}
+
+func wantSelected(i int) func(*testing.T, Model) {
+	return func(t *testing.T, m Model) {
+		t.Helper()
+		if m.selected != i {
+			t.Errorf("selected = %d, want %d", m.selected, i)
+		}
+	}
+}
+
+func wantScroll(offset int) func(*testing.T, Model) {
+	return func(t *testing.T, m Model) {
+		t.Helper()
+		if m.scrollOffset != offset {
+			t.Errorf("scrollOffset = %d, want %d", m.scrollOffset, offset)
+		}
+	}
+}
+
+func wantError(msg string) func(*testing.T, Model) {
+	return func(t *testing.T, m Model) {
+		t.Helper()
+		if m.hasError != (msg != "") || m.errorMsg != msg {
+			t.Errorf("error = %q (set %v), want %q", m.errorMsg, m.hasError, msg)
+		}
+	}
+}
+
+func wantDetail(on bool) func(*testing.T, Model) {
+	return func(t *testing.T, m Model) {
+		t.Helper()
+		if m.detailView != on {
+			t.Errorf("detailView = %v, want %v", m.detailView, on)
+		}
+	}
+}
+
+func TestUpdateSequences(t *testing.T) {
+	btc := testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500)
+	eth := testPosition("ETH-USDT-SWAP", "short", 2, 3000, 2950, 100)
+	sol := testPosition("SOL-USDT-SWAP", "long", 10, 100, 99, -10)
+
+	tests := []struct {
+		name  string
+		steps []updateStep
+	}{
+		{
+			name: "position lifecycle",
+			steps: []updateStep{
+				{btc, wantPositions(1)},
+				{eth, wantPositions(2)},
+				{positionUpdateMsg{InstrumentID: "BTC-USDT-SWAP", CurrentPrice: 51000}, func(t *testing.T, m Model) {
+					pos := m.positions["BTC-USDT-SWAP-long"]
+					if pos.CurrentPrice != 51000 || pos.PnL != 500 {
+						t.Errorf("after ticker price %v PnL %v, want price 51000 with PnL kept at 500", pos.CurrentPrice, pos.PnL)
+					}
+				}},
+				{testPosition("BTC-USDT-SWAP", "long", 0, 50000, 51000, 0), func(t *testing.T, m Model) {
+					wantPositions(1)(t, m)
+					if _, ok := m.closing["BTC-USDT-SWAP-long"]; !ok {
+						t.Error("closed position not flashed")
+					}
+				}},
+			},
+		},
+		{
+			name: "balances",
+			steps: []updateStep{
+				{balanceUpdateMsg{Currency: "USDT", TotalEquity: 1000, AvailBalance: 800}, func(t *testing.T, m Model) {
+					if got := m.balances["USDT"].TotalEquity; got != 1000 {
+						t.Errorf("USDT equity = %v, want 1000", got)
+					}
+					if m.sessionStartEquity != 1000 {
+						t.Errorf("session start = %v, want 1000", m.sessionStartEquity)
+					}
+				}},
+				{balanceUpdateMsg{Currency: "USDT", TotalEquity: 1100, AvailBalance: 900}, func(t *testing.T, m Model) {
+					if got := m.balances["USDT"].TotalEquity; got != 1100 {
+						t.Errorf("USDT equity = %v, want 1100", got)
+					}
+					if m.sessionStartEquity != 1000 {
+						t.Errorf("session start moved to %v, want 1000", m.sessionStartEquity)
+					}
+				}},
+			},
+		},
+		{
+			name: "errors, warnings and debug messages",
+			steps: []updateStep{
+				{errorMsg("connection refused"), wantError("connection refused")},
+				{errorMsg("DEBUG: dropped while hidden"), func(t *testing.T, m Model) {
+					if len(m.debugMessages) != 0 {
+						t.Errorf("debug messages kept while hidden: %v", m.debugMessages)
+					}
+				}},
+				{testKey("d"), nil},
+				{errorMsg("DEBUG: subscribed"), func(t *testing.T, m Model) {
+					if len(m.debugMessages) != 1 || !strings.HasSuffix(m.debugMessages[0], "subscribed") {
+						t.Errorf("debug messages = %v, want the subscribed line", m.debugMessages)
+					}
+				}},
+				{errorMsg("WARN: unknown instrument"), func(t *testing.T, m Model) {
+					if len(m.warnings) != 1 || m.warnings[0] != "unknown instrument" {
+						t.Errorf("warnings = %v", m.warnings)
+					}
+					wantError("connection refused")(t, m)
+				}},
+				{btc, wantError("")},
+				{testKey("d"), func(t *testing.T, m Model) {
+					if m.showDebug || len(m.debugMessages) != 0 {
+						t.Errorf("debug off kept %d messages", len(m.debugMessages))
+					}
+				}},
+			},
+		},
+		{
+			name: "selection and detail view",
+			steps: []updateStep{
+				{btc, nil},
+				{eth, nil},
+				{sol, wantSelected(0)},
+				{testKey("l"), wantSelected(1)},
+				{testKey("l"), wantSelected(2)},
+				{testKey("l"), wantSelected(2)},
+				{testKey("h"), wantSelected(1)},
+				{testKey("enter"), wantDetail(true)},
+				{testKey("esc"), wantDetail(false)},
+				{testKey("s"), wantSelected(0)},
+			},
+		},
+		{
+			name: "resize and scrolling",
+			steps: []updateStep{
+				{tea.WindowSizeMsg{Width: 60, Height: 12}, func(t *testing.T, m Model) {
+					if m.width != 60 || m.height != 12 {
+						t.Errorf("size = %dx%d, want 60x12", m.width, m.height)
+					}
+				}},
+				{btc, nil},
+				{eth, nil},
+				{sol, nil},
+				{testKey("k"), wantScroll(0)},
+				{testKey("j"), wantScroll(1)},
+				{testKey("pgdown"), wantScroll(6)},
+				{testKey("pgup"), wantScroll(1)},
+				{testKey("pgup"), wantScroll(0)},
+				{testKey("end"), func(t *testing.T, m Model) {
+					if m.scrollOffset < 6 {
+						t.Errorf("end scrolled to %d, want past the three cards", m.scrollOffset)
+					}
+				}},
+				{testKey("home"), wantScroll(0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(nil, nil, nil)
+			for i, step := range tt.steps {
+				m = updateModel(m, step.msg)
+				if step.check != nil {
+					t.Run(fmt.Sprintf("step %d", i+1), func(t *testing.T) { step.check(t, m) })
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateQuitKeys(t *testing.T) {
+	for _, key := range []string{"q", "ctrl+c"} {
+		_, cmd := NewModel(nil, nil, nil).Update(testKey(key))
+		if cmd == nil {
+			t.Fatalf("%s returned no command", key)
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("%s did not quit", key)
+		}
+	}
+}
+
+func TestUpdateClosingLastPositionLeavesDetailView(t *testing.T) {
+	m := updateModel(NewModel(nil, nil, nil),
+		testPosition("BTC-USDT-SWAP", "long", 1, 50000, 50500, 500),
+		testKey("enter"),
+	)
+	if !m.detailView {
+		t.Fatal("enter did not open the detail view")
+	}
+	m = updateModel(m, testPosition("BTC-USDT-SWAP", "long", 0, 50000, 50500, 0))
+	if m.detailView {
+		t.Error("detail view still open without positions")
+	}
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+)
+
+// Validate checks the options given as text, such as the sort mode, card
+// fields and KPIs, so a mistake can stop the program before the UI starts
+// rather than show as an error inside it. The error names the flag or setting
+// at fault, e.g. "-kpis: unknown KPI ...".
+func (opts Options) Validate() error {
+	// check drops the parsed value, keeping the error
+	check := func(_ interface{}, err error) error { return err }
+
+	checks := []struct {
+		name string
+		err  error
+	}{
+		{"-buffer-limits", check(parseBufferLimits(opts.BufferLimits))},
+		{"theme", validateTheme(opts.Theme)},
+		{"-open-alert-long", check(parseOpenAlert(opts.OpenAlertLong))},
+		{"-open-alert-short", check(parseOpenAlert(opts.OpenAlertShort))},
+		{"-equity-window", check(parseEquityWindow(opts.EquityWindow))},
+		{"-fx", check(parseFXRates(opts.FXRates))},
+		{"-negative-avail", check(parseNegativeAvailMode(opts.NegativeAvail))},
+		{"-rounding", check(parseRoundingMode(opts.Rounding))},
+		{"-sort", check(parseSortMode(opts.SortMode))},
+		{"-card-fields", check(parseCardFields(opts.CardFields))},
+		{"-min-change", check(parseMinChange(opts.MinChange))},
+		{"-pnl-trend", check(parsePnLTrendIntervals(opts.PnLTrend))},
+		{"-kpis", check(parseKPIs(opts.KPIs))},
+		{"-balance-baseline", check(parseBalanceBaselineMode(opts.BalanceBaseline))},
+	}
+	for _, c := range checks {
+		if c.err != nil {
+			return fmt.Errorf("%s: %v", c.name, c.err)
+		}
+	}
+	return nil
+}
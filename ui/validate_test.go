@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	if err := (Options{}).Validate(); err != nil {
+		t.Fatalf("zero options: %v", err)
+	}
+	valid := Options{
+		BufferLimits:    "price=240",
+		Theme:           map[string]string{"positive": "46"},
+		OpenAlertLong:   "bell",
+		EquityWindow:    "1h",
+		FXRates:         "USDC=live",
+		NegativeAvail:   "clamp",
+		Rounding:        "half-up",
+		SortMode:        "pnl",
+		CardFields:      "side,pnl",
+		MinChange:       "0.1%",
+		PnLTrend:        "1m,5m",
+		KPIs:            "equity,upnl",
+		BalanceBaseline: "session",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid options: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		set      func(*Options)
+		wantFlag string
+	}{
+		{"buffer limits", func(o *Options) { o.BufferLimits = "prices=1" }, "-buffer-limits: "},
+		{"theme", func(o *Options) { o.Theme = map[string]string{"bogus": "1"} }, "theme: "},
+		{"open alert", func(o *Options) { o.OpenAlertShort = "siren" }, "-open-alert-short: "},
+		{"equity window", func(o *Options) { o.EquityWindow = "forever" }, "-equity-window: "},
+		{"fx", func(o *Options) { o.FXRates = "USDT=live" }, "-fx: "},
+		{"negative avail", func(o *Options) { o.NegativeAvail = "hide" }, "-negative-avail: "},
+		{"rounding", func(o *Options) { o.Rounding = "up" }, "-rounding: "},
+		{"sort", func(o *Options) { o.SortMode = "random" }, "-sort: "},
+		{"card fields", func(o *Options) { o.CardFields = "side,levrage" }, "-card-fields: "},
+		{"min change", func(o *Options) { o.MinChange = "lots" }, "-min-change: "},
+		{"pnl trend", func(o *Options) { o.PnLTrend = "1m,soon" }, "-pnl-trend: "},
+		{"kpis", func(o *Options) { o.KPIs = "equity,vibes" }, "-kpis: "},
+		{"balance baseline", func(o *Options) { o.BalanceBaseline = "daily" }, "-balance-baseline: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.set(&opts)
+			err := opts.Validate()
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantFlag) {
+				t.Errorf("Validate() = %v, want an error starting %q", err, tt.wantFlag)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// warningPrefix marks error channel messages that stay on screen as warnings
+// rather than being replaced by the next update
+const warningPrefix = "WARN:"
+
+// maxWarnings is how many distinct warnings are kept on screen
+const maxWarnings = 3
+
+// warningStyle renders persistent configuration warnings
+var warningStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("214"))
+
+// addWarning keeps a warning on screen, ignoring repeats and dropping the
+// oldest beyond maxWarnings
+func (m *Model) addWarning(msg string) {
+	for _, existing := range m.warnings {
+		if existing == msg {
+			return
+		}
+	}
+	m.warnings = append(m.warnings, msg)
+	if len(m.warnings) > maxWarnings {
+		m.warnings = m.warnings[len(m.warnings)-maxWarnings:]
+	}
+	m.AddDebugMessage("Warning: " + msg)
+}
+
+// renderWarnings renders the persistent warnings, one per line
+func (m Model) renderWarnings() string {
+	lines := make([]string, 0, len(m.warnings))
+	for _, warning := range m.warnings {
+		lines = append(lines, warningStyle.Render("⚠ "+warning))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,27 @@
+package ui
+
+import "testing"
+
+func TestHeldFXInstrumentRendersAsPosition(t *testing.T) {
+	m := newModelWithOptions(nil, nil, nil, Options{FXRates: "USDC=live"})
+	m = updateModel(m, testPosition("USDC-USDT", "long", 100, 1, 1, 0))
+
+	// One price serves both the held position and the live rate
+	m = updateModel(m, positionUpdateMsg{InstrumentID: "USDC-USDT", CurrentPrice: 1.002})
+	if len(m.positions) != 1 || len(m.positionGroups()) != 1 {
+		t.Fatalf("%d positions in %d cards, want the held instrument once", len(m.positions), len(m.positionGroups()))
+	}
+	if pos := m.positions["USDC-USDT-long"]; pos.CurrentPrice != 1.002 || pos.PnL != 0 {
+		t.Errorf("position = %+v, want the price merged and OKX's PnL kept", pos)
+	}
+	if rate, ok := m.dollarRate("USDC"); !ok || rate != 1.002 {
+		t.Errorf("USDC rate = %v, %v; want the streamed 1.002", rate, ok)
+	}
+
+	// A rate instrument that isn't held gets no card of its own
+	m = newModelWithOptions(nil, nil, nil, Options{FXRates: "USDC=live"})
+	m = updateModel(m, positionUpdateMsg{InstrumentID: "USDC-USDT", CurrentPrice: 1.002})
+	if len(m.positions) != 0 {
+		t.Errorf("%d positions from a rate ticker alone, want none", len(m.positions))
+	}
+}